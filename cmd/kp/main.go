@@ -12,15 +12,20 @@ import (
 	"github.com/pivotal/kpack/pkg/logs"
 	"github.com/spf13/cobra"
 
+	"github.com/pivotal/build-service-cli/pkg/build"
 	"github.com/pivotal/build-service-cli/pkg/commands"
 	buildcmds "github.com/pivotal/build-service-cli/pkg/commands/build"
 	buildercmds "github.com/pivotal/build-service-cli/pkg/commands/builder"
 	clusterbuildercmds "github.com/pivotal/build-service-cli/pkg/commands/clusterbuilder"
 	clusterstackcmds "github.com/pivotal/build-service-cli/pkg/commands/clusterstack"
 	clusterstorecmds "github.com/pivotal/build-service-cli/pkg/commands/clusterstore"
+	graphcmds "github.com/pivotal/build-service-cli/pkg/commands/graph"
 	imgcmds "github.com/pivotal/build-service-cli/pkg/commands/image"
 	importcmds "github.com/pivotal/build-service-cli/pkg/commands/import"
 	secretcmds "github.com/pivotal/build-service-cli/pkg/commands/secret"
+	"github.com/pivotal/build-service-cli/pkg/detect"
+	"github.com/pivotal/build-service-cli/pkg/git"
+	"github.com/pivotal/build-service-cli/pkg/image"
 	importpkg "github.com/pivotal/build-service-cli/pkg/import"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 	"github.com/pivotal/build-service-cli/pkg/registry"
@@ -55,6 +60,7 @@ Learn more about kpack @ https://github.com/pivotal/kpack`,
 		getStackCommand(clientSetProvider),
 		getStoreCommand(clientSetProvider),
 		getImportCommand(clientSetProvider),
+		graphcmds.NewGraphCommand(clientSetProvider),
 		getCompletionCommand(),
 	)
 
@@ -82,29 +88,53 @@ func getVersionCommand() *cobra.Command {
 	return versionCmd
 }
 
-func getImageCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+func getImageCommand(clientSetProvider k8s.DefaultClientSetProvider) *cobra.Command {
+	newContextClientSetProvider := func(kubeContext string) k8s.ClientSetProvider {
+		return k8s.DefaultClientSetProvider{KubeContext: kubeContext}
+	}
+
 	newImageWaiter := func(clientSet k8s.ClientSet) imgcmds.ImageWaiter {
 		return logs.NewImageWaiter(clientSet.KpackClient, logs.NewBuildLogsClient(clientSet.K8sClient))
 	}
 
+	newGitValidator := func(clientSet k8s.ClientSet) image.GitValidator {
+		return image.GitSourceValidator{
+			Resolver:  git.RemoteResolver{},
+			K8sClient: clientSet.K8sClient,
+		}
+	}
+
+	newBuilderCache := func() (detect.BuilderCache, error) {
+		return detect.NewDefaultFileCache()
+	}
+
 	imageRootCmd := &cobra.Command{
 		Use:     "image",
 		Short:   "Image commands",
 		Aliases: []string{"images", "imgs", "img"},
 	}
 	imageRootCmd.AddCommand(
-		imgcmds.NewCreateCommand(clientSetProvider, registry.DefaultUtilProvider{}, newImageWaiter),
-		imgcmds.NewPatchCommand(clientSetProvider, registry.DefaultUtilProvider{}, newImageWaiter),
-		imgcmds.NewSaveCommand(clientSetProvider, registry.DefaultUtilProvider{}, newImageWaiter),
+		imgcmds.NewCreateCommand(clientSetProvider, registry.DefaultUtilProvider{}, newImageWaiter, newGitValidator),
+		imgcmds.NewPatchCommand(clientSetProvider, registry.DefaultUtilProvider{}, newImageWaiter, newGitValidator, commands.NewConfirmationProvider()),
+		imgcmds.NewSaveCommand(clientSetProvider, registry.DefaultUtilProvider{}, newImageWaiter, newGitValidator),
 		imgcmds.NewListCommand(clientSetProvider),
-		imgcmds.NewDeleteCommand(clientSetProvider),
-		imgcmds.NewTriggerCommand(clientSetProvider),
+		imgcmds.NewDeleteCommand(clientSetProvider, registry.DefaultUtilProvider{}, commands.NewConfirmationProvider()),
+		imgcmds.NewTriggerCommand(clientSetProvider, newImageWaiter, commands.NewConfirmationProvider()),
 		imgcmds.NewStatusCommand(clientSetProvider),
+		imgcmds.NewHistoryCommand(clientSetProvider),
+		imgcmds.NewRollbackCommand(clientSetProvider, registry.DefaultUtilProvider{}, commands.Differ{}),
+		imgcmds.NewCopyCommand(clientSetProvider, newContextClientSetProvider),
+		imgcmds.NewBumpCommand(clientSetProvider, newImageWaiter, newGitValidator),
+		imgcmds.NewDetectCommand(clientSetProvider, clientSetProvider, newBuilderCache),
 	)
 	return imageRootCmd
 }
 
 func getBuildCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	newLogsClient := func(clientSet k8s.ClientSet) buildcmds.LogsClient {
+		return build.NewLogsClient(clientSet.K8sClient)
+	}
+
 	buildRootCmd := &cobra.Command{
 		Use:     "build",
 		Short:   "Build Commands",
@@ -113,7 +143,14 @@ func getBuildCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 	buildRootCmd.AddCommand(
 		buildcmds.NewListCommand(clientSetProvider),
 		buildcmds.NewStatusCommand(clientSetProvider),
-		buildcmds.NewLogsCommand(clientSetProvider),
+		buildcmds.NewLogsCommand(clientSetProvider, registry.DefaultUtilProvider{}, newLogsClient),
+		buildcmds.NewArchiveLogsCommand(clientSetProvider, registry.DefaultUtilProvider{}, newLogsClient),
+		buildcmds.NewDiagnoseCommand(clientSetProvider, registry.DefaultUtilProvider{}, newLogsClient),
+		buildcmds.NewDiffCommand(clientSetProvider, commands.Differ{}),
+		buildcmds.NewCancelCommand(clientSetProvider, commands.NewConfirmationProvider()),
+		buildcmds.NewStatsCommand(clientSetProvider),
+		buildcmds.NewBOMCommand(clientSetProvider, registry.DefaultUtilProvider{}),
+		buildcmds.NewWhyCommand(clientSetProvider, commands.Differ{}),
 	)
 	return buildRootCmd
 }
@@ -0,0 +1,127 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"encoding/json"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+const (
+	BuildMetadataLabel = "io.buildpacks.build.metadata"
+	StackIdLabel       = "io.buildpacks.stack.id"
+)
+
+// BOM is the bill of materials of an image built by a build. The entries are
+// contributed by buildpacks, the buildpacks and stack come from the build.
+type BOM struct {
+	Image      string         `json:"image"`
+	Build      string         `json:"build"`
+	Created    time.Time      `json:"created"`
+	StackId    string         `json:"stackId,omitempty"`
+	RunImage   string         `json:"runImage,omitempty"`
+	Buildpacks []BOMBuildpack `json:"buildpacks"`
+	Entries    []BOMEntry     `json:"bom"`
+}
+
+type BOMBuildpack struct {
+	Id      string `json:"id"`
+	Version string `json:"version"`
+}
+
+type BOMEntry struct {
+	Name      string                 `json:"name"`
+	Version   string                 `json:"version,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Buildpack BOMBuildpack           `json:"buildpack"`
+}
+
+type buildMetadata struct {
+	BOM []BOMEntry `json:"bom"`
+}
+
+// ReadBOM reads the bill of materials of the image built by a build from the
+// CNB build metadata label of the image.
+func ReadBOM(img v1.Image, bld *v1alpha1.Build) (BOM, error) {
+	config, err := img.ConfigFile()
+	if err != nil {
+		return BOM{}, err
+	}
+
+	bom := BOM{
+		Image:      bld.Status.LatestImage,
+		Build:      bld.Labels[v1alpha1.BuildNumberLabel],
+		Created:    bld.CreationTimestamp.Time,
+		StackId:    bld.Status.Stack.ID,
+		RunImage:   bld.Status.Stack.RunImage,
+		Buildpacks: []BOMBuildpack{},
+		Entries:    []BOMEntry{},
+	}
+
+	if cond := bld.Status.GetCondition(corev1alpha1.ConditionSucceeded); cond != nil && !cond.LastTransitionTime.Inner.IsZero() {
+		bom.Created = cond.LastTransitionTime.Inner.Time
+	}
+
+	for _, bp := range bld.Status.BuildMetadata {
+		bom.Buildpacks = append(bom.Buildpacks, BOMBuildpack{Id: bp.Id, Version: bp.Version})
+	}
+
+	labels := config.Config.Labels
+	if bom.StackId == "" {
+		bom.StackId = labels[StackIdLabel]
+	}
+
+	if label, ok := labels[BuildMetadataLabel]; ok {
+		var metadata buildMetadata
+		if err := json.Unmarshal([]byte(label), &metadata); err != nil {
+			return BOM{}, errors.Wrapf(err, "unable to parse label %q", BuildMetadataLabel)
+		}
+
+		bom.Entries = append(bom.Entries, metadata.BOM...)
+	}
+
+	return bom, nil
+}
+
+// EntryVersion returns the version of an entry. Older buildpacks record the
+// version in the entry metadata.
+func (e BOMEntry) EntryVersion() string {
+	if e.Version != "" {
+		return e.Version
+	}
+	return e.metadataString("version")
+}
+
+// PackageURL returns the package url recorded by the buildpack, if any.
+func (e BOMEntry) PackageURL() string {
+	return e.metadataString("purl")
+}
+
+// Licenses returns the SPDX license ids recorded by the buildpack.
+func (e BOMEntry) Licenses() []string {
+	list, ok := e.Metadata["licenses"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var licenses []string
+	for _, l := range list {
+		if license, ok := l.(map[string]interface{}); ok {
+			if id, ok := license["type"].(string); ok && id != "" {
+				licenses = append(licenses, id)
+			}
+		}
+	}
+	return licenses
+}
+
+func (e BOMEntry) metadataString(key string) string {
+	s, _ := e.Metadata[key].(string)
+	return s
+}
@@ -0,0 +1,249 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+const (
+	cycloneDXSpecVersion = "1.2"
+	spdxVersion          = "SPDX-2.2"
+	spdxNoAssertion      = "NOASSERTION"
+	bomCreator           = "Tool: kp"
+)
+
+type CycloneDXDocument struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    CycloneDXMetadata    `json:"metadata"`
+	Components  []CycloneDXComponent `json:"components"`
+}
+
+type CycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     []CycloneDXTool    `json:"tools"`
+	Component CycloneDXComponent `json:"component"`
+}
+
+type CycloneDXTool struct {
+	Name string `json:"name"`
+}
+
+type CycloneDXComponent struct {
+	Type        string             `json:"type"`
+	Group       string             `json:"group,omitempty"`
+	Name        string             `json:"name"`
+	Version     string             `json:"version,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Purl        string             `json:"purl,omitempty"`
+	Licenses    []CycloneDXLicense `json:"licenses,omitempty"`
+}
+
+type CycloneDXLicense struct {
+	License CycloneDXLicenseId `json:"license"`
+}
+
+type CycloneDXLicenseId struct {
+	Id string `json:"id"`
+}
+
+// CycloneDX returns the bill of materials as a CycloneDX document. BOM
+// entries are libraries, buildpacks are applications and the run image is
+// the container the image is based on.
+func (b BOM) CycloneDX() CycloneDXDocument {
+	repo, digest := splitImage(b.Image)
+
+	doc := CycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: cycloneDXSpecVersion,
+		Version:     1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: b.Created.UTC().Format(time.RFC3339),
+			Tools:     []CycloneDXTool{{Name: "kp"}},
+			Component: CycloneDXComponent{Type: "container", Name: repo, Version: digest},
+		},
+		Components: []CycloneDXComponent{},
+	}
+
+	for _, entry := range b.Entries {
+		c := CycloneDXComponent{
+			Type:        "library",
+			Name:        entry.Name,
+			Version:     entry.EntryVersion(),
+			Description: fmt.Sprintf("contributed by buildpack %s", entry.Buildpack.Id),
+			Purl:        entry.PackageURL(),
+		}
+		for _, license := range entry.Licenses() {
+			c.Licenses = append(c.Licenses, CycloneDXLicense{License: CycloneDXLicenseId{Id: license}})
+		}
+		doc.Components = append(doc.Components, c)
+	}
+
+	for _, bp := range b.Buildpacks {
+		doc.Components = append(doc.Components, CycloneDXComponent{
+			Type:        "application",
+			Name:        bp.Id,
+			Version:     bp.Version,
+			Description: "buildpack",
+		})
+	}
+
+	if b.RunImage != "" {
+		runRepo, runDigest := splitImage(b.RunImage)
+		doc.Components = append(doc.Components, CycloneDXComponent{
+			Type:        "container",
+			Name:        runRepo,
+			Version:     runDigest,
+			Description: fmt.Sprintf("run image of stack %s", b.StackId),
+		})
+	}
+
+	return doc
+}
+
+type SPDXDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type SPDXPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Comment          string            `json:"comment,omitempty"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs,omitempty"`
+}
+
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type SPDXRelationship struct {
+	SPDXElementId      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var spdxIdInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// SPDX returns the bill of materials as an SPDX document describing the
+// image, which contains a package for every BOM entry, buildpack and the run
+// image.
+func (b BOM) SPDX() SPDXDocument {
+	repo, digest := splitImage(b.Image)
+
+	doc := SPDXDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              repo,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", spdxId(repo), strings.TrimPrefix(digest, "sha256:")),
+		CreationInfo: SPDXCreationInfo{
+			Created:  b.Created.UTC().Format(time.RFC3339),
+			Creators: []string{bomCreator},
+		},
+		Packages:      []SPDXPackage{},
+		Relationships: []SPDXRelationship{},
+	}
+
+	imageId := "SPDXRef-Image"
+	doc.Packages = append(doc.Packages, newSPDXPackage(imageId, repo, digest, nil, "", ""))
+	doc.Relationships = append(doc.Relationships, SPDXRelationship{
+		SPDXElementId:      doc.SPDXID,
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: imageId,
+	})
+
+	contains := func(pkg SPDXPackage) {
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, SPDXRelationship{
+			SPDXElementId:      imageId,
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+
+	for i, entry := range b.Entries {
+		id := fmt.Sprintf("SPDXRef-Package-%d-%s", i+1, spdxId(entry.Name))
+		comment := fmt.Sprintf("contributed by buildpack %s", entry.Buildpack.Id)
+		contains(newSPDXPackage(id, entry.Name, entry.EntryVersion(), entry.Licenses(), entry.PackageURL(), comment))
+	}
+
+	for i, bp := range b.Buildpacks {
+		id := fmt.Sprintf("SPDXRef-Buildpack-%d-%s", i+1, spdxId(bp.Id))
+		contains(newSPDXPackage(id, bp.Id, bp.Version, nil, "", "buildpack"))
+	}
+
+	if b.RunImage != "" {
+		runRepo, runDigest := splitImage(b.RunImage)
+		contains(newSPDXPackage("SPDXRef-RunImage", runRepo, runDigest, nil, "", fmt.Sprintf("run image of stack %s", b.StackId)))
+	}
+
+	return doc
+}
+
+func newSPDXPackage(id, name, version string, licenses []string, purl, comment string) SPDXPackage {
+	declared := spdxNoAssertion
+	if len(licenses) > 0 {
+		declared = strings.Join(licenses, " AND ")
+	}
+
+	pkg := SPDXPackage{
+		Name:             name,
+		SPDXID:           id,
+		VersionInfo:      version,
+		DownloadLocation: spdxNoAssertion,
+		LicenseConcluded: spdxNoAssertion,
+		LicenseDeclared:  declared,
+		CopyrightText:    spdxNoAssertion,
+		Comment:          comment,
+	}
+
+	if purl != "" {
+		pkg.ExternalRefs = []SPDXExternalRef{{
+			ReferenceCategory: "PACKAGE_MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  purl,
+		}}
+	}
+	return pkg
+}
+
+// splitImage splits an image reference into its repository and digest or tag.
+func splitImage(image string) (string, string) {
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return image, ""
+	}
+	return ref.Context().Name(), ref.Identifier()
+}
+
+func spdxId(s string) string {
+	return strings.Trim(spdxIdInvalidChars.ReplaceAllString(s, "-"), "-")
+}
@@ -0,0 +1,141 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	registryfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
+)
+
+func TestBOM(t *testing.T) {
+	spec.Run(t, "TestBOM", testBOM)
+}
+
+func testBOM(t *testing.T, when spec.G, it spec.S) {
+	const (
+		digest   = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		metadata = `{"bom":[{"name":"jre","metadata":{"version":"11.0.8","purl":"pkg:generic/jre@11.0.8","licenses":[{"type":"GPL-2.0-only"},{"type":"Classpath-exception-2.0"}]},"buildpack":{"id":"paketo/jvm","version":"1.0.0"}},{"name":"spring-boot","version":"2.3.4","buildpack":{"id":"paketo/spring-boot","version":"2.0.0"}}],"buildpacks":[]}`
+	)
+
+	finished := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	bld := &v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{v1alpha1.BuildNumberLabel: "3"},
+		},
+		Status: v1alpha1.BuildStatus{
+			Status: corev1alpha1.Status{
+				Conditions: corev1alpha1.Conditions{
+					{
+						Type:               corev1alpha1.ConditionSucceeded,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: corev1alpha1.VolatileTime{Inner: metav1.Time{Time: finished}},
+					},
+				},
+			},
+			LatestImage: "registry.io/my-app@" + digest,
+			Stack:       v1alpha1.BuildStack{ID: "io.buildpacks.stacks.bionic", RunImage: "registry.io/run@" + digest},
+			BuildMetadata: v1alpha1.BuildpackMetadataList{
+				{Id: "paketo/jvm", Version: "1.0.0"},
+			},
+		},
+	}
+
+	when("#ReadBOM", func() {
+		it("merges the bom label with the buildpacks and stack of the build", func() {
+			bom, err := build.ReadBOM(registryfakes.NewFakeLabeledImage(build.BuildMetadataLabel, metadata, "abc"), bld)
+			require.NoError(t, err)
+
+			require.Equal(t, "registry.io/my-app@"+digest, bom.Image)
+			require.Equal(t, "3", bom.Build)
+			require.Equal(t, finished, bom.Created)
+			require.Equal(t, "io.buildpacks.stacks.bionic", bom.StackId)
+			require.Equal(t, []build.BOMBuildpack{{Id: "paketo/jvm", Version: "1.0.0"}}, bom.Buildpacks)
+			require.Len(t, bom.Entries, 2)
+
+			jre := bom.Entries[0]
+			require.Equal(t, "11.0.8", jre.EntryVersion())
+			require.Equal(t, "pkg:generic/jre@11.0.8", jre.PackageURL())
+			require.Equal(t, []string{"GPL-2.0-only", "Classpath-exception-2.0"}, jre.Licenses())
+			require.Equal(t, "2.3.4", bom.Entries[1].EntryVersion())
+		})
+
+		it("returns an empty bom when the image has no bom label", func() {
+			bom, err := build.ReadBOM(registryfakes.NewFakeLabeledImage(build.StackIdLabel, "some-stack", "abc"), bld)
+			require.NoError(t, err)
+			require.Empty(t, bom.Entries)
+		})
+
+		it("returns an error when the bom label is invalid", func() {
+			_, err := build.ReadBOM(registryfakes.NewFakeLabeledImage(build.BuildMetadataLabel, "{", "abc"), bld)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "unable to parse label \"io.buildpacks.build.metadata\"")
+		})
+	})
+
+	when("#CycloneDX", func() {
+		it("describes the image with its bom entries, buildpacks and run image as components", func() {
+			bom, err := build.ReadBOM(registryfakes.NewFakeLabeledImage(build.BuildMetadataLabel, metadata, "abc"), bld)
+			require.NoError(t, err)
+
+			doc := bom.CycloneDX()
+			require.Equal(t, "CycloneDX", doc.BOMFormat)
+			require.Equal(t, "2020-10-01T12:00:00Z", doc.Metadata.Timestamp)
+			require.Equal(t, build.CycloneDXComponent{Type: "container", Name: "registry.io/my-app", Version: digest}, doc.Metadata.Component)
+			require.Len(t, doc.Components, 4)
+			require.Equal(t, build.CycloneDXComponent{
+				Type:        "library",
+				Name:        "jre",
+				Version:     "11.0.8",
+				Description: "contributed by buildpack paketo/jvm",
+				Purl:        "pkg:generic/jre@11.0.8",
+				Licenses: []build.CycloneDXLicense{
+					{License: build.CycloneDXLicenseId{Id: "GPL-2.0-only"}},
+					{License: build.CycloneDXLicenseId{Id: "Classpath-exception-2.0"}},
+				},
+			}, doc.Components[0])
+			require.Equal(t, "application", doc.Components[2].Type)
+			require.Equal(t, "registry.io/run", doc.Components[3].Name)
+		})
+	})
+
+	when("#SPDX", func() {
+		it("describes the image as a package containing the bom entries, buildpacks and run image", func() {
+			bom, err := build.ReadBOM(registryfakes.NewFakeLabeledImage(build.BuildMetadataLabel, metadata, "abc"), bld)
+			require.NoError(t, err)
+
+			doc := bom.SPDX()
+			require.Equal(t, "SPDX-2.2", doc.SPDXVersion)
+			require.Equal(t, "https://spdx.org/spdxdocs/registry.io-my-app-1111111111111111111111111111111111111111111111111111111111111111", doc.DocumentNamespace)
+			require.Len(t, doc.Packages, 5)
+			require.Len(t, doc.Relationships, 5)
+
+			require.Equal(t, build.SPDXPackage{
+				Name:             "jre",
+				SPDXID:           "SPDXRef-Package-1-jre",
+				VersionInfo:      "11.0.8",
+				DownloadLocation: "NOASSERTION",
+				LicenseConcluded: "NOASSERTION",
+				LicenseDeclared:  "GPL-2.0-only AND Classpath-exception-2.0",
+				CopyrightText:    "NOASSERTION",
+				Comment:          "contributed by buildpack paketo/jvm",
+				ExternalRefs: []build.SPDXExternalRef{
+					{ReferenceCategory: "PACKAGE_MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:generic/jre@11.0.8"},
+				},
+			}, doc.Packages[1])
+			require.Equal(t, "SPDXRef-Buildpack-1-paketo-jvm", doc.Packages[3].SPDXID)
+			require.Equal(t, build.SPDXRelationship{SPDXElementId: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Image"}, doc.Relationships[0])
+			require.Equal(t, build.SPDXRelationship{SPDXElementId: "SPDXRef-Image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-RunImage"}, doc.Relationships[4])
+		})
+	})
+}
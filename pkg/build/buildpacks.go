@@ -0,0 +1,60 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+)
+
+type BuildpackChange struct {
+	Id         string `json:"id"`
+	OldVersion string `json:"oldVersion,omitempty"`
+	NewVersion string `json:"newVersion,omitempty"`
+}
+
+func (c BuildpackChange) String() string {
+	switch {
+	case c.OldVersion == "":
+		return fmt.Sprintf("+%s %s", c.Id, c.NewVersion)
+	case c.NewVersion == "":
+		return fmt.Sprintf("-%s %s", c.Id, c.OldVersion)
+	default:
+		return fmt.Sprintf("%s %s -> %s", c.Id, c.OldVersion, c.NewVersion)
+	}
+}
+
+// DiffBuildpacks returns the buildpacks added, removed or changed between two
+// builds, ordered by buildpack id.
+func DiffBuildpacks(previous, current v1alpha1.BuildpackMetadataList) []BuildpackChange {
+	oldVersions := map[string]string{}
+	for _, bp := range previous {
+		oldVersions[bp.Id] = bp.Version
+	}
+
+	newVersions := map[string]string{}
+	for _, bp := range current {
+		newVersions[bp.Id] = bp.Version
+	}
+
+	var changes []BuildpackChange
+	for id, version := range newVersions {
+		if oldVersion, ok := oldVersions[id]; !ok || oldVersion != version {
+			changes = append(changes, BuildpackChange{Id: id, OldVersion: oldVersion, NewVersion: version})
+		}
+	}
+
+	for id, version := range oldVersions {
+		if _, ok := newVersions[id]; !ok {
+			changes = append(changes, BuildpackChange{Id: id, OldVersion: version})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Id < changes[j].Id
+	})
+	return changes
+}
@@ -0,0 +1,49 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestDiffBuildpacks(t *testing.T) {
+	spec.Run(t, "TestDiffBuildpacks", testDiffBuildpacks)
+}
+
+func testDiffBuildpacks(t *testing.T, when spec.G, it spec.S) {
+	it("returns the added, removed and changed buildpacks ordered by id", func() {
+		previous := v1alpha1.BuildpackMetadataList{
+			{Id: "bp-c", Version: "3.0.0"},
+			{Id: "bp-a", Version: "1.0.0"},
+			{Id: "bp-d", Version: "4.0.0"},
+		}
+		current := v1alpha1.BuildpackMetadataList{
+			{Id: "bp-a", Version: "1.1.0"},
+			{Id: "bp-b", Version: "2.0.0"},
+			{Id: "bp-d", Version: "4.0.0"},
+		}
+
+		changes := build.DiffBuildpacks(previous, current)
+		require.Equal(t, []build.BuildpackChange{
+			{Id: "bp-a", OldVersion: "1.0.0", NewVersion: "1.1.0"},
+			{Id: "bp-b", NewVersion: "2.0.0"},
+			{Id: "bp-c", OldVersion: "3.0.0"},
+		}, changes)
+
+		require.Equal(t, "bp-a 1.0.0 -> 1.1.0", changes[0].String())
+		require.Equal(t, "+bp-b 2.0.0", changes[1].String())
+		require.Equal(t, "-bp-c 3.0.0", changes[2].String())
+	})
+
+	it("returns no changes for the same buildpacks", func() {
+		bps := v1alpha1.BuildpackMetadataList{{Id: "bp-a", Version: "1.0.0"}}
+		require.Empty(t, build.DiffBuildpacks(bps, bps))
+	})
+}
@@ -0,0 +1,31 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CancelledReason is the status reason of builds cancelled with kp.
+const CancelledReason = "Cancelled"
+
+// Cancel marks a build as failed with the cancelled reason. kpack does not
+// cancel builds itself, but it stops reconciling builds once they have
+// finished, so the build pod is not recreated after it is deleted.
+func Cancel(bld *v1alpha1.Build, now time.Time) {
+	bld.Status.Conditions = corev1alpha1.Conditions{
+		{
+			Type:               corev1alpha1.ConditionSucceeded,
+			Status:             corev1.ConditionFalse,
+			Reason:             CancelledReason,
+			Message:            "Build was cancelled",
+			LastTransitionTime: corev1alpha1.VolatileTime{Inner: metav1.NewTime(now)},
+		},
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestCancel(t *testing.T) {
+	spec.Run(t, "TestCancel", testCancel)
+}
+
+func testCancel(t *testing.T, when spec.G, it spec.S) {
+	it("marks a running build as failed with the cancelled reason", func() {
+		now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+		bld := &v1alpha1.Build{
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{
+						{Type: corev1alpha1.ConditionSucceeded, Status: corev1.ConditionUnknown},
+					},
+				},
+			},
+		}
+
+		build.Cancel(bld, now)
+
+		require.False(t, bld.IsRunning())
+		require.Equal(t, "FAILURE", build.Status(*bld))
+
+		cond := bld.Status.GetCondition(corev1alpha1.ConditionSucceeded)
+		require.Equal(t, build.CancelledReason, cond.Reason)
+		require.Equal(t, now, cond.LastTransitionTime.Inner.Time)
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+)
+
+type Failure string
+
+const (
+	FailureNoBuildpack   Failure = "NoBuildpackDetected"
+	FailureRegistryAuth  Failure = "RegistryAuthentication"
+	FailureOutOfMemory   Failure = "OutOfMemory"
+	FailureGitClone      Failure = "GitCloneFailed"
+	FailureStackMismatch Failure = "StackMismatch"
+	FailureUnknown       Failure = "Unknown"
+)
+
+var failureSummaries = map[Failure]string{
+	FailureNoBuildpack:   "No buildpack group passed detection",
+	FailureRegistryAuth:  "The registry rejected the credentials of the build",
+	FailureOutOfMemory:   "The build ran out of memory",
+	FailureGitClone:      "The git source could not be fetched",
+	FailureStackMismatch: "A buildpack does not support the stack of the builder",
+	FailureUnknown:       "The failure could not be classified",
+}
+
+func (f Failure) Summary() string {
+	return failureSummaries[f]
+}
+
+var (
+	noBuildpackPattern   = regexp.MustCompile(`(?i)no buildpack groups? passed detection`)
+	gitClonePattern      = regexp.MustCompile(`(?i)(error fetching git|unable to fetch references|could not read from remote repository|repository not found|ssh: handshake failed|fatal: )`)
+	stackMismatchPattern = regexp.MustCompile(`(?i)(incompatible stack|does not support stack|unsupported stack|stack .*(does not match|mismatch|not supported))`)
+	registryAuthPattern  = regexp.MustCompile(`(?i)(unauthorized|authentication required|access denied|denied:|forbidden|no basic auth credentials)`)
+)
+
+// lifecycle detect exits with this code when no buildpack group passes
+const detectFailedExitCode = 100
+
+// StepFailure describes the lifecycle step a build failed in.
+type StepFailure struct {
+	Step     string
+	ExitCode int32
+	Reason   string
+	Message  string
+}
+
+// FailedStep returns the first step of a build that terminated with a
+// non-zero exit code. Steps run in order, so the name of a terminated step
+// is at the same index in the completed steps as its state.
+func FailedStep(bld *v1alpha1.Build) (StepFailure, bool) {
+	for i, state := range bld.Status.StepStates {
+		terminated := state.Terminated
+		if terminated == nil || terminated.ExitCode == 0 {
+			continue
+		}
+
+		failure := StepFailure{
+			ExitCode: terminated.ExitCode,
+			Reason:   terminated.Reason,
+			Message:  terminated.Message,
+		}
+		if i < len(bld.Status.StepsCompleted) {
+			failure.Step = bld.Status.StepsCompleted[i]
+		}
+		return failure, true
+	}
+	return StepFailure{}, false
+}
+
+// Classify matches a failed step and its logs against common build failures.
+func Classify(bld *v1alpha1.Build, step StepFailure, logs []byte) Failure {
+	text := string(logs)
+
+	switch {
+	case step.Reason == "OOMKilled":
+		return FailureOutOfMemory
+	case step.Step == "prepare" && bld.Spec.Source.Git != nil && gitClonePattern.MatchString(text):
+		return FailureGitClone
+	case step.Step == "detect" && (step.ExitCode == detectFailedExitCode || noBuildpackPattern.MatchString(text)):
+		return FailureNoBuildpack
+	case stackMismatchPattern.MatchString(text):
+		return FailureStackMismatch
+	case registryAuthPattern.MatchString(text):
+		return FailureRegistryAuth
+	default:
+		return FailureUnknown
+	}
+}
+
+// LastLogLines returns up to n of the last non-empty log lines, without the
+// step headers.
+func LastLogLines(logs []byte, n int) []string {
+	var lines []string
+
+	s := bufio.NewScanner(bytes.NewReader(logs))
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "===> ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
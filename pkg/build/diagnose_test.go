@@ -0,0 +1,98 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestDiagnose(t *testing.T) {
+	spec.Run(t, "TestDiagnose", testDiagnose)
+}
+
+func testDiagnose(t *testing.T, when spec.G, it spec.S) {
+	terminated := func(exitCode int32, reason string) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Reason: reason}}
+	}
+
+	when("#FailedStep", func() {
+		it("returns the first step with a non-zero exit code", func() {
+			bld := &v1alpha1.Build{
+				Status: v1alpha1.BuildStatus{
+					StepStates: []corev1.ContainerState{
+						terminated(0, "Completed"),
+						terminated(0, "Completed"),
+						terminated(137, "OOMKilled"),
+						{Waiting: &corev1.ContainerStateWaiting{}},
+					},
+					StepsCompleted: []string{"prepare", "detect", "analyze"},
+				},
+			}
+
+			step, ok := build.FailedStep(bld)
+			require.True(t, ok)
+			require.Equal(t, build.StepFailure{Step: "analyze", ExitCode: 137, Reason: "OOMKilled"}, step)
+		})
+
+		it("returns false when no step failed", func() {
+			bld := &v1alpha1.Build{
+				Status: v1alpha1.BuildStatus{
+					StepStates:     []corev1.ContainerState{terminated(0, "Completed")},
+					StepsCompleted: []string{"prepare"},
+				},
+			}
+
+			_, ok := build.FailedStep(bld)
+			require.False(t, ok)
+		})
+	})
+
+	when("#Classify", func() {
+		gitBuild := &v1alpha1.Build{
+			Spec: v1alpha1.BuildSpec{
+				Source: v1alpha1.SourceConfig{Git: &v1alpha1.Git{URL: "https://github.com/some/repo", Revision: "main"}},
+			},
+		}
+
+		for _, c := range []struct {
+			name     string
+			step     build.StepFailure
+			logs     string
+			expected build.Failure
+		}{
+			{"out of memory", build.StepFailure{Step: "build", ExitCode: 137, Reason: "OOMKilled"}, "", build.FailureOutOfMemory},
+			{"git clone", build.StepFailure{Step: "prepare", ExitCode: 1}, "Error fetching git repository: repository not found", build.FailureGitClone},
+			{"no buildpack by exit code", build.StepFailure{Step: "detect", ExitCode: 100}, "", build.FailureNoBuildpack},
+			{"no buildpack by logs", build.StepFailure{Step: "detect", ExitCode: 1}, "ERROR: No buildpack groups passed detection.", build.FailureNoBuildpack},
+			{"stack mismatch", build.StepFailure{Step: "build", ExitCode: 1}, "buildpack some-bp does not support stack io.buildpacks.stacks.bionic", build.FailureStackMismatch},
+			{"registry auth", build.StepFailure{Step: "export", ExitCode: 1}, "ERROR: failed to export: UNAUTHORIZED: authentication required", build.FailureRegistryAuth},
+			{"unknown", build.StepFailure{Step: "build", ExitCode: 1}, "go: build failed", build.FailureUnknown},
+		} {
+			c := c
+			it("classifies "+c.name, func() {
+				require.Equal(t, c.expected, build.Classify(gitBuild, c.step, []byte(c.logs)))
+			})
+		}
+
+		it("does not classify prepare failures of non git sources as git failures", func() {
+			failure := build.Classify(&v1alpha1.Build{}, build.StepFailure{Step: "prepare", ExitCode: 1}, []byte("fatal: something"))
+			require.Equal(t, build.FailureUnknown, failure)
+		})
+	})
+
+	when("#LastLogLines", func() {
+		it("returns the last non-empty lines without step headers", func() {
+			logs := "===> BUILD\nline 1\n\nline 2\nline 3  \n===> EXPORT\nline 4\n"
+			require.Equal(t, []string{"line 2", "line 3", "line 4"}, build.LastLogLines([]byte(logs), 3))
+			require.Equal(t, []string{"line 1", "line 2", "line 3", "line 4"}, build.LastLogLines([]byte(logs), 10))
+		})
+	})
+}
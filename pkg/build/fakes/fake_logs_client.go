@@ -0,0 +1,34 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fakes
+
+import (
+	"context"
+	"io"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+type LogsCall struct {
+	Build   *v1alpha1.Build
+	Options build.LogOptions
+}
+
+type FakeLogsClient struct {
+	Output string
+	Err    error
+	Calls  []LogsCall
+}
+
+func (f *FakeLogsClient) Logs(ctx context.Context, out io.Writer, bld *v1alpha1.Build, opts build.LogOptions) error {
+	f.Calls = append(f.Calls, LogsCall{Build: bld, Options: opts})
+	if f.Err != nil {
+		return f.Err
+	}
+
+	_, err := io.WriteString(out, f.Output)
+	return err
+}
@@ -4,7 +4,11 @@
 package build
 
 import (
+	"strings"
+	"time"
+
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 )
 
 func Sort(builds []v1alpha1.Build) func(i int, j int) bool {
@@ -12,3 +16,75 @@ func Sort(builds []v1alpha1.Build) func(i int, j int) bool {
 		return builds[j].ObjectMeta.CreationTimestamp.After(builds[i].ObjectMeta.CreationTimestamp.Time)
 	}
 }
+
+func Status(b v1alpha1.Build) string {
+	cond := b.Status.GetCondition(corev1alpha1.ConditionSucceeded)
+	switch {
+	case cond.IsTrue():
+		return "SUCCESS"
+	case cond.IsFalse():
+		return "FAILURE"
+	case cond.IsUnknown():
+		return "BUILDING"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Duration returns the time between the creation of a build and its
+// completion. It returns false for builds that have not completed.
+func Duration(b v1alpha1.Build) (time.Duration, bool) {
+	if b.IsRunning() {
+		return 0, false
+	}
+
+	cond := b.Status.GetCondition(corev1alpha1.ConditionSucceeded)
+	if cond == nil || cond.LastTransitionTime.Inner.IsZero() || b.CreationTimestamp.IsZero() {
+		return 0, false
+	}
+
+	return cond.LastTransitionTime.Inner.Sub(b.CreationTimestamp.Time), true
+}
+
+func Reasons(b v1alpha1.Build) []string {
+	s := strings.Split(b.Annotations[v1alpha1.BuildReasonAnnotation], ",")
+	if len(s) == 1 && s[0] == "" {
+		return nil
+	}
+	return s
+}
+
+func TruncatedReason(b v1alpha1.Build) string {
+	r := Reasons(b)
+
+	if len(r) == 0 {
+		return "UNKNOWN"
+	}
+
+	if len(r) == 1 {
+		return r[0]
+	}
+
+	return mostImportantReason(r) + "+"
+}
+
+func mostImportantReason(r []string) string {
+	if contains(r, "CONFIG") {
+		return "CONFIG"
+	} else if contains(r, "COMMIT") {
+		return "COMMIT"
+	} else if contains(r, "BUILDPACK") {
+		return "BUILDPACK"
+	}
+
+	return r[0]
+}
+
+func contains(reasons []string, value string) bool {
+	for _, v := range reasons {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+const (
+	logArchiveFile = "build.log"
+
+	// LogArchiveConfigMediaType identifies the config of a log archive, so
+	// that the archive is not mistaken for a runnable image.
+	LogArchiveConfigMediaType types.MediaType = "application/vnd.kpack.build.logs.config.v1+json"
+)
+
+// ErrBuildPodNotFound is returned when the pod of a completed build has been
+// removed and its logs can only be read from a log archive.
+var ErrBuildPodNotFound = errors.New("build pod not found")
+
+// LogArchiveTag is the tag the logs of a build are archived at. It is in the
+// repository of the image built by the build.
+func LogArchiveTag(bld *v1alpha1.Build) (string, error) {
+	if len(bld.Spec.Tags) == 0 {
+		return "", errors.Errorf("build %q has no image tag", bld.Name)
+	}
+
+	tag, err := name.NewTag(bld.Spec.Tags[0], name.WeakValidation)
+	if err != nil {
+		return "", err
+	}
+
+	return tag.Context().Tag(bld.Name + ".logs").String(), nil
+}
+
+// NewLogArchive returns an OCI artifact with a single layer holding the logs
+// of a build. The logs should be read with timestamps and without color so
+// that FilterLogs can apply the options of the reader.
+func NewLogArchive(bld *v1alpha1.Build, logs []byte) (v1.Image, error) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     logArchiveFile,
+		Mode:     0644,
+		Size:     int64(len(logs)),
+		Typeflag: tar.TypeReg,
+		ModTime:  time.Unix(0, 0),
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(logs); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := mutate.AppendLayers(mutate.MediaType(empty.Image, types.OCIManifestSchema1), ociLayer{layer})
+	if err != nil {
+		return nil, errors.Wrap(err, "adding layer")
+	}
+
+	img, err = mutate.Config(img, v1.Config{
+		Labels: map[string]string{
+			v1alpha1.BuildLabel:       bld.Name,
+			v1alpha1.ImageLabel:       bld.Labels[v1alpha1.ImageLabel],
+			v1alpha1.BuildNumberLabel: bld.Labels[v1alpha1.BuildNumberLabel],
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return logArchive{img}, nil
+}
+
+// ociLayer is a layer with the OCI layer media type, tarball layers default
+// to the docker media type.
+type ociLayer struct {
+	v1.Layer
+}
+
+func (ociLayer) MediaType() (types.MediaType, error) {
+	return types.OCILayer, nil
+}
+
+// logArchive sets the log archive config media type in the manifest of an
+// image. The manifest digest and size are computed from the updated manifest.
+type logArchive struct {
+	v1.Image
+}
+
+func (a logArchive) Manifest() (*v1.Manifest, error) {
+	m, err := a.Image.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	m = m.DeepCopy()
+	m.MediaType = types.OCIManifestSchema1
+	m.Config.MediaType = LogArchiveConfigMediaType
+	return m, nil
+}
+
+func (a logArchive) RawManifest() ([]byte, error) {
+	m, err := a.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+func (a logArchive) Digest() (v1.Hash, error) {
+	return partial.Digest(a)
+}
+
+func (a logArchive) Size() (int64, error) {
+	return partial.Size(a)
+}
+
+func ReadLogArchive(img v1.Image) ([]byte, error) {
+	rc := mutate.Extract(img)
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("log archive does not contain any logs")
+		} else if err != nil {
+			return nil, err
+		}
+
+		if header.Name == logArchiveFile {
+			return ioutil.ReadAll(tr)
+		}
+	}
+}
+
+// FilterLogs applies the step, timestamp and color options to archived logs.
+func FilterLogs(logs []byte, opts LogOptions) ([]byte, error) {
+	if err := ValidateStep(opts.Step); err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	found := false
+	inStep := opts.Step == ""
+
+	s := bufio.NewScanner(bytes.NewReader(logs))
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		line := s.Text()
+
+		if strings.HasPrefix(line, "===> ") {
+			step := strings.ToLower(strings.TrimPrefix(line, "===> "))
+			inStep = matchesStep(step, opts.Step)
+			if inStep {
+				found = true
+				out.WriteString(stepHeader(step, opts.Color) + "\n")
+			}
+			continue
+		}
+
+		if !inStep {
+			continue
+		}
+
+		if !opts.Timestamps {
+			line = stripTimestamp(line)
+		}
+		out.WriteString(line + "\n")
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if opts.Step != "" && !found {
+		return nil, errors.Errorf("step %q is not in the log archive", opts.Step)
+	}
+	return out.Bytes(), nil
+}
+
+func stripTimestamp(line string) string {
+	parts := strings.SplitN(line, " ", 2)
+	if len(parts) != 2 {
+		return line
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, parts[0]); err != nil {
+		return line
+	}
+	return parts[1]
+}
@@ -0,0 +1,131 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"bytes"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestLogArchive(t *testing.T) {
+	spec.Run(t, "TestLogArchive", testLogArchive)
+}
+
+func testLogArchive(t *testing.T, when spec.G, it spec.S) {
+	bld := &v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "some-image-build-2-abcde",
+			Labels: map[string]string{
+				v1alpha1.ImageLabel:       "some-image",
+				v1alpha1.BuildNumberLabel: "2",
+			},
+		},
+		Spec: v1alpha1.BuildSpec{
+			Tags: []string{"some-registry.io/some-repo:latest", "some-registry.io/some-repo:b2"},
+		},
+	}
+
+	const logs = `===> PREPARE
+2020-10-01T10:00:00.000000001Z Preparing source
+===> DETECT
+2020-10-01T10:00:01.5Z 3 of 4 buildpacks participating
+2020-10-01T10:00:01.6Z paketo-buildpacks/go-build 0.0.1
+`
+
+	when("#LogArchiveTag", func() {
+		it("tags the logs in the repository of the image", func() {
+			tag, err := build.LogArchiveTag(bld)
+			require.NoError(t, err)
+			require.Equal(t, "some-registry.io/some-repo:some-image-build-2-abcde.logs", tag)
+		})
+
+		it("errors when the build has no tags", func() {
+			_, err := build.LogArchiveTag(&v1alpha1.Build{ObjectMeta: metav1.ObjectMeta{Name: "some-build"}})
+			require.EqualError(t, err, `build "some-build" has no image tag`)
+		})
+	})
+
+	when("#NewLogArchive", func() {
+		it("stores the logs so they can be read back", func() {
+			img, err := build.NewLogArchive(bld, []byte(logs))
+			require.NoError(t, err)
+
+			cfg, err := img.ConfigFile()
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{
+				v1alpha1.BuildLabel:       "some-image-build-2-abcde",
+				v1alpha1.ImageLabel:       "some-image",
+				v1alpha1.BuildNumberLabel: "2",
+			}, cfg.Config.Labels)
+
+			read, err := build.ReadLogArchive(img)
+			require.NoError(t, err)
+			require.Equal(t, logs, string(read))
+		})
+
+		it("is an OCI artifact with the log archive config media type", func() {
+			img, err := build.NewLogArchive(bld, []byte(logs))
+			require.NoError(t, err)
+
+			mediaType, err := img.MediaType()
+			require.NoError(t, err)
+			require.Equal(t, types.OCIManifestSchema1, mediaType)
+
+			rawManifest, err := img.RawManifest()
+			require.NoError(t, err)
+
+			manifest, err := v1.ParseManifest(bytes.NewReader(rawManifest))
+			require.NoError(t, err)
+			require.Equal(t, types.OCIManifestSchema1, manifest.MediaType)
+			require.Equal(t, build.LogArchiveConfigMediaType, manifest.Config.MediaType)
+			require.Len(t, manifest.Layers, 1)
+			require.Equal(t, types.OCILayer, manifest.Layers[0].MediaType)
+
+			digest, err := img.Digest()
+			require.NoError(t, err)
+			expected, _, err := v1.SHA256(bytes.NewReader(rawManifest))
+			require.NoError(t, err)
+			require.Equal(t, expected, digest)
+		})
+	})
+
+	when("#FilterLogs", func() {
+		it("keeps timestamps when requested", func() {
+			filtered, err := build.FilterLogs([]byte(logs), build.LogOptions{Timestamps: true})
+			require.NoError(t, err)
+			require.Equal(t, logs, string(filtered))
+		})
+
+		it("removes timestamps", func() {
+			filtered, err := build.FilterLogs([]byte(logs), build.LogOptions{})
+			require.NoError(t, err)
+			require.Equal(t, `===> PREPARE
+Preparing source
+===> DETECT
+3 of 4 buildpacks participating
+paketo-buildpacks/go-build 0.0.1
+`, string(filtered))
+		})
+
+		it("only keeps the requested step", func() {
+			filtered, err := build.FilterLogs([]byte(logs), build.LogOptions{Step: "prepare", Color: true})
+			require.NoError(t, err)
+			require.Equal(t, "\033[0;36m===> PREPARE\033[0m\nPreparing source\n", string(filtered))
+		})
+
+		it("errors when the step is not archived", func() {
+			_, err := build.FilterLogs([]byte(logs), build.LogOptions{Step: "export"})
+			require.EqualError(t, err, `step "export" is not in the log archive`)
+		})
+	})
+}
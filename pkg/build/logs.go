@@ -0,0 +1,237 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	k8sclient "k8s.io/client-go/kubernetes"
+)
+
+// Steps are the lifecycle containers of a build pod in the order they run.
+var Steps = []string{"prepare", "detect", "analyze", "restore", "build", "export"}
+
+type LogOptions struct {
+	Step       string
+	Timestamps bool
+	Follow     bool
+	Color      bool
+}
+
+func ValidateStep(step string) error {
+	if step == "" {
+		return nil
+	}
+
+	for _, s := range Steps {
+		if s == step {
+			return nil
+		}
+	}
+
+	return errors.Errorf("invalid step %q, must be one of %s", step, strings.Join(Steps, ", "))
+}
+
+// ContainerLogs opens the log stream of a single container in a pod.
+type ContainerLogs func(namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error)
+
+type LogsClient struct {
+	K8sClient     k8sclient.Interface
+	ContainerLogs ContainerLogs
+}
+
+func NewLogsClient(k8sClient k8sclient.Interface) *LogsClient {
+	return &LogsClient{
+		K8sClient: k8sClient,
+		ContainerLogs: func(namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+			return k8sClient.CoreV1().Pods(namespace).GetLogs(pod, opts).Stream()
+		},
+	}
+}
+
+// Logs writes the logs of the build pod containers to out. When following,
+// containers are streamed as they start until the build pod completes.
+// Otherwise only the logs of containers that have already started are written.
+func (c *LogsClient) Logs(ctx context.Context, out io.Writer, bld *v1alpha1.Build, opts LogOptions) error {
+	if err := ValidateStep(opts.Step); err != nil {
+		return err
+	}
+
+	if !bld.IsRunning() && bld.Status.PodName != "" {
+		_, err := c.K8sClient.CoreV1().Pods(bld.Namespace).Get(bld.Status.PodName, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return ErrBuildPodNotFound
+		} else if err != nil {
+			return err
+		}
+	}
+
+	var (
+		found bool
+		err   error
+	)
+	if opts.Follow {
+		found, err = c.follow(ctx, out, bld, opts)
+	} else {
+		found, err = c.dump(ctx, out, bld, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.Step != "" && !found {
+		return errors.Errorf("step %q did not run in build %q", opts.Step, bld.Name)
+	}
+	return nil
+}
+
+func (c *LogsClient) dump(ctx context.Context, out io.Writer, bld *v1alpha1.Build, opts LogOptions) (bool, error) {
+	if bld.Status.PodName == "" {
+		return false, errors.Errorf("build %q has not started", bld.Name)
+	}
+
+	pod, err := c.K8sClient.CoreV1().Pods(bld.Namespace).Get(bld.Status.PodName, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	for _, container := range startedContainers(pod) {
+		if !matchesStep(container, opts.Step) {
+			continue
+		}
+
+		found = true
+		if err := c.stream(ctx, out, pod, container, opts); err != nil {
+			return found, err
+		}
+	}
+	return found, nil
+}
+
+func (c *LogsClient) follow(ctx context.Context, out io.Writer, bld *v1alpha1.Build, opts LogOptions) (bool, error) {
+	watcher, err := c.K8sClient.CoreV1().Pods(bld.Namespace).Watch(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", v1alpha1.BuildLabel, bld.Name),
+	})
+	if err != nil {
+		return false, err
+	}
+	defer watcher.Stop()
+
+	processed := map[string]bool{}
+	for {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return false, nil
+			}
+
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok {
+				continue
+			}
+
+			for _, container := range startedContainers(pod) {
+				if processed[container] || !matchesStep(container, opts.Step) {
+					continue
+				}
+				processed[container] = true
+
+				if err := c.stream(ctx, out, pod, container, opts); err != nil {
+					return true, err
+				}
+
+				if opts.Step != "" {
+					return true, nil
+				}
+			}
+
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				return len(processed) > 0, nil
+			}
+		}
+	}
+}
+
+func (c *LogsClient) stream(ctx context.Context, out io.Writer, pod *corev1.Pod, container string, opts LogOptions) error {
+	logs, err := c.ContainerLogs(pod.Namespace, pod.Name, &corev1.PodLogOptions{
+		Container:  container,
+		Follow:     opts.Follow,
+		Timestamps: opts.Timestamps,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "unable to read logs of step %q", container)
+	}
+	defer logs.Close()
+
+	if _, err := fmt.Fprintln(out, stepHeader(container, opts.Color)); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(logs)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			if _, werr := out.Write(line); werr != nil {
+				return werr
+			}
+		}
+
+		if err == io.EOF {
+			if len(line) > 0 && line[len(line)-1] != '\n' {
+				_, err = fmt.Fprintln(out)
+				return err
+			}
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func startedContainers(pod *corev1.Pod) []string {
+	var containers []string
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, s := range statuses {
+			if s.State.Waiting == nil {
+				containers = append(containers, s.Name)
+			}
+		}
+	}
+	return containers
+}
+
+func stepHeader(step string, color bool) string {
+	header := fmt.Sprintf("===> %s", strings.ToUpper(step))
+	if color {
+		return "\033[0;36m" + header + "\033[0m"
+	}
+	return header
+}
+
+func matchesStep(container, step string) bool {
+	return step == "" || container == step
+}
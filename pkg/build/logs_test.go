@@ -0,0 +1,174 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestLogsClient(t *testing.T) {
+	spec.Run(t, "TestLogsClient", testLogsClient)
+}
+
+func testLogsClient(t *testing.T, when spec.G, it spec.S) {
+	const namespace = "some-namespace"
+
+	var (
+		k8sClient *fake.Clientset
+		client    *build.LogsClient
+		requested []corev1.PodLogOptions
+		out       *bytes.Buffer
+	)
+
+	bld := &v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "some-build",
+			Namespace: namespace,
+		},
+		Status: v1alpha1.BuildStatus{
+			PodName: "some-build-pod",
+		},
+	}
+
+	started := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}
+	waiting := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}
+
+	makePod := func(phase corev1.PodPhase, states ...corev1.ContainerState) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-build-pod",
+				Namespace: namespace,
+				Labels:    map[string]string{v1alpha1.BuildLabel: "some-build"},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+		for i, state := range states {
+			pod.Status.InitContainerStatuses = append(pod.Status.InitContainerStatuses, corev1.ContainerStatus{
+				Name:  build.Steps[i],
+				State: state,
+			})
+		}
+		return pod
+	}
+
+	it.Before(func() {
+		k8sClient = fake.NewSimpleClientset()
+		client = build.NewLogsClient(k8sClient)
+		client.ContainerLogs = func(ns, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+			require.Equal(t, namespace, ns)
+			require.Equal(t, "some-build-pod", pod)
+			requested = append(requested, *opts)
+			return ioutil.NopCloser(strings.NewReader(opts.Container + " line 1\n" + opts.Container + " line 2")), nil
+		}
+		requested = nil
+		out = &bytes.Buffer{}
+	})
+
+	when("not following", func() {
+		it("writes the logs of the started containers", func() {
+			k8sClient = fake.NewSimpleClientset(makePod(corev1.PodRunning, started, started, waiting))
+			client.K8sClient = k8sClient
+
+			err := client.Logs(context.Background(), out, bld, build.LogOptions{Timestamps: true})
+			require.NoError(t, err)
+
+			require.Equal(t, `===> PREPARE
+prepare line 1
+prepare line 2
+===> DETECT
+detect line 1
+detect line 2
+`, out.String())
+			require.Equal(t, []corev1.PodLogOptions{
+				{Container: "prepare", Timestamps: true},
+				{Container: "detect", Timestamps: true},
+			}, requested)
+		})
+
+		it("writes the logs of a single step", func() {
+			k8sClient = fake.NewSimpleClientset(makePod(corev1.PodSucceeded, started, started, started))
+			client.K8sClient = k8sClient
+
+			err := client.Logs(context.Background(), out, bld, build.LogOptions{Step: "analyze"})
+			require.NoError(t, err)
+
+			require.Equal(t, "===> ANALYZE\nanalyze line 1\nanalyze line 2\n", out.String())
+		})
+
+		it("errors when the step has not run", func() {
+			k8sClient = fake.NewSimpleClientset(makePod(corev1.PodRunning, started, waiting))
+			client.K8sClient = k8sClient
+
+			err := client.Logs(context.Background(), out, bld, build.LogOptions{Step: "detect"})
+			require.EqualError(t, err, `step "detect" did not run in build "some-build"`)
+		})
+
+		it("returns ErrBuildPodNotFound when the pod of a completed build has been removed", func() {
+			completed := bld.DeepCopy()
+			completed.Status.Conditions = corev1alpha1.Conditions{{Type: corev1alpha1.ConditionSucceeded, Status: corev1.ConditionTrue}}
+
+			err := client.Logs(context.Background(), out, completed, build.LogOptions{})
+			require.Equal(t, build.ErrBuildPodNotFound, err)
+		})
+
+		it("errors when the build has no pod", func() {
+			err := client.Logs(context.Background(), out, &v1alpha1.Build{ObjectMeta: metav1.ObjectMeta{Name: "pending"}}, build.LogOptions{})
+			require.EqualError(t, err, `build "pending" has not started`)
+		})
+	})
+
+	when("following", func() {
+		it("streams containers as they start until the pod completes", func() {
+			podWatcher := watch.NewFakeWithChanSize(3, false)
+			podWatcher.Add(makePod(corev1.PodPending, started, waiting))
+			podWatcher.Modify(makePod(corev1.PodRunning, started, started))
+			podWatcher.Modify(makePod(corev1.PodSucceeded, started, started, started))
+			k8sClient.PrependWatchReactor("pods", k8stesting.DefaultWatchReactor(podWatcher, nil))
+
+			err := client.Logs(context.Background(), out, bld, build.LogOptions{Follow: true, Color: true})
+			require.NoError(t, err)
+
+			require.Equal(t, []corev1.PodLogOptions{
+				{Container: "prepare", Follow: true},
+				{Container: "detect", Follow: true},
+				{Container: "analyze", Follow: true},
+			}, requested)
+			require.Contains(t, out.String(), "\033[0;36m===> ANALYZE\033[0m\n")
+		})
+
+		it("stops after the requested step", func() {
+			podWatcher := watch.NewFakeWithChanSize(2, false)
+			podWatcher.Add(makePod(corev1.PodRunning, started, started))
+			podWatcher.Modify(makePod(corev1.PodRunning, started, started, started))
+			k8sClient.PrependWatchReactor("pods", k8stesting.DefaultWatchReactor(podWatcher, nil))
+
+			err := client.Logs(context.Background(), out, bld, build.LogOptions{Follow: true, Step: "detect"})
+			require.NoError(t, err)
+
+			require.Equal(t, []corev1.PodLogOptions{{Container: "detect", Follow: true}}, requested)
+		})
+
+		it("errors for an invalid step", func() {
+			err := client.Logs(context.Background(), out, bld, build.LogOptions{Follow: true, Step: "compile"})
+			require.EqualError(t, err, `invalid step "compile", must be one of prepare, detect, analyze, restore, build, export`)
+		})
+	})
+}
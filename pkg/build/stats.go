@@ -0,0 +1,99 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+)
+
+// Stats summarizes the builds of a single image.
+type Stats struct {
+	Namespace string
+	Image     string
+	Builds    int
+	Succeeded int
+	Failed    int
+	Running   int
+	Reasons   map[string]int
+
+	durations []time.Duration
+}
+
+// SuccessRate returns the share of finished builds that succeeded. It
+// returns false when no build has finished.
+func (s Stats) SuccessRate() (float64, bool) {
+	finished := s.Succeeded + s.Failed
+	if finished == 0 {
+		return 0, false
+	}
+	return float64(s.Succeeded) / float64(finished), true
+}
+
+// Percentile returns the duration that p percent of the finished builds
+// completed within, using the nearest rank. It returns false when no build
+// has finished.
+func (s Stats) Percentile(p float64) (time.Duration, bool) {
+	if len(s.durations) == 0 {
+		return 0, false
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(s.durations))))
+	if rank < 1 {
+		rank = 1
+	}
+	return s.durations[rank-1], true
+}
+
+// Aggregate summarizes builds per image, sorted by namespace and image name.
+func Aggregate(builds []v1alpha1.Build) []Stats {
+	byImage := map[string]*Stats{}
+	for _, bld := range builds {
+		key := bld.Namespace + "/" + bld.Labels[v1alpha1.ImageLabel]
+		s, ok := byImage[key]
+		if !ok {
+			s = &Stats{
+				Namespace: bld.Namespace,
+				Image:     bld.Labels[v1alpha1.ImageLabel],
+				Reasons:   map[string]int{},
+			}
+			byImage[key] = s
+		}
+
+		s.Builds++
+		switch Status(bld) {
+		case "SUCCESS":
+			s.Succeeded++
+		case "FAILURE":
+			s.Failed++
+		case "BUILDING":
+			s.Running++
+		}
+
+		for _, reason := range Reasons(bld) {
+			s.Reasons[reason]++
+		}
+
+		if d, ok := Duration(bld); ok {
+			s.durations = append(s.durations, d)
+		}
+	}
+
+	var stats []Stats
+	for _, s := range byImage {
+		sort.Slice(s.durations, func(i, j int) bool { return s.durations[i] < s.durations[j] })
+		stats = append(stats, *s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Namespace != stats[j].Namespace {
+			return stats[i].Namespace < stats[j].Namespace
+		}
+		return stats[i].Image < stats[j].Image
+	})
+	return stats
+}
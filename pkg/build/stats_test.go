@@ -0,0 +1,97 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestStats(t *testing.T) {
+	spec.Run(t, "TestStats", testStats)
+}
+
+func testStats(t *testing.T, when spec.G, it spec.S) {
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	makeBuild := func(namespace, image string, status corev1.ConditionStatus, duration time.Duration, reasons string) v1alpha1.Build {
+		return v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         namespace,
+				CreationTimestamp: metav1.Time{Time: start},
+				Labels:            map[string]string{v1alpha1.ImageLabel: image},
+				Annotations:       map[string]string{v1alpha1.BuildReasonAnnotation: reasons},
+			},
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{
+						{
+							Type:               corev1alpha1.ConditionSucceeded,
+							Status:             status,
+							LastTransitionTime: corev1alpha1.VolatileTime{Inner: metav1.Time{Time: start.Add(duration)}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	when("#Aggregate", func() {
+		it("summarizes the builds of every image", func() {
+			stats := build.Aggregate([]v1alpha1.Build{
+				makeBuild("ns-b", "image-a", corev1.ConditionTrue, time.Minute, "CONFIG"),
+				makeBuild("ns-a", "image-b", corev1.ConditionTrue, 4*time.Minute, "COMMIT"),
+				makeBuild("ns-a", "image-b", corev1.ConditionFalse, time.Minute, "COMMIT,BUILDPACK"),
+				makeBuild("ns-a", "image-b", corev1.ConditionTrue, 2*time.Minute, "STACK"),
+				makeBuild("ns-a", "image-b", corev1.ConditionUnknown, 0, "TRIGGER"),
+			})
+			require.Len(t, stats, 2)
+
+			s := stats[0]
+			require.Equal(t, "ns-a", s.Namespace)
+			require.Equal(t, "image-b", s.Image)
+			require.Equal(t, 4, s.Builds)
+			require.Equal(t, 2, s.Succeeded)
+			require.Equal(t, 1, s.Failed)
+			require.Equal(t, 1, s.Running)
+			require.Equal(t, map[string]int{"COMMIT": 2, "BUILDPACK": 1, "STACK": 1, "TRIGGER": 1}, s.Reasons)
+
+			rate, ok := s.SuccessRate()
+			require.True(t, ok)
+			require.InDelta(t, 2.0/3.0, rate, 0.001)
+
+			p50, ok := s.Percentile(50)
+			require.True(t, ok)
+			require.Equal(t, 2*time.Minute, p50)
+
+			p95, ok := s.Percentile(95)
+			require.True(t, ok)
+			require.Equal(t, 4*time.Minute, p95)
+
+			require.Equal(t, "ns-b", stats[1].Namespace)
+		})
+
+		it("returns no rate or percentiles when no build has finished", func() {
+			stats := build.Aggregate([]v1alpha1.Build{
+				makeBuild("ns", "image", corev1.ConditionUnknown, 0, "CONFIG"),
+			})
+			require.Len(t, stats, 1)
+
+			_, ok := stats[0].SuccessRate()
+			require.False(t, ok)
+
+			_, ok = stats[0].Percentile(50)
+			require.False(t, ok)
+		})
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+)
+
+const rebaseStep = "rebase"
+
+const (
+	StepWaiting   = "Waiting"
+	StepRunning   = "Running"
+	StepSucceeded = "Succeeded"
+	StepFailed    = "Failed"
+)
+
+// StepStatus is the state of a single lifecycle container of a build pod.
+type StepStatus struct {
+	Name     string
+	State    string
+	ExitCode int32
+	Started  time.Time
+	Finished time.Time
+}
+
+// Duration returns how long a step ran. It returns false for steps that
+// have not terminated.
+func (s StepStatus) Duration() (time.Duration, bool) {
+	if s.Started.IsZero() || s.Finished.IsZero() {
+		return 0, false
+	}
+	return s.Finished.Sub(s.Started), true
+}
+
+// StepStatuses returns the state of every lifecycle container of a build in
+// the order they run. Only terminated steps are named in the build status,
+// the names of the remaining steps are inferred from the pod layout.
+func StepStatuses(bld *v1alpha1.Build) []StepStatus {
+	var statuses []StepStatus
+	for i, state := range bld.Status.StepStates {
+		status := StepStatus{
+			Name:  stepName(bld, i),
+			State: StepWaiting,
+		}
+
+		switch {
+		case state.Terminated != nil:
+			status.State = StepSucceeded
+			if state.Terminated.ExitCode != 0 {
+				status.State = StepFailed
+			}
+			status.ExitCode = state.Terminated.ExitCode
+			status.Started = state.Terminated.StartedAt.Time
+			status.Finished = state.Terminated.FinishedAt.Time
+		case state.Running != nil:
+			status.State = StepRunning
+			status.Started = state.Running.StartedAt.Time
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func stepName(bld *v1alpha1.Build, i int) string {
+	if i < len(bld.Status.StepsCompleted) {
+		return bld.Status.StepsCompleted[i]
+	}
+
+	switch len(bld.Status.StepStates) {
+	case len(Steps):
+		return Steps[i]
+	case 1:
+		return rebaseStep
+	default:
+		return ""
+	}
+}
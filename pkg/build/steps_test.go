@@ -0,0 +1,101 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestSteps(t *testing.T) {
+	spec.Run(t, "TestSteps", testSteps)
+}
+
+func testSteps(t *testing.T, when spec.G, it spec.S) {
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	terminated := func(exitCode int32, started, finished time.Duration) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			ExitCode:   exitCode,
+			StartedAt:  metav1.Time{Time: start.Add(started)},
+			FinishedAt: metav1.Time{Time: start.Add(finished)},
+		}}
+	}
+
+	when("#StepStatuses", func() {
+		it("returns the state of every step of a build", func() {
+			bld := &v1alpha1.Build{
+				Status: v1alpha1.BuildStatus{
+					StepStates: []corev1.ContainerState{
+						terminated(0, 0, 5*time.Second),
+						terminated(0, 5*time.Second, 8*time.Second),
+						terminated(1, 8*time.Second, 20*time.Second),
+						{Running: &corev1.ContainerStateRunning{StartedAt: metav1.Time{Time: start.Add(20 * time.Second)}}},
+						{Waiting: &corev1.ContainerStateWaiting{}},
+						{Waiting: &corev1.ContainerStateWaiting{}},
+					},
+					StepsCompleted: []string{"prepare", "detect", "analyze"},
+				},
+			}
+
+			statuses := build.StepStatuses(bld)
+			require.Len(t, statuses, 6)
+
+			require.Equal(t, build.StepStatus{
+				Name:     "detect",
+				State:    build.StepSucceeded,
+				Started:  start.Add(5 * time.Second),
+				Finished: start.Add(8 * time.Second),
+			}, statuses[1])
+
+			require.Equal(t, "analyze", statuses[2].Name)
+			require.Equal(t, build.StepFailed, statuses[2].State)
+			require.Equal(t, int32(1), statuses[2].ExitCode)
+
+			require.Equal(t, build.StepStatus{
+				Name:    "restore",
+				State:   build.StepRunning,
+				Started: start.Add(20 * time.Second),
+			}, statuses[3])
+
+			require.Equal(t, "export", statuses[5].Name)
+			require.Equal(t, build.StepWaiting, statuses[5].State)
+		})
+
+		it("names the single step of a rebase build", func() {
+			bld := &v1alpha1.Build{
+				Status: v1alpha1.BuildStatus{
+					StepStates: []corev1.ContainerState{
+						{Running: &corev1.ContainerStateRunning{}},
+					},
+				},
+			}
+
+			statuses := build.StepStatuses(bld)
+			require.Len(t, statuses, 1)
+			require.Equal(t, "rebase", statuses[0].Name)
+		})
+	})
+
+	when("#Duration", func() {
+		it("returns how long a terminated step ran", func() {
+			d, ok := build.StepStatus{Started: start, Finished: start.Add(90 * time.Second)}.Duration()
+			require.True(t, ok)
+			require.Equal(t, 90*time.Second, d)
+		})
+
+		it("returns false for steps that have not terminated", func() {
+			_, ok := build.StepStatus{Started: start}.Duration()
+			require.False(t, ok)
+		})
+	})
+}
@@ -14,3 +14,12 @@ func ExactArgsWithUsage(n int) cobra.PositionalArgs {
 		return nil
 	}
 }
+
+func MaxArgsWithUsage(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) > n {
+			return fmt.Errorf("accepts at most %d arg(s), received %d\n\n%s", n, len(args), cmd.UsageString())
+		}
+		return nil
+	}
+}
@@ -29,3 +29,28 @@ Actual:
 %v`, expectedMsg, err)
 	}
 }
+
+func TestMaxArgsWithUsage(t *testing.T) {
+	cmd := cobra.Command{
+		Args: commands.MaxArgsWithUsage(1),
+	}
+	cmd.SetUsageFunc(func(cmd *cobra.Command) error {
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), "some usage")
+		return err
+	})
+
+	if err := cmd.ValidateArgs([]string{"one"}); err != nil {
+		t.Errorf("Expected no error for one arg, got: %v", err)
+	}
+
+	expectedMsg := "accepts at most 1 arg(s), received 2\n\nsome usage\n"
+	err := cmd.ValidateArgs([]string{"one", "two"})
+
+	if err == nil || err.Error() != expectedMsg {
+		t.Errorf(`Did not return expected usage error from using too many args.
+Expected:
+%v
+Actual:
+%v`, expectedMsg, err)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+func NewArchiveLogsCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider, newLogsClient func(k8s.ClientSet) LogsClient) *cobra.Command {
+	var (
+		namespace   string
+		buildNumber string
+		tlsCfg      registry.TLSConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "archive-logs <image-name>",
+		Short: "Archive the logs of a completed image build",
+		Long: `Stores the logs of a completed build of an image in the registry so they remain available after the build pod is removed.
+
+The logs are uploaded as an image with a single layer to the repository of the image tag.
+They are tagged with the build name and a ".logs" suffix.
+"kp build logs" reads the archive once the build pod has been removed.
+
+The build defaults to the latest build number.
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build archive-logs my-image\nkp build archive-logs my-image -b 2 -n my-namespace",
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			bld, err := getBuild(cs, args[0], buildNumber)
+			if err != nil {
+				return err
+			}
+
+			number := bld.Labels[v1alpha1.BuildNumberLabel]
+			if bld.IsRunning() {
+				return errors.Errorf("build %q is still running, archive its logs once it completes", number)
+			}
+
+			tag, err := build.LogArchiveTag(&bld)
+			if err != nil {
+				return err
+			}
+
+			if err := ch.PrintStatus("Archiving logs of build %q of Image %q...", number, args[0]); err != nil {
+				return err
+			}
+
+			logs := &bytes.Buffer{}
+			err = newLogsClient(cs).Logs(context.Background(), logs, &bld, build.LogOptions{Timestamps: true})
+			if errors.Cause(err) == build.ErrBuildPodNotFound {
+				return errors.Errorf("the pod of build %q has been removed, its logs can no longer be archived", number)
+			} else if err != nil {
+				return err
+			}
+
+			img, err := build.NewLogArchive(&bld, logs.Bytes())
+			if err != nil {
+				return err
+			}
+
+			if _, err := rup.ImageWriter(ch.CanChangeState()).Write(img, tag, ch.Writer(), tlsCfg); err != nil {
+				return err
+			}
+
+			return ch.PrintResult("Logs archived to '%s'", tag)
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().Bool(commands.DryRunFlag, false, "read the logs without uploading the archive")
+	commands.SetTLSFlags(cmd, &tlsCfg)
+
+	return cmd
+}
@@ -0,0 +1,156 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	buildpkg "github.com/pivotal/build-service-cli/pkg/build"
+	buildfakes "github.com/pivotal/build-service-cli/pkg/build/fakes"
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	registryfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildArchiveLogsCommand(t *testing.T) {
+	spec.Run(t, "TestBuildArchiveLogsCommand", testBuildArchiveLogsCommand)
+}
+
+func testBuildArchiveLogsCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+		archiveTag       = "some-registry.io/some-repo:build-four.logs"
+	)
+
+	var (
+		fakeLogsClient  *buildfakes.FakeLogsClient
+		fakeImageWriter *registryfakes.ImageWriter
+	)
+
+	newLogsClient := func(k8s.ClientSet) build.LogsClient {
+		return fakeLogsClient
+	}
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		rup := registryfakes.UtilProvider{FakeImageWriter: fakeImageWriter}
+		return build.NewArchiveLogsCommand(clientSetProvider, rup, newLogsClient)
+	}
+
+	makeBuild := func(status corev1.ConditionStatus) *v1alpha1.Build {
+		return &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "build-four",
+				Namespace: defaultNamespace,
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       image,
+					v1alpha1.BuildNumberLabel: "4",
+				},
+			},
+			Spec: v1alpha1.BuildSpec{
+				Tags: []string{"some-registry.io/some-repo"},
+			},
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{{Type: corev1alpha1.ConditionSucceeded, Status: status}},
+				},
+				PodName: "pod-four",
+			},
+		}
+	}
+
+	it.Before(func() {
+		fakeLogsClient = &buildfakes.FakeLogsClient{Output: "===> BUILD\nBuilding\n"}
+		fakeImageWriter = &registryfakes.ImageWriter{}
+	})
+
+	it("uploads the logs of a completed build", func() {
+		bld := makeBuild(corev1.ConditionFalse)
+		archive, err := buildpkg.NewLogArchive(bld, []byte("===> BUILD\nBuilding\n"))
+		require.NoError(t, err)
+		digest, err := archive.Digest()
+		require.NoError(t, err)
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{bld},
+			Args:    []string{image},
+			ExpectedOutput: `Archiving logs of build "4" of Image "test-image"...
+	Uploading 'some-registry.io/some-repo@` + digest.String() + `'
+Logs archived to 'some-registry.io/some-repo:build-four.logs'
+`,
+		}.TestKpack(t, cmdFunc)
+
+		require.Len(t, fakeLogsClient.Calls, 1)
+		require.Equal(t, buildpkg.LogOptions{Timestamps: true}, fakeLogsClient.Calls[0].Options)
+
+		logs, err := buildpkg.ReadLogArchive(fakeImageWriter.Images()[archiveTag])
+		require.NoError(t, err)
+		require.Equal(t, "===> BUILD\nBuilding\n", string(logs))
+	})
+
+	it("does not upload the logs with --dry-run", func() {
+		fakeImageWriter.SetSkip(true)
+
+		bld := makeBuild(corev1.ConditionTrue)
+		archive, err := buildpkg.NewLogArchive(bld, []byte("===> BUILD\nBuilding\n"))
+		require.NoError(t, err)
+		digest, err := archive.Digest()
+		require.NoError(t, err)
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{bld},
+			Args:    []string{image, "--dry-run"},
+			ExpectedOutput: `Archiving logs of build "4" of Image "test-image"... (dry run)
+	Skipping 'some-registry.io/some-repo@` + digest.String() + `'
+Logs archived to 'some-registry.io/some-repo:build-four.logs' (dry run)
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when the build is still running", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{makeBuild(corev1.ConditionUnknown)},
+			Args:           []string{image},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: build \"4\" is still running, archive its logs once it completes\n",
+		}.TestKpack(t, cmdFunc)
+
+		require.Len(t, fakeLogsClient.Calls, 0)
+	})
+
+	it("errors when the build pod has been removed", func() {
+		fakeLogsClient.Err = buildpkg.ErrBuildPodNotFound
+
+		testhelpers.CommandTest{
+			Objects:   []runtime.Object{makeBuild(corev1.ConditionTrue)},
+			Args:      []string{image},
+			ExpectErr: true,
+			ExpectedOutput: `Archiving logs of build "4" of Image "test-image"...
+Error: the pod of build "4" has been removed, its logs can no longer be archived
+`,
+		}.TestKpack(t, cmdFunc)
+
+		require.Len(t, fakeImageWriter.Images(), 0)
+	})
+
+	it("errors when there are no builds", func() {
+		testhelpers.CommandTest{
+			Args:           []string{image},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: no builds found\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
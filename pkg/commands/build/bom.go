@@ -0,0 +1,109 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+const (
+	bomFormatJSON      = "json"
+	bomFormatCycloneDX = "cyclonedx"
+	bomFormatSPDX      = "spdx"
+)
+
+func NewBOMCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider) *cobra.Command {
+	var (
+		namespace   string
+		buildNumber string
+		format      string
+		tlsCfg      registry.TLSConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bom <image-name>",
+		Short: "Export the bill of materials of an image build",
+		Long: `Prints the bill of materials of the image built by a build in the provided namespace.
+
+The bill of materials is read from the CNB build metadata label of the built image and merged with the
+buildpacks and stack of the build.
+
+The "--format" flag prints the bill of materials as kp json, as a CycloneDX 1.2 document or as an
+SPDX 2.2 document. Both documents are json.
+
+The build defaults to the latest build number.
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build bom my-image\nkp build bom my-image -b 2 --format cyclonedx\nkp build bom my-image --format spdx -n my-namespace",
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case bomFormatJSON, bomFormatCycloneDX, bomFormatSPDX:
+			default:
+				return errors.Errorf("unsupported format: %q, supported formats are cyclonedx, spdx, json", format)
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			bld, err := getBuild(cs, args[0], buildNumber)
+			if err != nil {
+				return err
+			}
+
+			if build.Status(bld) != "SUCCESS" || bld.Status.LatestImage == "" {
+				return errors.Errorf("build %q of Image %q did not succeed, it has no bill of materials", bld.Labels[v1alpha1.BuildNumberLabel], args[0])
+			}
+
+			img, err := rup.Fetcher().Fetch(bld.Status.LatestImage, tlsCfg)
+			if err != nil {
+				return errors.Wrapf(err, "unable to fetch image '%s'", bld.Status.LatestImage)
+			}
+
+			bom, err := build.ReadBOM(img, &bld)
+			if err != nil {
+				return err
+			}
+
+			return printBOM(cmd.OutOrStdout(), bom, format)
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().StringVar(&format, "format", bomFormatJSON, "bill of materials format, supported formats are: cyclonedx, spdx, json")
+	commands.SetTLSFlags(cmd, &tlsCfg)
+
+	return cmd
+}
+
+func printBOM(out io.Writer, bom build.BOM, format string) error {
+	var doc interface{} = bom
+	switch format {
+	case bomFormatCycloneDX:
+		doc = bom.CycloneDX()
+	case bomFormatSPDX:
+		doc = bom.SPDX()
+	}
+
+	buf, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, string(buf))
+	return err
+}
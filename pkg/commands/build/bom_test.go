@@ -0,0 +1,294 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	buildpkg "github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	registryfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildBOMCommand(t *testing.T) {
+	spec.Run(t, "TestBuildBOMCommand", testBuildBOMCommand)
+}
+
+func testBuildBOMCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+		digest           = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		latestImage      = "registry.io/test-image@" + digest
+	)
+
+	var fakeFetcher *registryfakes.Fetcher
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return build.NewBOMCommand(clientSetProvider, registryfakes.UtilProvider{FakeFetcher: fakeFetcher})
+	}
+
+	makeBuild := func(name, number string, status corev1.ConditionStatus) *v1alpha1.Build {
+		return &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: defaultNamespace,
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       image,
+					v1alpha1.BuildNumberLabel: number,
+				},
+			},
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{
+						{
+							Type:               corev1alpha1.ConditionSucceeded,
+							Status:             status,
+							LastTransitionTime: corev1alpha1.VolatileTime{Inner: metav1.Time{Time: time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)}},
+						},
+					},
+				},
+				LatestImage: latestImage,
+				Stack:       v1alpha1.BuildStack{ID: "io.buildpacks.stacks.bionic", RunImage: "registry.io/run@" + digest},
+				BuildMetadata: v1alpha1.BuildpackMetadataList{
+					{Id: "paketo/jvm", Version: "1.0.0"},
+				},
+			},
+		}
+	}
+
+	builds := []runtime.Object{
+		makeBuild("build-one", "1", corev1.ConditionTrue),
+		makeBuild("build-two", "2", corev1.ConditionFalse),
+	}
+
+	it.Before(func() {
+		fakeFetcher = &registryfakes.Fetcher{}
+		fakeFetcher.AddImage(latestImage, registryfakes.NewFakeLabeledImage(
+			buildpkg.BuildMetadataLabel,
+			`{"bom":[{"name":"jre","version":"11.0.8","metadata":{"licenses":[{"type":"GPL-2.0-only"}]},"buildpack":{"id":"paketo/jvm","version":"1.0.0"}}]}`,
+			"abc",
+		))
+	})
+
+	it("prints the bill of materials as json", func() {
+		testhelpers.CommandTest{
+			Objects: builds,
+			Args:    []string{image, "-b", "1"},
+			ExpectedOutput: `{
+  "image": "registry.io/test-image@sha256:1111111111111111111111111111111111111111111111111111111111111111",
+  "build": "1",
+  "created": "2020-10-01T12:00:00Z",
+  "stackId": "io.buildpacks.stacks.bionic",
+  "runImage": "registry.io/run@sha256:1111111111111111111111111111111111111111111111111111111111111111",
+  "buildpacks": [
+    {
+      "id": "paketo/jvm",
+      "version": "1.0.0"
+    }
+  ],
+  "bom": [
+    {
+      "name": "jre",
+      "version": "11.0.8",
+      "metadata": {
+        "licenses": [
+          {
+            "type": "GPL-2.0-only"
+          }
+        ]
+      },
+      "buildpack": {
+        "id": "paketo/jvm",
+        "version": "1.0.0"
+      }
+    }
+  ]
+}
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("prints the bill of materials as a cyclonedx document", func() {
+		testhelpers.CommandTest{
+			Objects: builds,
+			Args:    []string{image, "-b", "1", "--format", "cyclonedx"},
+			ExpectedOutput: `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.2",
+  "version": 1,
+  "metadata": {
+    "timestamp": "2020-10-01T12:00:00Z",
+    "tools": [
+      {
+        "name": "kp"
+      }
+    ],
+    "component": {
+      "type": "container",
+      "name": "registry.io/test-image",
+      "version": "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+    }
+  },
+  "components": [
+    {
+      "type": "library",
+      "name": "jre",
+      "version": "11.0.8",
+      "description": "contributed by buildpack paketo/jvm",
+      "licenses": [
+        {
+          "license": {
+            "id": "GPL-2.0-only"
+          }
+        }
+      ]
+    },
+    {
+      "type": "application",
+      "name": "paketo/jvm",
+      "version": "1.0.0",
+      "description": "buildpack"
+    },
+    {
+      "type": "container",
+      "name": "registry.io/run",
+      "version": "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+      "description": "run image of stack io.buildpacks.stacks.bionic"
+    }
+  ]
+}
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("prints the bill of materials as an spdx document", func() {
+		testhelpers.CommandTest{
+			Objects: builds,
+			Args:    []string{image, "-b", "1", "--format", "spdx"},
+			ExpectedOutput: `{
+  "spdxVersion": "SPDX-2.2",
+  "dataLicense": "CC0-1.0",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "registry.io/test-image",
+  "documentNamespace": "https://spdx.org/spdxdocs/registry.io-test-image-1111111111111111111111111111111111111111111111111111111111111111",
+  "creationInfo": {
+    "created": "2020-10-01T12:00:00Z",
+    "creators": [
+      "Tool: kp"
+    ]
+  },
+  "packages": [
+    {
+      "name": "registry.io/test-image",
+      "SPDXID": "SPDXRef-Image",
+      "versionInfo": "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+      "downloadLocation": "NOASSERTION",
+      "filesAnalyzed": false,
+      "licenseConcluded": "NOASSERTION",
+      "licenseDeclared": "NOASSERTION",
+      "copyrightText": "NOASSERTION"
+    },
+    {
+      "name": "jre",
+      "SPDXID": "SPDXRef-Package-1-jre",
+      "versionInfo": "11.0.8",
+      "downloadLocation": "NOASSERTION",
+      "filesAnalyzed": false,
+      "licenseConcluded": "NOASSERTION",
+      "licenseDeclared": "GPL-2.0-only",
+      "copyrightText": "NOASSERTION",
+      "comment": "contributed by buildpack paketo/jvm"
+    },
+    {
+      "name": "paketo/jvm",
+      "SPDXID": "SPDXRef-Buildpack-1-paketo-jvm",
+      "versionInfo": "1.0.0",
+      "downloadLocation": "NOASSERTION",
+      "filesAnalyzed": false,
+      "licenseConcluded": "NOASSERTION",
+      "licenseDeclared": "NOASSERTION",
+      "copyrightText": "NOASSERTION",
+      "comment": "buildpack"
+    },
+    {
+      "name": "registry.io/run",
+      "SPDXID": "SPDXRef-RunImage",
+      "versionInfo": "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+      "downloadLocation": "NOASSERTION",
+      "filesAnalyzed": false,
+      "licenseConcluded": "NOASSERTION",
+      "licenseDeclared": "NOASSERTION",
+      "copyrightText": "NOASSERTION",
+      "comment": "run image of stack io.buildpacks.stacks.bionic"
+    }
+  ],
+  "relationships": [
+    {
+      "spdxElementId": "SPDXRef-DOCUMENT",
+      "relationshipType": "DESCRIBES",
+      "relatedSpdxElement": "SPDXRef-Image"
+    },
+    {
+      "spdxElementId": "SPDXRef-Image",
+      "relationshipType": "CONTAINS",
+      "relatedSpdxElement": "SPDXRef-Package-1-jre"
+    },
+    {
+      "spdxElementId": "SPDXRef-Image",
+      "relationshipType": "CONTAINS",
+      "relatedSpdxElement": "SPDXRef-Buildpack-1-paketo-jvm"
+    },
+    {
+      "spdxElementId": "SPDXRef-Image",
+      "relationshipType": "CONTAINS",
+      "relatedSpdxElement": "SPDXRef-RunImage"
+    }
+  ]
+}
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error when the build did not succeed", func() {
+		testhelpers.CommandTest{
+			Objects:        builds,
+			Args:           []string{image},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: build \"2\" of Image \"test-image\" did not succeed, it has no bill of materials\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error when the image cannot be fetched", func() {
+		fakeFetcher = &registryfakes.Fetcher{}
+
+		testhelpers.CommandTest{
+			Objects:        builds,
+			Args:           []string{image, "-b", "1"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: unable to fetch image 'registry.io/test-image@sha256:1111111111111111111111111111111111111111111111111111111111111111': image not found: \"registry.io/test-image@sha256:1111111111111111111111111111111111111111111111111111111111111111\"\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error for an unsupported format", func() {
+		testhelpers.CommandTest{
+			Objects:        builds,
+			Args:           []string{image, "--format", "xml"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: unsupported format: \"xml\", supported formats are cyclonedx, spdx, json\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
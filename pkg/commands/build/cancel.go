@@ -0,0 +1,252 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+type ConfirmationProvider interface {
+	Confirm(message string, okayResponses ...string) (bool, error)
+}
+
+type cancelOptions struct {
+	buildNumber   string
+	allRunning    bool
+	selector      string
+	allNamespaces bool
+	force         bool
+}
+
+func NewCancelCommand(clientSetProvider k8s.ClientSetProvider, confirmationProvider ConfirmationProvider) *cobra.Command {
+	var (
+		namespace string
+		opts      cancelOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "cancel [image-name]",
+		Short: "Cancel a running image build",
+		Long: `Cancels a running build of an image in the provided namespace.
+
+kpack has no cancel mechanism, so the build is marked as failed with the "Cancelled" reason and its pod
+is deleted. kpack does not reconcile failed builds, so the pod is not recreated.
+
+The build defaults to the latest build number.
+
+The "--all-running" flag cancels every running build in the namespace, or in all namespaces with
+"--all-namespaces". The builds may be narrowed down with a label selector or an image name.
+The selected builds are listed for confirmation unless "--force" is provided.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example: `kp build cancel my-image
+kp build cancel my-image -b 3 -n my-namespace
+kp build cancel --all-running -l team=my-team
+kp build cancel --all-running --all-namespaces --force`,
+		Args:         cancelArgs(&opts),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			if opts.allRunning {
+				var image string
+				if len(args) == 1 {
+					image = args[0]
+				}
+				return cancelRunningBuilds(cmd, cs, confirmationProvider, opts, image)
+			}
+
+			bld, err := getBuild(cs, args[0], opts.buildNumber)
+			if err != nil {
+				return err
+			}
+
+			if !bld.IsRunning() {
+				return errors.Errorf("build %q of Image %q is not running", bld.Labels[v1alpha1.BuildNumberLabel], args[0])
+			}
+
+			result, err := cancelBuild(cs, bld)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Build %q of Image %q %s\n", bld.Labels[v1alpha1.BuildNumberLabel], args[0], result)
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&opts.buildNumber, "build", "b", "", "build number")
+	cmd.Flags().BoolVar(&opts.allRunning, "all-running", false, "cancel all running builds")
+	cmd.Flags().StringVarP(&opts.selector, "selector", "l", "", "label selector to select builds with --all-running (e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVarP(&opts.allNamespaces, "all-namespaces", "A", false, "select builds across all namespaces with --all-running")
+	cmd.Flags().BoolVarP(&opts.force, "force", "f", false, "skip confirmation when cancelling multiple builds")
+
+	return cmd
+}
+
+func cancelArgs(opts *cancelOptions) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if opts.allRunning {
+			if opts.buildNumber != "" {
+				return fmt.Errorf("build number cannot be provided with --all-running\n\n%s", cmd.UsageString())
+			}
+			return commands.MaxArgsWithUsage(1)(cmd, args)
+		}
+
+		if opts.selector != "" || opts.allNamespaces {
+			return fmt.Errorf("--selector and --all-namespaces can only be used with --all-running\n\n%s", cmd.UsageString())
+		}
+		return commands.ExactArgsWithUsage(1)(cmd, args)
+	}
+}
+
+// cancelBuild marks a build as cancelled before deleting its pod, so that
+// kpack does not recreate the pod. It returns a description of what was stopped.
+func cancelBuild(cs k8s.ClientSet, bld v1alpha1.Build) (string, error) {
+	cancelled := bld.DeepCopy()
+	build.Cancel(cancelled, time.Now())
+
+	_, err := cs.KpackClient.KpackV1alpha1().Builds(bld.Namespace).UpdateStatus(cancelled)
+	if err != nil {
+		return "", err
+	}
+
+	if bld.Status.PodName == "" {
+		return "cancelled before its pod was created", nil
+	}
+
+	err = cs.K8sClient.CoreV1().Pods(bld.Namespace).Delete(bld.Status.PodName, &metav1.DeleteOptions{})
+	if k8serrors.IsNotFound(err) {
+		return fmt.Sprintf("cancelled, pod %q was already removed", bld.Status.PodName), nil
+	} else if err != nil {
+		return "", errors.Wrapf(err, "build cancelled but its pod %q could not be deleted", bld.Status.PodName)
+	}
+
+	return fmt.Sprintf("cancelled, pod %q deleted", bld.Status.PodName), nil
+}
+
+func cancelRunningBuilds(cmd *cobra.Command, cs k8s.ClientSet, confirmationProvider ConfirmationProvider, opts cancelOptions, image string) error {
+	out := cmd.OutOrStdout()
+
+	namespace := cs.Namespace
+	if opts.allNamespaces {
+		namespace = metav1.NamespaceAll
+	}
+
+	selector := opts.selector
+	if image != "" {
+		if selector != "" {
+			selector += ","
+		}
+		selector += v1alpha1.ImageLabel + "=" + image
+	}
+
+	buildList, err := cs.KpackClient.KpackV1alpha1().Builds(namespace).List(metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return err
+	}
+
+	var builds []v1alpha1.Build
+	for _, bld := range buildList.Items {
+		if bld.IsRunning() {
+			builds = append(builds, bld)
+		}
+	}
+
+	if len(builds) == 0 {
+		_, err := fmt.Fprintln(out, "No running builds found")
+		return err
+	}
+
+	sort.Slice(builds, func(i, j int) bool {
+		if builds[i].Namespace != builds[j].Namespace {
+			return builds[i].Namespace < builds[j].Namespace
+		}
+		return build.Sort(builds)(i, j)
+	})
+
+	if !opts.force {
+		confirmed, err := confirmCancel(out, confirmationProvider, builds)
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			_, err = fmt.Fprintln(out, "Skipping build cancel")
+			return err
+		}
+	}
+
+	writer, err := commands.NewTableWriter(out, "Namespace", "Image", "Build", "Result")
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, bld := range builds {
+		result, err := cancelBuild(cs, bld)
+		if err != nil {
+			failed++
+			result = fmt.Sprintf("error: %s", err)
+		}
+
+		err = writer.AddRow(bld.Namespace, bld.Labels[v1alpha1.ImageLabel], bld.Labels[v1alpha1.BuildNumberLabel], result)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Write(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return errors.Errorf("cancel failed for %d of %d build(s)", failed, len(builds))
+	}
+	return nil
+}
+
+func confirmCancel(out io.Writer, confirmationProvider ConfirmationProvider, builds []v1alpha1.Build) (bool, error) {
+	_, err := fmt.Fprintln(out, "The following running builds were selected for cancel:")
+	if err != nil {
+		return false, err
+	}
+
+	writer, err := commands.NewTableWriter(out, "Namespace", "Image", "Build", "Pod Name")
+	if err != nil {
+		return false, err
+	}
+
+	for _, bld := range builds {
+		err := writer.AddRow(bld.Namespace, bld.Labels[v1alpha1.ImageLabel], bld.Labels[v1alpha1.BuildNumberLabel], bld.Status.PodName)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if err := writer.Write(); err != nil {
+		return false, err
+	}
+
+	message := fmt.Sprintf("Please confirm cancel of %d build(s) by typing 'y': ", len(builds))
+	return confirmationProvider.Confirm(message)
+}
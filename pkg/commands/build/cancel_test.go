@@ -0,0 +1,228 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfakes "k8s.io/client-go/kubernetes/fake"
+
+	buildpkg "github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	commandsfakes "github.com/pivotal/build-service-cli/pkg/commands/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildCancelCommand(t *testing.T) {
+	spec.Run(t, "TestBuildCancelCommand", testBuildCancelCommand)
+}
+
+func testBuildCancelCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+	)
+
+	var (
+		k8sClient            *k8sfakes.Clientset
+		kpackClient          *kpackfakes.Clientset
+		confirmationProvider *commandsfakes.FakeConfirmationProvider
+	)
+
+	makePod := func(namespace, name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+
+	makeRunningBuild := func(namespace, image, number string, labels map[string]string) *v1alpha1.Build {
+		bld := &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      image + "-build-" + number,
+				Namespace: namespace,
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       image,
+					v1alpha1.BuildNumberLabel: number,
+				},
+			},
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{
+						{Type: corev1alpha1.ConditionSucceeded, Status: corev1.ConditionUnknown},
+					},
+				},
+				PodName: image + "-build-" + number + "-pod",
+			},
+		}
+		for k, v := range labels {
+			bld.Labels[k] = v
+		}
+		return bld
+	}
+
+	run := func(args ...string) (string, error) {
+		clientSetProvider := testhelpers.GetFakeProvider(k8sClient, kpackClient, defaultNamespace)
+		cmd := build.NewCancelCommand(clientSetProvider, confirmationProvider)
+		cmd.SetArgs(args)
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(out)
+
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	cancelledBuilds := func() []*v1alpha1.Build {
+		actions, err := testhelpers.ActionRecorderList{kpackClient}.ActionsByVerb()
+		require.NoError(t, err)
+
+		var builds []*v1alpha1.Build
+		for _, update := range actions.Updates {
+			require.Equal(t, "status", update.GetSubresource())
+			builds = append(builds, update.GetObject().(*v1alpha1.Build))
+		}
+		return builds
+	}
+
+	deletedPods := func() []string {
+		actions, err := testhelpers.ActionRecorderList{k8sClient}.ActionsByVerb()
+		require.NoError(t, err)
+
+		var pods []string
+		for _, del := range actions.Deletes {
+			pods = append(pods, del.GetNamespace()+"/"+del.GetName())
+		}
+		return pods
+	}
+
+	it.Before(func() {
+		confirmationProvider = commandsfakes.NewFakeConfirmationProvider(true, nil)
+	})
+
+	when("cancelling a single build", func() {
+		it("marks the latest build as cancelled and deletes its pod", func() {
+			builds := testhelpers.MakeTestBuilds(image, defaultNamespace)
+			kpackClient = kpackfakes.NewSimpleClientset(builds...)
+			k8sClient = k8sfakes.NewSimpleClientset(makePod(defaultNamespace, "pod-three"))
+
+			out, err := run(image)
+			require.NoError(t, err)
+			require.Equal(t, "Build \"3\" of Image \"test-image\" cancelled, pod \"pod-three\" deleted\n", out)
+
+			cancelled := cancelledBuilds()
+			require.Len(t, cancelled, 1)
+			require.Equal(t, "build-three", cancelled[0].Name)
+
+			cond := cancelled[0].Status.GetCondition(corev1alpha1.ConditionSucceeded)
+			require.True(t, cond.IsFalse())
+			require.Equal(t, buildpkg.CancelledReason, cond.Reason)
+			require.False(t, cond.LastTransitionTime.Inner.IsZero())
+
+			require.Equal(t, []string{defaultNamespace + "/pod-three"}, deletedPods())
+		})
+
+		it("reports when the pod was already removed", func() {
+			kpackClient = kpackfakes.NewSimpleClientset(makeRunningBuild(defaultNamespace, image, "1", nil))
+			k8sClient = k8sfakes.NewSimpleClientset()
+
+			out, err := run(image, "-b", "1")
+			require.NoError(t, err)
+			require.Equal(t, "Build \"1\" of Image \"test-image\" cancelled, pod \"test-image-build-1-pod\" was already removed\n", out)
+			require.Len(t, cancelledBuilds(), 1)
+		})
+
+		it("returns an error when the build is not running", func() {
+			kpackClient = kpackfakes.NewSimpleClientset(testhelpers.MakeTestBuilds(image, defaultNamespace)...)
+			k8sClient = k8sfakes.NewSimpleClientset()
+
+			_, err := run(image, "-b", "1")
+			require.EqualError(t, err, "build \"1\" of Image \"test-image\" is not running")
+			require.Len(t, cancelledBuilds(), 0)
+			require.Len(t, deletedPods(), 0)
+		})
+
+		it("returns an error when a selector is provided without --all-running", func() {
+			kpackClient = kpackfakes.NewSimpleClientset()
+			k8sClient = k8sfakes.NewSimpleClientset()
+
+			_, err := run(image, "-l", "team=a")
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "--selector and --all-namespaces can only be used with --all-running")
+		})
+	})
+
+	when("cancelling all running builds", func() {
+		var objects []runtime.Object
+
+		it.Before(func() {
+			finished := testhelpers.MakeTestBuilds("other-image", defaultNamespace)
+			objects = append(finished,
+				makeRunningBuild(defaultNamespace, "image-a", "2", map[string]string{"team": "a"}),
+				makeRunningBuild(defaultNamespace, "image-b", "5", map[string]string{"team": "b"}),
+				makeRunningBuild("other-namespace", "image-c", "1", map[string]string{"team": "a"}),
+			)
+			kpackClient = kpackfakes.NewSimpleClientset(objects...)
+			k8sClient = k8sfakes.NewSimpleClientset(
+				makePod(defaultNamespace, "image-a-build-2-pod"),
+				makePod(defaultNamespace, "image-b-build-5-pod"),
+				makePod("other-namespace", "image-c-build-1-pod"),
+			)
+		})
+
+		it("cancels the selected running builds after confirmation", func() {
+			out, err := run("--all-running", "-l", "team=a", "-A")
+			require.NoError(t, err)
+			require.Equal(t, `The following running builds were selected for cancel:
+NAMESPACE                 IMAGE      BUILD    POD NAME
+other-namespace           image-c    1        image-c-build-1-pod
+some-default-namespace    image-a    2        image-a-build-2-pod
+
+NAMESPACE                 IMAGE      BUILD    RESULT
+other-namespace           image-c    1        cancelled, pod "image-c-build-1-pod" deleted
+some-default-namespace    image-a    2        cancelled, pod "image-a-build-2-pod" deleted
+
+`, out)
+
+			require.NoError(t, confirmationProvider.WasRequestedWithMsg("Please confirm cancel of 2 build(s) by typing 'y': "))
+			require.Len(t, cancelledBuilds(), 2)
+			require.Equal(t, []string{"other-namespace/image-c-build-1-pod", defaultNamespace + "/image-a-build-2-pod"}, deletedPods())
+		})
+
+		it("cancels the running builds of an image in the namespace without confirmation when forced", func() {
+			out, err := run("image-b", "--all-running", "--force")
+			require.NoError(t, err)
+			require.Equal(t, `NAMESPACE                 IMAGE      BUILD    RESULT
+some-default-namespace    image-b    5        cancelled, pod "image-b-build-5-pod" deleted
+
+`, out)
+
+			require.False(t, confirmationProvider.WasRequested())
+			require.Equal(t, []string{defaultNamespace + "/image-b-build-5-pod"}, deletedPods())
+		})
+
+		it("does not cancel builds when the confirmation is declined", func() {
+			confirmationProvider = commandsfakes.NewFakeConfirmationProvider(false, nil)
+
+			out, err := run("--all-running")
+			require.NoError(t, err)
+			require.Contains(t, out, "Skipping build cancel\n")
+			require.Len(t, cancelledBuilds(), 0)
+			require.Len(t, deletedPods(), 0)
+		})
+
+		it("prints a message when no builds are running", func() {
+			out, err := run("--all-running", "-l", "team=c")
+			require.NoError(t, err)
+			require.Equal(t, "No running builds found\n", out)
+		})
+	})
+}
@@ -0,0 +1,290 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+func NewDiagnoseCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider, newLogsClient func(k8s.ClientSet) LogsClient) *cobra.Command {
+	var (
+		namespace   string
+		buildNumber string
+		lines       int
+		tlsCfg      registry.TLSConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diagnose <image-name>",
+		Short: "Diagnose a failed image build",
+		Long: `Finds the lifecycle step a build failed in, prints the last lines of its logs and suggests how to fix common failures.
+
+The recognized failures are: no buildpack detected, registry authentication failure, out of memory,
+git clone failure and stack mismatch.
+
+Logs are read from the build pod, or from the log archive created with "kp build archive-logs" once the pod has been removed.
+
+The build defaults to the latest build number.
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build diagnose my-image\nkp build diagnose my-image -b 2 -n my-namespace --lines 50",
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if lines < 1 {
+				return errors.New("lines must be greater than 0")
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			bld, err := getBuild(cs, args[0], buildNumber)
+			if err != nil {
+				return err
+			}
+
+			number := bld.Labels[v1alpha1.BuildNumberLabel]
+			switch build.Status(bld) {
+			case "SUCCESS":
+				_, err := fmt.Fprintf(cmd.OutOrStdout(), "Build %q succeeded, there is nothing to diagnose\n", number)
+				return err
+			case "BUILDING":
+				_, err := fmt.Fprintf(cmd.OutOrStdout(), "Build %q is still running, follow it with \"kp build logs %s -b %s\"\n", number, args[0], number)
+				return err
+			}
+
+			img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(args[0], metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				img = nil
+			} else if err != nil {
+				return err
+			}
+
+			d := diagnosis{image: args[0], img: img, build: &bld}
+			d.step, d.stepFound = build.FailedStep(&bld)
+
+			opts := build.LogOptions{}
+			if d.stepFound && build.ValidateStep(d.step.Step) == nil {
+				opts.Step = d.step.Step
+			}
+
+			logs := &bytes.Buffer{}
+			err = newLogsClient(cs).Logs(context.Background(), logs, &bld, opts)
+			if errors.Cause(err) == build.ErrBuildPodNotFound {
+				var tag string
+				if tag, err = build.LogArchiveTag(&bld); err == nil {
+					var archived []byte
+					archived, err = readArchivedLogs(rup.Fetcher(), tag, opts, tlsCfg)
+					logs = bytes.NewBuffer(archived)
+				}
+			}
+			if err != nil {
+				d.logsErr = err
+			}
+
+			d.failure = build.Classify(&bld, d.step, logs.Bytes())
+			d.lines = build.LastLogLines(logs.Bytes(), lines)
+
+			return d.write(cmd.OutOrStdout())
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().IntVar(&lines, "lines", 20, "number of log lines to show")
+	commands.SetTLSFlags(cmd, &tlsCfg)
+
+	return cmd
+}
+
+type diagnosis struct {
+	image     string
+	img       *v1alpha1.Image
+	build     *v1alpha1.Build
+	step      build.StepFailure
+	stepFound bool
+	failure   build.Failure
+	lines     []string
+	logsErr   error
+}
+
+func (d diagnosis) write(out io.Writer) error {
+	statusWriter := commands.NewStatusWriter(out)
+
+	cond := d.build.Status.GetCondition(corev1alpha1.ConditionSucceeded)
+	var reason, message string
+	if cond != nil {
+		reason, message = cond.Reason, cond.Message
+	}
+
+	failedStep := "unknown"
+	if d.stepFound {
+		failedStep = fmt.Sprintf("%s (exit code %d)", d.step.Step, d.step.ExitCode)
+		if d.step.Reason != "" && d.step.Reason != "Error" {
+			failedStep = fmt.Sprintf("%s (%s, exit code %d)", d.step.Step, d.step.Reason, d.step.ExitCode)
+		}
+	}
+
+	err := statusWriter.AddBlock("",
+		"Build", d.build.Labels[v1alpha1.BuildNumberLabel],
+		"Status", build.Status(*d.build),
+		"Status Reason", reason,
+		"Status Message", message,
+		"Failed Step", failedStep,
+		"Failure", d.failure.Summary(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := statusWriter.Write(); err != nil {
+		return err
+	}
+
+	if d.logsErr != nil {
+		if _, err := fmt.Fprintf(out, "Logs are not available: %s\n\n", d.logsErr); err != nil {
+			return err
+		}
+	} else if len(d.lines) > 0 {
+		if _, err := fmt.Fprintln(out, "Last Log Lines:"); err != nil {
+			return err
+		}
+
+		for _, line := range d.lines {
+			if _, err := fmt.Fprintf(out, "  %s\n", line); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintln(out, ""); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintln(out, "Remediation:"); err != nil {
+		return err
+	}
+
+	for _, r := range d.remediation() {
+		if _, err := fmt.Fprintf(out, "  - %s\n", r); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(out, "")
+	return err
+}
+
+func (d diagnosis) remediation() []string {
+	var r []string
+
+	switch d.failure {
+	case build.FailureNoBuildpack:
+		r = append(r,
+			fmt.Sprintf("Check which buildpack group matches the source with \"kp image detect --local-path <path> %s\"", d.builderFlag()),
+			fmt.Sprintf("If the app is in a sub directory of the source, set it with \"kp image patch %s --sub-path <path>\"", d.image),
+		)
+	case build.FailureRegistryAuth:
+		r = append(r,
+			fmt.Sprintf("Create a secret for the registry with \"kp secret create <secret-name> --registry %s --registry-user <user>\"", d.registry()),
+			fmt.Sprintf("Make sure the secret is attached to service account '%s'", d.serviceAccount()),
+		)
+	case build.FailureOutOfMemory:
+		r = append(r,
+			fmt.Sprintf("Increase the memory limit of the build with \"kubectl patch image %s -n %s --type merge -p '{\"spec\":{\"build\":{\"resources\":{\"limits\":{\"memory\":\"<limit>\"}}}}}'\"", d.image, d.build.Namespace),
+		)
+	case build.FailureGitClone:
+		git := d.build.Spec.Source.Git
+		r = append(r,
+			fmt.Sprintf("Check the repository url '%s' and revision '%s', update them with \"kp image patch %s --git <url> --git-revision <revision>\"", git.URL, git.Revision, d.image),
+			fmt.Sprintf("If the repository is private, create a secret with \"kp secret create <secret-name> %s\"", gitSecretFlags(git.URL)),
+		)
+	case build.FailureStackMismatch:
+		r = append(r,
+			fmt.Sprintf("Check the stack and buildpacks of the builder with \"%s\"", d.builderStatusCommand()),
+			"Use a builder with a stack supported by the buildpacks, see \"kp clusterstack list\"",
+		)
+	}
+
+	logsCommand := fmt.Sprintf("kp build logs %s -b %s", d.image, d.build.Labels[v1alpha1.BuildNumberLabel])
+	if d.stepFound && build.ValidateStep(d.step.Step) == nil {
+		logsCommand += " --step " + d.step.Step
+	}
+	return append(r, fmt.Sprintf("See the full logs with \"%s\"", logsCommand))
+}
+
+func (d diagnosis) builderFlag() string {
+	if d.img == nil || d.img.Spec.Builder.Name == "" {
+		return "--cluster-builder <builder>"
+	}
+
+	if d.img.Spec.Builder.Kind == v1alpha1.BuilderKind {
+		return "--builder " + d.img.Spec.Builder.Name
+	}
+	return "--cluster-builder " + d.img.Spec.Builder.Name
+}
+
+func (d diagnosis) builderStatusCommand() string {
+	if d.img == nil || d.img.Spec.Builder.Name == "" {
+		return "kp clusterbuilder status <builder>"
+	}
+
+	if d.img.Spec.Builder.Kind == v1alpha1.BuilderKind {
+		return "kp builder status " + d.img.Spec.Builder.Name
+	}
+	return "kp clusterbuilder status " + d.img.Spec.Builder.Name
+}
+
+func (d diagnosis) registry() string {
+	if len(d.build.Spec.Tags) == 0 {
+		return "<registry>"
+	}
+
+	tag, err := name.NewTag(d.build.Spec.Tags[0], name.WeakValidation)
+	if err != nil {
+		return "<registry>"
+	}
+	return tag.RegistryStr()
+}
+
+func (d diagnosis) serviceAccount() string {
+	if d.build.Spec.ServiceAccount == "" {
+		return "default"
+	}
+	return d.build.Spec.ServiceAccount
+}
+
+// gitSecretFlags returns the "kp secret create" flags for the host of a git
+// repository url.
+func gitSecretFlags(repo string) string {
+	if strings.HasPrefix(repo, "git@") {
+		if idx := strings.Index(repo, ":"); idx != -1 {
+			repo = repo[:idx]
+		}
+		return fmt.Sprintf("--git-url %s --git-ssh-key <path>", repo)
+	}
+
+	if u, err := url.Parse(repo); err == nil && u.Host != "" {
+		repo = fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+	}
+	return fmt.Sprintf("--git-url %s --git-user <user>", repo)
+}
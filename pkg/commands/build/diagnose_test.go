@@ -0,0 +1,235 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	buildpkg "github.com/pivotal/build-service-cli/pkg/build"
+	buildfakes "github.com/pivotal/build-service-cli/pkg/build/fakes"
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	registryfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildDiagnoseCommand(t *testing.T) {
+	spec.Run(t, "TestBuildDiagnoseCommand", testBuildDiagnoseCommand)
+}
+
+func testBuildDiagnoseCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+	)
+
+	var (
+		fakeLogsClient *buildfakes.FakeLogsClient
+		fakeFetcher    *registryfakes.Fetcher
+	)
+
+	newLogsClient := func(k8s.ClientSet) build.LogsClient {
+		return fakeLogsClient
+	}
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		rup := registryfakes.UtilProvider{FakeFetcher: fakeFetcher}
+		return build.NewDiagnoseCommand(clientSetProvider, rup, newLogsClient)
+	}
+
+	img := &v1alpha1.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      image,
+			Namespace: defaultNamespace,
+		},
+		Spec: v1alpha1.ImageSpec{
+			Builder: corev1.ObjectReference{Kind: v1alpha1.ClusterBuilderKind, Name: "some-cluster-builder"},
+		},
+	}
+
+	makeFailedBuild := func(failedStep string, exitCode int32, reason string) *v1alpha1.Build {
+		bld := &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "build-five",
+				Namespace: defaultNamespace,
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       image,
+					v1alpha1.BuildNumberLabel: "5",
+				},
+			},
+			Spec: v1alpha1.BuildSpec{
+				Tags:           []string{"some-registry.io/some-repo"},
+				ServiceAccount: "some-sa",
+			},
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{{
+						Type:    corev1alpha1.ConditionSucceeded,
+						Status:  corev1.ConditionFalse,
+						Reason:  "BuildFailed",
+						Message: "some message",
+					}},
+				},
+				PodName: "pod-five",
+			},
+		}
+
+		for _, step := range buildpkg.Steps {
+			code, r := int32(0), "Completed"
+			if step == failedStep {
+				code, r = exitCode, reason
+			}
+			bld.Status.StepStates = append(bld.Status.StepStates, corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{ExitCode: code, Reason: r},
+			})
+			bld.Status.StepsCompleted = append(bld.Status.StepsCompleted, step)
+			if step == failedStep {
+				break
+			}
+		}
+		return bld
+	}
+
+	it.Before(func() {
+		fakeLogsClient = &buildfakes.FakeLogsClient{}
+		fakeFetcher = &registryfakes.Fetcher{}
+	})
+
+	it("diagnoses a detect failure", func() {
+		fakeLogsClient.Output = "===> DETECT\nline 1\nline 2\nERROR: No buildpack groups passed detection.\n"
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{img, makeFailedBuild("detect", 100, "Error")},
+			Args:    []string{image, "--lines", "2"},
+			ExpectedOutput: `Build:             5
+Status:            FAILURE
+Status Reason:     BuildFailed
+Status Message:    some message
+Failed Step:       detect (exit code 100)
+Failure:           No buildpack group passed detection
+
+Last Log Lines:
+  line 2
+  ERROR: No buildpack groups passed detection.
+
+Remediation:
+  - Check which buildpack group matches the source with "kp image detect --local-path <path> --cluster-builder some-cluster-builder"
+  - If the app is in a sub directory of the source, set it with "kp image patch test-image --sub-path <path>"
+  - See the full logs with "kp build logs test-image -b 5 --step detect"
+
+`,
+		}.TestKpack(t, cmdFunc)
+
+		require.Len(t, fakeLogsClient.Calls, 1)
+		require.Equal(t, buildpkg.LogOptions{Step: "detect"}, fakeLogsClient.Calls[0].Options)
+	})
+
+	it("reads the logs from the log archive when the build pod has been removed", func() {
+		bld := makeFailedBuild("export", 1, "Error")
+		archive, err := buildpkg.NewLogArchive(bld, []byte("===> EXPORT\n2020-10-01T10:00:00Z ERROR: failed to export: UNAUTHORIZED: authentication required\n"))
+		require.NoError(t, err)
+		fakeFetcher.AddImage("some-registry.io/some-repo:build-five.logs", archive)
+		fakeLogsClient.Err = buildpkg.ErrBuildPodNotFound
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{bld},
+			Args:    []string{image},
+			ExpectedOutput: `Build:             5
+Status:            FAILURE
+Status Reason:     BuildFailed
+Status Message:    some message
+Failed Step:       export (exit code 1)
+Failure:           The registry rejected the credentials of the build
+
+Last Log Lines:
+  ERROR: failed to export: UNAUTHORIZED: authentication required
+
+Remediation:
+  - Create a secret for the registry with "kp secret create <secret-name> --registry some-registry.io --registry-user <user>"
+  - Make sure the secret is attached to service account 'some-sa'
+  - See the full logs with "kp build logs test-image -b 5 --step export"
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("diagnoses an out of memory failure without logs", func() {
+		fakeLogsClient.Err = buildpkg.ErrBuildPodNotFound
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{img, makeFailedBuild("build", 137, "OOMKilled")},
+			Args:    []string{image},
+			ExpectedOutput: `Build:             5
+Status:            FAILURE
+Status Reason:     BuildFailed
+Status Message:    some message
+Failed Step:       build (OOMKilled, exit code 137)
+Failure:           The build ran out of memory
+
+Logs are not available: unable to read log archive: image not found: "some-registry.io/some-repo:build-five.logs"
+
+Remediation:
+  - Increase the memory limit of the build with "kubectl patch image test-image -n some-default-namespace --type merge -p '{"spec":{"build":{"resources":{"limits":{"memory":"<limit>"}}}}}'"
+  - See the full logs with "kp build logs test-image -b 5 --step build"
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("diagnoses a git clone failure", func() {
+		bld := makeFailedBuild("prepare", 1, "Error")
+		bld.Spec.Source.Git = &v1alpha1.Git{URL: "git@github.com:some/repo.git", Revision: "main"}
+		fakeLogsClient.Output = "===> PREPARE\nError fetching git repository: ssh: handshake failed\n"
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{img, bld},
+			Args:    []string{image},
+			ExpectedOutput: `Build:             5
+Status:            FAILURE
+Status Reason:     BuildFailed
+Status Message:    some message
+Failed Step:       prepare (exit code 1)
+Failure:           The git source could not be fetched
+
+Last Log Lines:
+  Error fetching git repository: ssh: handshake failed
+
+Remediation:
+  - Check the repository url 'git@github.com:some/repo.git' and revision 'main', update them with "kp image patch test-image --git <url> --git-revision <revision>"
+  - If the repository is private, create a secret with "kp secret create <secret-name> --git-url git@github.com --git-ssh-key <path>"
+  - See the full logs with "kp build logs test-image -b 5 --step prepare"
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("does not diagnose successful builds", func() {
+		testhelpers.CommandTest{
+			Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:           []string{image, "-b", "1"},
+			ExpectedOutput: "Build \"1\" succeeded, there is nothing to diagnose\n",
+		}.TestKpack(t, cmdFunc)
+
+		require.Len(t, fakeLogsClient.Calls, 0)
+	})
+
+	it("does not diagnose running builds", func() {
+		testhelpers.CommandTest{
+			Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:           []string{image},
+			ExpectedOutput: "Build \"3\" is still running, follow it with \"kp build logs test-image -b 3\"\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
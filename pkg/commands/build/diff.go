@@ -0,0 +1,194 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+type Differ interface {
+	Diff(dOld, dNew interface{}) (string, error)
+}
+
+// buildInputs are the inputs of a build that may explain why two builds of
+// an image behave differently.
+type buildInputs struct {
+	Build      string                 `json:"build"`
+	Revision   string                 `json:"revision,omitempty"`
+	SubPath    string                 `json:"subPath,omitempty"`
+	Builder    string                 `json:"builder"`
+	RunImage   string                 `json:"runImage"`
+	StackId    string                 `json:"stackId"`
+	Env        []buildStatusEnv       `json:"env,omitempty"`
+	Buildpacks []buildStatusBuildpack `json:"buildpacks,omitempty"`
+}
+
+type buildDiff struct {
+	Image   string      `json:"image"`
+	From    buildInputs `json:"from"`
+	To      buildInputs `json:"to"`
+	Changed []string    `json:"changed"`
+}
+
+func NewDiffCommand(clientSetProvider k8s.ClientSetProvider, differ Differ) *cobra.Command {
+	var (
+		namespace string
+		output    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <image-name> <build-number> <other-build-number>",
+		Short: "Compare the inputs of two image builds",
+		Long: `Prints the differences between the inputs of two builds of an image in the provided namespace.
+
+The compared inputs are the source revision (git commit, blob url or source image), sub path, builder image, run image, stack id, env vars and
+buildpack versions. Env var values are masked, a changed value is marked as changed.
+
+The "-o json" flag prints the inputs of both builds and the names of the changed inputs.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build diff my-image 12 13\nkp build diff my-image 12 13 -n my-namespace -o json",
+		Args:         commands.ExactArgsWithUsage(3),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "" && output != k8s.FormatJSON {
+				return errors.Errorf("unsupported output format: %q, supported formats are json", output)
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			builds, err := listBuilds(cs, args[0])
+			if err != nil {
+				return err
+			}
+
+			from, err := findBuild(builds, args[1])
+			if err != nil {
+				return err
+			}
+
+			to, err := findBuild(builds, args[2])
+			if err != nil {
+				return err
+			}
+
+			d := newBuildDiff(args[0], from, to)
+			if output == k8s.FormatJSON {
+				return printBuildDiff(cmd.OutOrStdout(), d)
+			}
+
+			if len(d.Changed) == 0 {
+				_, err = fmt.Fprintf(cmd.OutOrStdout(), "Builds %q and %q of Image %q have the same inputs\n", d.From.Build, d.To.Build, d.Image)
+				return err
+			}
+
+			diff, err := differ.Diff(d.From, d.To)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprint(cmd.OutOrStdout(), diff)
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output format, supported formats are: json")
+
+	return cmd
+}
+
+func newBuildDiff(image string, from, to v1alpha1.Build) buildDiff {
+	d := buildDiff{
+		Image:   image,
+		From:    newBuildInputs(from),
+		To:      newBuildInputs(to),
+		Changed: []string{},
+	}
+	d.To.Env = newChangedEnv(from.Spec.Env, to.Spec.Env)
+
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"revision", d.From.Revision, d.To.Revision},
+		{"subPath", d.From.SubPath, d.To.SubPath},
+		{"builder", d.From.Builder, d.To.Builder},
+		{"runImage", d.From.RunImage, d.To.RunImage},
+		{"stackId", d.From.StackId, d.To.StackId},
+		{"env", d.From.Env, d.To.Env},
+		{"buildpacks", d.From.Buildpacks, d.To.Buildpacks},
+	}
+	for _, f := range fields {
+		if !reflect.DeepEqual(f.from, f.to) {
+			d.Changed = append(d.Changed, f.name)
+		}
+	}
+
+	return d
+}
+
+func newBuildInputs(bld v1alpha1.Build) buildInputs {
+	status := newBuildStatus(bld)
+	return buildInputs{
+		Build:      status.Build,
+		Revision:   sourceRevision(bld.Spec.Source),
+		SubPath:    bld.Spec.Source.SubPath,
+		Builder:    status.Builder,
+		RunImage:   status.RunImage,
+		StackId:    bld.Status.Stack.ID,
+		Env:        status.Env,
+		Buildpacks: status.Buildpacks,
+	}
+}
+
+// newChangedEnv masks the values of env vars. Masked values are marked when
+// they changed, so the change shows up in a diff.
+func newChangedEnv(previous, current []corev1.EnvVar) []buildStatusEnv {
+	previousValues := map[string]string{}
+	for _, env := range previous {
+		previousValues[env.Name] = envValue(env)
+	}
+
+	var result []buildStatusEnv
+	for _, env := range current {
+		value := maskEnvValue(env)
+		if old, ok := previousValues[env.Name]; ok && old != envValue(env) {
+			value += " (changed)"
+		}
+		result = append(result, buildStatusEnv{Name: env.Name, Value: value})
+	}
+	return result
+}
+
+// envValue returns a comparable representation of the value of an env var.
+func envValue(env corev1.EnvVar) string {
+	if env.ValueFrom != nil {
+		return env.ValueFrom.String()
+	}
+	return env.Value
+}
+
+func printBuildDiff(out io.Writer, d buildDiff) error {
+	buf, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, string(buf))
+	return err
+}
@@ -0,0 +1,261 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	commandsfakes "github.com/pivotal/build-service-cli/pkg/commands/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildDiffCommand(t *testing.T) {
+	spec.Run(t, "TestBuildDiffCommand", testBuildDiffCommand)
+}
+
+func testBuildDiffCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+	)
+
+	var fakeDiffer *commandsfakes.FakeDiffer
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return build.NewDiffCommand(clientSetProvider, fakeDiffer)
+	}
+
+	makeBuild := func(number string, created time.Duration) *v1alpha1.Build {
+		return &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "build-" + number,
+				Namespace:         defaultNamespace,
+				CreationTimestamp: metav1.Time{Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Add(created)},
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       image,
+					v1alpha1.BuildNumberLabel: number,
+				},
+			},
+			Spec: v1alpha1.BuildSpec{
+				Builder: v1alpha1.BuildBuilderSpec{Image: "some-repo.com/builder@sha256:123"},
+				Source: v1alpha1.SourceConfig{
+					Git: &v1alpha1.Git{URL: "https://github.com/some-org/some-repo", Revision: "sha-one"},
+				},
+				Env: []corev1.EnvVar{
+					{Name: "BP_JAVA_VERSION", Value: "11"},
+					{Name: "BP_DEBUG", Value: "true"},
+				},
+			},
+			Status: v1alpha1.BuildStatus{
+				Stack: v1alpha1.BuildStack{RunImage: "some-repo.com/run@sha256:456", ID: "io.buildpacks.stacks.bionic"},
+				BuildMetadata: v1alpha1.BuildpackMetadataList{
+					{Id: "bp-id-1", Version: "1.0.0"},
+					{Id: "bp-id-2", Version: "2.0.0"},
+				},
+			},
+		}
+	}
+
+	build1 := makeBuild("1", 0)
+
+	build2 := makeBuild("2", time.Hour)
+	build2.Spec.Source.Git.Revision = "sha-two"
+	build2.Spec.Env[0].Value = "14"
+	build2.Status.BuildMetadata[1].Version = "2.1.0"
+
+	it.Before(func() {
+		fakeDiffer = &commandsfakes.FakeDiffer{DiffResult: "some-diff\n"}
+	})
+
+	it("prints the differences between the inputs of the builds", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{build1, build2},
+			Args:           []string{image, "1", "2"},
+			ExpectedOutput: "some-diff\n",
+		}.TestKpack(t, cmdFunc)
+
+		oldArg, newArg := fakeDiffer.Args()
+
+		oldData, err := yaml.Marshal(oldArg)
+		require.NoError(t, err)
+		require.Equal(t, `build: "1"
+builder: some-repo.com/builder@sha256:123
+buildpacks:
+- id: bp-id-1
+  version: 1.0.0
+- id: bp-id-2
+  version: 2.0.0
+env:
+- name: BP_JAVA_VERSION
+  value: '********'
+- name: BP_DEBUG
+  value: '********'
+revision: sha-one
+runImage: some-repo.com/run@sha256:456
+stackId: io.buildpacks.stacks.bionic
+`, string(oldData))
+
+		newData, err := yaml.Marshal(newArg)
+		require.NoError(t, err)
+		require.Equal(t, `build: "2"
+builder: some-repo.com/builder@sha256:123
+buildpacks:
+- id: bp-id-1
+  version: 1.0.0
+- id: bp-id-2
+  version: 2.1.0
+env:
+- name: BP_JAVA_VERSION
+  value: '******** (changed)'
+- name: BP_DEBUG
+  value: '********'
+revision: sha-two
+runImage: some-repo.com/run@sha256:456
+stackId: io.buildpacks.stacks.bionic
+`, string(newData))
+	})
+
+	it("prints a message when the inputs of the builds are the same", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{build1, makeBuild("2", time.Hour)},
+			Args:           []string{image, "1", "2"},
+			ExpectedOutput: "Builds \"1\" and \"2\" of Image \"test-image\" have the same inputs\n",
+		}.TestKpack(t, cmdFunc)
+
+		oldArg, _ := fakeDiffer.Args()
+		require.Nil(t, oldArg)
+	})
+
+	it("prints the inputs and changes as json", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{build1, build2},
+			Args:    []string{image, "1", "2", "-o", "json"},
+			ExpectedOutput: `{
+  "image": "test-image",
+  "from": {
+    "build": "1",
+    "revision": "sha-one",
+    "builder": "some-repo.com/builder@sha256:123",
+    "runImage": "some-repo.com/run@sha256:456",
+    "stackId": "io.buildpacks.stacks.bionic",
+    "env": [
+      {
+        "name": "BP_JAVA_VERSION",
+        "value": "********"
+      },
+      {
+        "name": "BP_DEBUG",
+        "value": "********"
+      }
+    ],
+    "buildpacks": [
+      {
+        "id": "bp-id-1",
+        "version": "1.0.0"
+      },
+      {
+        "id": "bp-id-2",
+        "version": "2.0.0"
+      }
+    ]
+  },
+  "to": {
+    "build": "2",
+    "revision": "sha-two",
+    "builder": "some-repo.com/builder@sha256:123",
+    "runImage": "some-repo.com/run@sha256:456",
+    "stackId": "io.buildpacks.stacks.bionic",
+    "env": [
+      {
+        "name": "BP_JAVA_VERSION",
+        "value": "******** (changed)"
+      },
+      {
+        "name": "BP_DEBUG",
+        "value": "********"
+      }
+    ],
+    "buildpacks": [
+      {
+        "id": "bp-id-1",
+        "version": "1.0.0"
+      },
+      {
+        "id": "bp-id-2",
+        "version": "2.1.0"
+      }
+    ]
+  },
+  "changed": [
+    "revision",
+    "env",
+    "buildpacks"
+  ]
+}
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("reports blob url and sub path changes and lists the builds once", func() {
+		blobBuild1 := makeBuild("1", 0)
+		blobBuild1.Spec.Source = v1alpha1.SourceConfig{Blob: &v1alpha1.Blob{URL: "https://some-blob-host.com/source-1.tgz"}}
+
+		blobBuild2 := makeBuild("2", time.Hour)
+		blobBuild2.Spec.Source = v1alpha1.SourceConfig{Blob: &v1alpha1.Blob{URL: "https://some-blob-host.com/source-2.tgz"}, SubPath: "some-path"}
+
+		clientSet := fake.NewSimpleClientset(blobBuild1, blobBuild2)
+		cmd := cmdFunc(clientSet)
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{image, "1", "2", "-o", "json"})
+		require.NoError(t, cmd.Execute())
+
+		var d struct {
+			Changed []string `json:"changed"`
+		}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &d))
+		require.Equal(t, []string{"revision", "subPath"}, d.Changed)
+
+		lists := 0
+		for _, action := range clientSet.Actions() {
+			if action.GetVerb() == "list" {
+				lists++
+			}
+		}
+		require.Equal(t, 1, lists)
+	})
+
+	it("returns an error when a build does not exist", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{build1, build2},
+			Args:           []string{image, "1", "3"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: build \"3\" not found\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error for an unsupported output format", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{build1, build2},
+			Args:           []string{image, "1", "2", "-o", "yaml"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: unsupported output format: \"yaml\", supported formats are json\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
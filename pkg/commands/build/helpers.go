@@ -4,25 +4,19 @@
 package build
 
 import (
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
-)
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-func getStatus(b v1alpha1.Build) string {
-	cond := b.Status.GetCondition(corev1alpha1.ConditionSucceeded)
-	switch {
-	case cond.IsTrue():
-		return "SUCCESS"
-	case cond.IsFalse():
-		return "FAILURE"
-	case cond.IsUnknown():
-		return "BUILDING"
-	default:
-		return "UNKNOWN"
-	}
-}
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
 
 func getStarted(b v1alpha1.Build) string {
 	return b.CreationTimestamp.Time.Format("2006-01-02 15:04:05")
@@ -35,45 +29,62 @@ func getFinished(b v1alpha1.Build) string {
 	return b.Status.GetCondition(corev1alpha1.ConditionSucceeded).LastTransitionTime.Inner.Format("2006-01-02 15:04:05")
 }
 
-func getTruncatedReason(b v1alpha1.Build) string {
-	r := getReasons(b)
+// getBuild returns the build of an image with the given build number,
+// or the latest build when no build number is provided.
+func getBuild(cs k8s.ClientSet, image, buildNumber string) (v1alpha1.Build, error) {
+	builds, err := listBuilds(cs, image)
+	if err != nil {
+		return v1alpha1.Build{}, err
+	}
+
+	return findBuild(builds, buildNumber)
+}
 
-	if len(r) == 0 {
-		return "UNKNOWN"
+// listBuilds returns the builds of an image, oldest first.
+func listBuilds(cs k8s.ClientSet, image string) ([]v1alpha1.Build, error) {
+	buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
+		LabelSelector: v1alpha1.ImageLabel + "=" + image,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if len(r) == 1 {
-		return r[0]
+	if len(buildList.Items) == 0 {
+		return nil, errors.New("no builds found")
 	}
 
-	return mostImportantReason(r) + "+"
+	sort.Slice(buildList.Items, build.Sort(buildList.Items))
+	return buildList.Items, nil
 }
 
-func getReasons(b v1alpha1.Build) []string {
-	s := strings.Split(b.Annotations[v1alpha1.BuildReasonAnnotation], ",")
-	if len(s) == 1 && s[0] == "" {
-		return nil
+func getDuration(b v1alpha1.Build) string {
+	d, ok := build.Duration(b)
+	if !ok {
+		return ""
 	}
-	return s
+	return d.Round(time.Second).String()
 }
 
-func mostImportantReason(r []string) string {
-	if contains(r, "CONFIG") {
-		return "CONFIG"
-	} else if contains(r, "COMMIT") {
-		return "COMMIT"
-	} else if contains(r, "BUILDPACK") {
-		return "BUILDPACK"
+// parseSince parses a duration that may be expressed in days.
+func parseSince(since string) (time.Duration, error) {
+	if since == "" {
+		return 0, nil
 	}
 
-	return r[0]
-}
+	var (
+		d   time.Duration
+		err error
+	)
+	if strings.HasSuffix(since, "d") {
+		var days int
+		days, err = strconv.Atoi(strings.TrimSuffix(since, "d"))
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(since)
+	}
 
-func contains(reasons []string, value string) bool {
-	for _, v := range reasons {
-		if v == value {
-			return true
-		}
+	if err != nil || d <= 0 {
+		return 0, errors.Errorf("invalid since %q, must be a positive duration (e.g. 7d or 24h)", since)
 	}
-	return false
+	return d, nil
 }
@@ -4,32 +4,62 @@
 package build
 
 import (
+	"io"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/pivotal/build-service-cli/pkg/build"
 	"github.com/pivotal/build-service-cli/pkg/commands"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 )
 
+type listOptions struct {
+	image         string
+	allNamespaces bool
+	status        string
+	reason        string
+	since         string
+	builder       string
+	limit         int
+	output        string
+	watch         bool
+}
+
 func NewListCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 	var (
 		namespace string
+		opts      listOptions
 	)
 
 	cmd := &cobra.Command{
-		Use:   "list <image-name>",
-		Short: "List builds for an image",
-		Long: `Prints a table of the most important information about builds for an image in the provided namespace.
+		Use:   "list [image-name]",
+		Short: "List builds",
+		Long: `Prints a table of the most important information about builds in the provided namespace.
+
+When an image name is provided, only the builds of that image are listed.
+The namespace defaults to the kubernetes current-context namespace.
 
-The namespace defaults to the kubernetes current-context namespace.`,
+Builds may be filtered by status (success, failure or building), by build reason (CONFIG, COMMIT,
+BUILDPACK, STACK or TRIGGER), by the time since they started and by the builder of their image.
+The "--since" duration may be expressed in days, e.g. "7d".
+The "--limit" flag only lists the most recent builds.
 
-		Example:      "kp build list my-image\nkp build list my-image -n my-namespace",
-		Args:         commands.ExactArgsWithUsage(1),
+The "--watch" flag keeps the command running and updates the table as builds change.
+The table is redrawn in place on a terminal. With "-o json", one json event is printed per change.`,
+
+		Example: `kp build list my-image
+kp build list my-image -n my-namespace
+kp build list --all-namespaces --status failure --since 7d
+kp build list --reason STACK --builder my-builder --limit 10
+kp build list my-image --watch`,
+		Args:         commands.MaxArgsWithUsage(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cs, err := clientSetProvider.GetClientSet(namespace)
@@ -37,39 +67,238 @@ The namespace defaults to the kubernetes current-context namespace.`,
 				return err
 			}
 
-			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
-				LabelSelector: v1alpha1.ImageLabel + "=" + args[0],
+			if opts.output != "" && opts.output != k8s.FormatJSON {
+				return errors.Errorf("unsupported output format: %q, supported formats are json", opts.output)
+			}
+
+			if len(args) == 1 {
+				opts.image = args[0]
+			}
+
+			listNamespace := cs.Namespace
+			if opts.allNamespaces {
+				listNamespace = metav1.NamespaceAll
+			}
+
+			filter, err := newBuildFilter(cs, listNamespace, opts)
+			if err != nil {
+				return err
+			}
+
+			var selector string
+			if opts.image != "" {
+				selector = v1alpha1.ImageLabel + "=" + opts.image
+			}
+
+			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(listNamespace).List(metav1.ListOptions{
+				LabelSelector: selector,
 			})
 			if err != nil {
 				return err
 			}
 
+			if opts.watch {
+				return watchBuildList(cmd, cs, listNamespace, buildList, selector, filter, opts)
+			}
+
+			sort.Slice(buildList.Items, build.Sort(buildList.Items))
+			buildList.Items = filter.apply(buildList.Items)
+
 			if len(buildList.Items) == 0 {
 				return errors.New("no builds found")
-			} else {
-				sort.Slice(buildList.Items, build.Sort(buildList.Items))
-				return displayBuildsTable(cmd, buildList)
 			}
+
+			if opts.output == k8s.FormatJSON {
+				return printBuildList(cmd, buildList)
+			}
+			return displayBuildsTable(cmd.OutOrStdout(), buildList.Items, opts)
 		},
 	}
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().BoolVarP(&opts.allNamespaces, "all-namespaces", "A", false, "list builds across all namespaces")
+	cmd.Flags().StringVar(&opts.status, "status", "", "only list builds with this status (success, failure or building)")
+	cmd.Flags().StringVar(&opts.reason, "reason", "", "only list builds with this build reason (e.g. STACK)")
+	cmd.Flags().StringVar(&opts.since, "since", "", "only list builds started within this duration (e.g. 7d or 24h)")
+	cmd.Flags().StringVar(&opts.builder, "builder", "", "only list builds of images using this builder or cluster builder")
+	cmd.Flags().IntVar(&opts.limit, "limit", 0, "only list this number of the most recent builds")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "output format, supported formats are: json")
+	cmd.Flags().BoolVarP(&opts.watch, commands.WatchFlag, "w", false, "watch for changes to builds")
 
 	return cmd
 }
 
-func displayBuildsTable(cmd *cobra.Command, buildList *v1alpha1.BuildList) error {
-	writer, err := commands.NewTableWriter(cmd.OutOrStdout(), "Build", "Status", "Image", "Reason")
+type buildFilter struct {
+	status   string
+	reason   string
+	since    time.Time
+	builders map[string]bool
+	limit    int
+}
+
+func newBuildFilter(cs k8s.ClientSet, namespace string, opts listOptions) (buildFilter, error) {
+	filter := buildFilter{
+		status: strings.ToUpper(opts.status),
+		reason: strings.ToUpper(opts.reason),
+		limit:  opts.limit,
+	}
+
+	switch filter.status {
+	case "", "SUCCESS", "FAILURE", "BUILDING":
+	default:
+		return filter, errors.Errorf("unsupported status %q, supported statuses are success, failure, building", opts.status)
+	}
+
+	since, err := parseSince(opts.since)
+	if err != nil {
+		return filter, err
+	} else if since > 0 {
+		filter.since = time.Now().Add(-since)
+	}
+
+	if opts.limit < 0 {
+		return filter, errors.New("limit must be a positive number")
+	}
+
+	if opts.builder != "" {
+		imageList, err := cs.KpackClient.KpackV1alpha1().Images(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return filter, err
+		}
+
+		// builds only reference the builder image, the builder name comes from their image
+		filter.builders = map[string]bool{}
+		for _, img := range imageList.Items {
+			if img.Spec.Builder.Name == opts.builder {
+				filter.builders[img.Namespace+"/"+img.Name] = true
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+func (f buildFilter) matches(bld v1alpha1.Build) bool {
+	if f.status != "" && build.Status(bld) != f.status {
+		return false
+	}
+
+	if f.reason != "" && !containsReason(build.Reasons(bld), f.reason) {
+		return false
+	}
+
+	if !f.since.IsZero() && bld.CreationTimestamp.Time.Before(f.since) {
+		return false
+	}
+
+	if f.builders != nil && !f.builders[bld.Namespace+"/"+bld.Labels[v1alpha1.ImageLabel]] {
+		return false
+	}
+
+	return true
+}
+
+// apply returns the sorted builds that match the filter, keeping only the
+// most recent builds when there is a limit.
+func (f buildFilter) apply(builds []v1alpha1.Build) []v1alpha1.Build {
+	var filtered []v1alpha1.Build
+	for _, bld := range builds {
+		if f.matches(bld) {
+			filtered = append(filtered, bld)
+		}
+	}
+
+	if f.limit > 0 && len(filtered) > f.limit {
+		filtered = filtered[len(filtered)-f.limit:]
+	}
+	return filtered
+}
+
+func containsReason(reasons []string, reason string) bool {
+	for _, r := range reasons {
+		if strings.ToUpper(r) == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func watchBuildList(cmd *cobra.Command, cs k8s.ClientSet, namespace string, buildList *v1alpha1.BuildList, selector string, filter buildFilter, opts listOptions) error {
+	out := cmd.OutOrStdout()
+
+	if opts.output == k8s.FormatJSON {
+		return watchBuilds(cmd, cs, namespace, buildList, selector, func(builds []v1alpha1.Build, event *watch.Event) error {
+			if event != nil {
+				bld := event.Object.(*v1alpha1.Build)
+				if !filter.matches(*bld) {
+					return nil
+				}
+				return commands.WriteWatchEvent(out, event.Type, setBuildTypeMeta(bld))
+			}
+
+			builds = filter.apply(builds)
+			for i := range builds {
+				if err := commands.WriteWatchEvent(out, watch.Added, setBuildTypeMeta(&builds[i])); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	redrawer := commands.NewRedrawer(out)
+	return watchBuilds(cmd, cs, namespace, buildList, selector, func(builds []v1alpha1.Build, _ *watch.Event) error {
+		return redrawer.Redraw(func(out io.Writer) error {
+			return displayBuildsTable(out, filter.apply(builds), opts)
+		})
+	})
+}
+
+func printBuildList(cmd *cobra.Command, buildList *v1alpha1.BuildList) error {
+	printer, err := k8s.NewObjectPrinter(k8s.FormatJSON)
 	if err != nil {
 		return err
 	}
 
-	for _, bld := range buildList.Items {
-		err := writer.AddRow(
+	buildList.Kind = "BuildList"
+	buildList.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	for i := range buildList.Items {
+		setBuildTypeMeta(&buildList.Items[i])
+	}
+
+	return printer.PrintObject(buildList, cmd.OutOrStdout())
+}
+
+func displayBuildsTable(out io.Writer, builds []v1alpha1.Build, opts listOptions) error {
+	headers := []string{"Build", "Status", "Image", "Reason", "Started", "Duration"}
+	if opts.image == "" {
+		headers = append([]string{"Image Name"}, headers...)
+	}
+	if opts.allNamespaces {
+		headers = append([]string{"Namespace"}, headers...)
+	}
+
+	writer, err := commands.NewTableWriter(out, headers...)
+	if err != nil {
+		return err
+	}
+
+	for _, bld := range builds {
+		row := []string{
 			bld.Labels[v1alpha1.BuildNumberLabel],
-			getStatus(bld),
+			build.Status(bld),
 			bld.Status.LatestImage,
-			getTruncatedReason(bld),
-		)
+			build.TruncatedReason(bld),
+			getStarted(bld),
+			getDuration(bld),
+		}
+		if opts.image == "" {
+			row = append([]string{bld.Labels[v1alpha1.ImageLabel]}, row...)
+		}
+		if opts.allNamespaces {
+			row = append([]string{bld.Namespace}, row...)
+		}
+
+		err := writer.AddRow(row...)
 		if err != nil {
 			return err
 		}
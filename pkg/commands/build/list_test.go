@@ -5,10 +5,18 @@ package build_test
 
 import (
 	"testing"
+	"time"
 
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
 
 	"github.com/pivotal/build-service-cli/pkg/commands/build"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
@@ -22,15 +30,24 @@ func testBuildListCommand(t *testing.T, when spec.G, it spec.S) {
 	const (
 		image            = "test-image"
 		defaultNamespace = "some-default-namespace"
-		expectedOutput   = `BUILD    STATUS      IMAGE                   REASON
-1        SUCCESS     repo.com/image-1:tag    CONFIG
-2        FAILURE     repo.com/image-2:tag    COMMIT+
-3        BUILDING    repo.com/image-3:tag    TRIGGER
+		expectedOutput   = `BUILD    STATUS      IMAGE                   REASON     STARTED                DURATION
+1        SUCCESS     repo.com/image-1:tag    CONFIG     0001-01-01 00:00:00    
+2        FAILURE     repo.com/image-2:tag    COMMIT+    0001-01-01 01:00:00    
+3        BUILDING    repo.com/image-3:tag    TRIGGER    0001-01-01 05:00:00    
 
 `
 	)
 
+	var buildWatcher *watch.FakeWatcher
+
+	it.Before(func() {
+		buildWatcher = nil
+	})
+
 	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		if buildWatcher != nil {
+			clientSet.PrependWatchReactor("builds", k8stesting.DefaultWatchReactor(buildWatcher, nil))
+		}
 		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
 		return build.NewListCommand(clientSetProvider)
 	}
@@ -82,4 +99,231 @@ func testBuildListCommand(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 	})
+
+	when("watch is provided", func() {
+		it("prints the table for every change until the watch closes", func() {
+			builds := testhelpers.MakeTestBuilds(image, defaultNamespace)
+
+			finished := builds[1].(*v1alpha1.Build).DeepCopy()
+			finished.Status.Conditions = corev1alpha1.Conditions{
+				{
+					Type:   corev1alpha1.ConditionSucceeded,
+					Status: corev1.ConditionTrue,
+				},
+			}
+
+			buildWatcher = watch.NewFakeWithChanSize(1, false)
+			buildWatcher.Modify(finished)
+			buildWatcher.Stop()
+
+			testhelpers.CommandTest{
+				Objects: builds,
+				Args:    []string{image, "--watch"},
+				ExpectedOutput: expectedOutput + `BUILD    STATUS     IMAGE                   REASON     STARTED                DURATION
+1        SUCCESS    repo.com/image-1:tag    CONFIG     0001-01-01 00:00:00    
+2        FAILURE    repo.com/image-2:tag    COMMIT+    0001-01-01 01:00:00    
+3        SUCCESS    repo.com/image-3:tag    TRIGGER    0001-01-01 05:00:00    
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("prints a json event for every change", func() {
+			makeBuild := func(name, number string) *v1alpha1.Build {
+				return &v1alpha1.Build{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: defaultNamespace,
+						Labels: map[string]string{
+							v1alpha1.ImageLabel:       image,
+							v1alpha1.BuildNumberLabel: number,
+						},
+					},
+				}
+			}
+
+			buildWatcher = watch.NewFakeWithChanSize(1, false)
+			buildWatcher.Add(makeBuild("build-two", "2"))
+			buildWatcher.Stop()
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{makeBuild("build-one", "1")},
+				Args:    []string{image, "--watch", "-o", "json"},
+				ExpectedOutput: `{"type":"ADDED","object":{"kind":"Build","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"build-one","namespace":"some-default-namespace","creationTimestamp":null,"labels":{"image.kpack.io/buildNumber":"1","image.kpack.io/image":"test-image"}},"spec":{"builder":{},"source":{},"resources":{}},"status":{"stack":{}}}}
+{"type":"ADDED","object":{"kind":"Build","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"build-two","namespace":"some-default-namespace","creationTimestamp":null,"labels":{"image.kpack.io/buildNumber":"2","image.kpack.io/image":"test-image"}},"spec":{"builder":{},"source":{},"resources":{}},"status":{"stack":{}}}}
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+	})
+
+	when("no image name is provided", func() {
+		const otherNamespace = "some-other-namespace"
+
+		now := time.Now().Truncate(time.Second)
+
+		makeBuild := func(namespace, image, number, reason string, status corev1.ConditionStatus, age, duration time.Duration) *v1alpha1.Build {
+			created := now.Add(-age)
+			return &v1alpha1.Build{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              image + "-build-" + number,
+					Namespace:         namespace,
+					CreationTimestamp: metav1.Time{Time: created},
+					Labels: map[string]string{
+						v1alpha1.ImageLabel:       image,
+						v1alpha1.BuildNumberLabel: number,
+					},
+					Annotations: map[string]string{
+						v1alpha1.BuildReasonAnnotation: reason,
+					},
+				},
+				Status: v1alpha1.BuildStatus{
+					Status: corev1alpha1.Status{
+						Conditions: corev1alpha1.Conditions{{
+							Type:               corev1alpha1.ConditionSucceeded,
+							Status:             status,
+							LastTransitionTime: corev1alpha1.VolatileTime{Inner: metav1.Time{Time: created.Add(duration)}},
+						}},
+					},
+				},
+			}
+		}
+
+		makeImage := func(namespace, name, builder string) *v1alpha1.Image {
+			return &v1alpha1.Image{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec: v1alpha1.ImageSpec{
+					Builder: corev1.ObjectReference{Kind: v1alpha1.ClusterBuilderKind, Name: builder},
+				},
+			}
+		}
+
+		objects := []runtime.Object{
+			makeBuild(defaultNamespace, "image-a", "1", "CONFIG", corev1.ConditionTrue, 48*time.Hour, 150*time.Second),
+			makeBuild(defaultNamespace, "image-b", "1", "STACK", corev1.ConditionFalse, 2*time.Hour, 45*time.Second),
+			makeBuild(otherNamespace, "image-a", "7", "COMMIT,STACK", corev1.ConditionFalse, time.Hour, 90*time.Second),
+			makeImage(defaultNamespace, "image-a", "cluster-builder-1"),
+			makeImage(defaultNamespace, "image-b", "cluster-builder-2"),
+		}
+
+		started := func(age time.Duration) string {
+			return now.Add(-age).Format("2006-01-02 15:04:05")
+		}
+
+		it("lists the builds of all images in the namespace", func() {
+			testhelpers.CommandTest{
+				Objects: objects,
+				Args:    []string{},
+				ExpectedOutput: `IMAGE NAME    BUILD    STATUS     IMAGE    REASON    STARTED                DURATION
+image-a       1        SUCCESS             CONFIG    ` + started(48*time.Hour) + `    2m30s
+image-b       1        FAILURE             STACK     ` + started(2*time.Hour) + `    45s
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("lists the builds in all namespaces", func() {
+			testhelpers.CommandTest{
+				Objects: objects,
+				Args:    []string{"-A", "--status", "failure"},
+				ExpectedOutput: `NAMESPACE                 IMAGE NAME    BUILD    STATUS     IMAGE    REASON     STARTED                DURATION
+some-default-namespace    image-b       1        FAILURE             STACK      ` + started(2*time.Hour) + `    45s
+some-other-namespace      image-a       7        FAILURE             COMMIT+    ` + started(time.Hour) + `    1m30s
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("filters the builds by the time since they started", func() {
+			testhelpers.CommandTest{
+				Objects: objects,
+				Args:    []string{"--since", "24h"},
+				ExpectedOutput: `IMAGE NAME    BUILD    STATUS     IMAGE    REASON    STARTED                DURATION
+image-b       1        FAILURE             STACK     ` + started(2*time.Hour) + `    45s
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("filters the builds by a number of days since they started", func() {
+			testhelpers.CommandTest{
+				Objects: objects,
+				Args:    []string{"--since", "1d"},
+				ExpectedOutput: `IMAGE NAME    BUILD    STATUS     IMAGE    REASON    STARTED                DURATION
+image-b       1        FAILURE             STACK     ` + started(2*time.Hour) + `    45s
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("filters the builds by reason", func() {
+			testhelpers.CommandTest{
+				Objects: objects,
+				Args:    []string{"-A", "--reason", "commit"},
+				ExpectedOutput: `NAMESPACE               IMAGE NAME    BUILD    STATUS     IMAGE    REASON     STARTED                DURATION
+some-other-namespace    image-a       7        FAILURE             COMMIT+    ` + started(time.Hour) + `    1m30s
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("filters the builds by the builder of their image", func() {
+			testhelpers.CommandTest{
+				Objects: objects,
+				Args:    []string{"-A", "--builder", "cluster-builder-1"},
+				ExpectedOutput: `NAMESPACE                 IMAGE NAME    BUILD    STATUS     IMAGE    REASON    STARTED                DURATION
+some-default-namespace    image-a       1        SUCCESS             CONFIG    ` + started(48*time.Hour) + `    2m30s
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("limits the builds to the most recent ones", func() {
+			testhelpers.CommandTest{
+				Objects: objects,
+				Args:    []string{"-A", "--limit", "1"},
+				ExpectedOutput: `NAMESPACE               IMAGE NAME    BUILD    STATUS     IMAGE    REASON     STARTED                DURATION
+some-other-namespace    image-a       7        FAILURE             COMMIT+    ` + started(time.Hour) + `    1m30s
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("errors when no build matches the filters", func() {
+			testhelpers.CommandTest{
+				Objects:        objects,
+				Args:           []string{"--status", "building"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: no builds found\n",
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("errors on an unsupported status", func() {
+			testhelpers.CommandTest{
+				Objects:        objects,
+				Args:           []string{"--status", "broken"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: unsupported status \"broken\", supported statuses are success, failure, building\n",
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("errors on an invalid since duration", func() {
+			testhelpers.CommandTest{
+				Objects:        objects,
+				Args:           []string{"--since", "-1h"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: invalid since \"-1h\", must be a positive duration (e.g. 7d or 24h)\n",
+			}.TestKpack(t, cmdFunc)
+		})
+	})
+
+	when("an unsupported output format is provided", func() {
+		it("returns an error", func() {
+			testhelpers.CommandTest{
+				Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+				Args:           []string{image, "-o", "yaml"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: unsupported output format: \"yaml\", supported formats are json\n",
+			}.TestKpack(t, cmdFunc)
+		})
+	})
 }
@@ -5,23 +5,33 @@ package build
 
 import (
 	"context"
-	"sort"
+	"fmt"
+	"io"
+	"os"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
-	"github.com/pivotal/kpack/pkg/logs"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/pivotal/build-service-cli/pkg/build"
 	"github.com/pivotal/build-service-cli/pkg/commands"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/registry"
 )
 
-func NewLogsCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+type LogsClient interface {
+	Logs(ctx context.Context, out io.Writer, bld *v1alpha1.Build, opts build.LogOptions) error
+}
+
+func NewLogsCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider, newLogsClient func(k8s.ClientSet) LogsClient) *cobra.Command {
 	var (
 		namespace   string
 		buildNumber string
+		step        string
+		timestamps  bool
+		noFollow    bool
+		outputFile  string
+		tlsCfg      registry.TLSConfig
 	)
 
 	cmd := &cobra.Command{
@@ -30,37 +40,101 @@ func NewLogsCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		Long: `Tails logs from the containers of a specific build of an image in the provided namespace.
 
 The build defaults to the latest build number.
-The namespace defaults to the kubernetes current-context namespace.`,
-		Example:      "kp build logs my-image\nkp build logs my-image -b 2 -n my-namespace",
+The namespace defaults to the kubernetes current-context namespace.
+
+Use --step to only show the logs of a single lifecycle step: prepare, detect, analyze, restore, build or export.
+Use --no-follow to print the logs of the steps that have already run and exit.
+Use --output-file to also write the logs to a file.
+
+Once the pod of a completed build has been removed, the logs are read from the log archive
+created with "kp build archive-logs".`,
+		Example: `kp build logs my-image
+kp build logs my-image -b 2 -n my-namespace
+kp build logs my-image --step build --timestamps
+kp build logs my-image --no-follow --output-file build.log`,
 		Args:         commands.ExactArgsWithUsage(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := build.ValidateStep(step); err != nil {
+				return err
+			}
+
 			cs, err := clientSetProvider.GetClientSet(namespace)
 			if err != nil {
 				return err
 			}
 
-			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
-				LabelSelector: v1alpha1.ImageLabel + "=" + args[0],
-			})
+			bld, err := getBuild(cs, args[0], buildNumber)
 			if err != nil {
 				return err
 			}
 
-			if len(buildList.Items) == 0 {
-				return errors.New("no builds found")
-			} else {
-				sort.Slice(buildList.Items, build.Sort(buildList.Items))
-				bld, err := findBuild(buildList, buildNumber, args[0], cs.Namespace)
+			opts := build.LogOptions{
+				Step:       step,
+				Timestamps: timestamps,
+				Follow:     !noFollow,
+				Color:      outputFile == "" && commands.IsTerminal(cmd.OutOrStdout()),
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFile != "" {
+				f, err := os.Create(outputFile)
 				if err != nil {
-					return err
+					return errors.Wrap(err, "unable to create output file")
 				}
-				return logs.NewBuildLogsClient(cs.K8sClient).Tail(context.Background(), cmd.OutOrStdout(), args[0], bld.Labels[v1alpha1.BuildNumberLabel], cs.Namespace)
+				defer f.Close()
+
+				out = io.MultiWriter(out, f)
 			}
+
+			err = newLogsClient(cs).Logs(context.Background(), out, &bld, opts)
+			if errors.Cause(err) != build.ErrBuildPodNotFound {
+				return err
+			}
+
+			return printArchivedLogs(cmd.ErrOrStderr(), out, rup.Fetcher(), &bld, opts, tlsCfg)
 		},
 	}
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
 	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().StringVar(&step, "step", "", "only show logs of a lifecycle step (prepare, detect, analyze, restore, build or export)")
+	cmd.Flags().BoolVar(&timestamps, "timestamps", false, "prefix each log line with its timestamp")
+	cmd.Flags().BoolVar(&noFollow, "no-follow", false, "print the logs of completed steps and exit without waiting for the build")
+	cmd.Flags().StringVar(&outputFile, "output-file", "", "also write the logs to this file")
+	commands.SetTLSFlags(cmd, &tlsCfg)
 
 	return cmd
 }
+
+func printArchivedLogs(errOut, out io.Writer, fetcher registry.Fetcher, bld *v1alpha1.Build, opts build.LogOptions, tlsCfg registry.TLSConfig) error {
+	tag, err := build.LogArchiveTag(bld)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(errOut, "Build pod has been removed, reading logs from archive '%s'\n", tag); err != nil {
+		return err
+	}
+
+	logs, err := readArchivedLogs(fetcher, tag, opts, tlsCfg)
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(logs)
+	return err
+}
+
+func readArchivedLogs(fetcher registry.Fetcher, tag string, opts build.LogOptions, tlsCfg registry.TLSConfig) ([]byte, error) {
+	img, err := fetcher.Fetch(tag, tlsCfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read log archive")
+	}
+
+	logs, err := build.ReadLogArchive(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return build.FilterLogs(logs, opts)
+}
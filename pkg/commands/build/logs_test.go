@@ -4,13 +4,24 @@
 package build_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
+	buildpkg "github.com/pivotal/build-service-cli/pkg/build"
+	buildfakes "github.com/pivotal/build-service-cli/pkg/build/fakes"
 	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	registryfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
 )
 
@@ -24,12 +35,124 @@ func testBuildLogsCommand(t *testing.T, when spec.G, it spec.S) {
 		defaultNamespace = "some-default-namespace"
 	)
 
+	var (
+		fakeLogsClient *buildfakes.FakeLogsClient
+		fakeFetcher    *registryfakes.Fetcher
+	)
+
+	newLogsClient := func(k8s.ClientSet) build.LogsClient {
+		return fakeLogsClient
+	}
+
 	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
 		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-		return build.NewLogsCommand(clientSetProvider)
+		rup := registryfakes.UtilProvider{FakeFetcher: fakeFetcher}
+		return build.NewLogsCommand(clientSetProvider, rup, newLogsClient)
 	}
 
+	it.Before(func() {
+		fakeLogsClient = &buildfakes.FakeLogsClient{Output: "some logs\n"}
+		fakeFetcher = &registryfakes.Fetcher{}
+	})
+
 	when("getting build logs", func() {
+		it("follows the logs of the latest build without color when stdout is not a terminal", func() {
+			testhelpers.CommandTest{
+				Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+				Args:           []string{image},
+				ExpectedOutput: "some logs\n",
+			}.TestKpack(t, cmdFunc)
+
+			require.Len(t, fakeLogsClient.Calls, 1)
+			require.Equal(t, "build-three", fakeLogsClient.Calls[0].Build.Name)
+			require.Equal(t, buildpkg.LogOptions{Follow: true}, fakeLogsClient.Calls[0].Options)
+		})
+
+		it("passes the step, timestamps and no-follow options for the given build", func() {
+			testhelpers.CommandTest{
+				Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+				Args:           []string{image, "-b", "2", "--step", "detect", "--timestamps", "--no-follow"},
+				ExpectedOutput: "some logs\n",
+			}.TestKpack(t, cmdFunc)
+
+			require.Len(t, fakeLogsClient.Calls, 1)
+			require.Equal(t, "build-two", fakeLogsClient.Calls[0].Build.Name)
+			require.Equal(t, buildpkg.LogOptions{Step: "detect", Timestamps: true}, fakeLogsClient.Calls[0].Options)
+		})
+
+		it("writes the logs to the output file without color", func() {
+			dir, err := ioutil.TempDir("", "build-logs")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			outputFile := filepath.Join(dir, "build.log")
+
+			testhelpers.CommandTest{
+				Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+				Args:           []string{image, "--no-follow", "--output-file", outputFile},
+				ExpectedOutput: "some logs\n",
+			}.TestKpack(t, cmdFunc)
+
+			contents, err := ioutil.ReadFile(outputFile)
+			require.NoError(t, err)
+			require.Equal(t, "some logs\n", string(contents))
+			require.False(t, fakeLogsClient.Calls[0].Options.Color)
+		})
+
+		when("the build pod has been removed", func() {
+			completedBuild := &v1alpha1.Build{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "build-four",
+					Namespace: defaultNamespace,
+					Labels: map[string]string{
+						v1alpha1.ImageLabel:       image,
+						v1alpha1.BuildNumberLabel: "4",
+					},
+				},
+				Spec: v1alpha1.BuildSpec{
+					Tags: []string{"some-registry.io/some-repo"},
+				},
+			}
+
+			it.Before(func() {
+				fakeLogsClient.Err = buildpkg.ErrBuildPodNotFound
+			})
+
+			it("prints the logs from the log archive", func() {
+				archive, err := buildpkg.NewLogArchive(completedBuild, []byte("===> BUILD\n2020-10-01T10:00:00Z Building\n"))
+				require.NoError(t, err)
+				fakeFetcher.AddImage("some-registry.io/some-repo:build-four.logs", archive)
+
+				testhelpers.CommandTest{
+					Objects:             []runtime.Object{completedBuild},
+					Args:                []string{image, "--step", "build"},
+					ExpectedOutput:      "===> BUILD\nBuilding\n",
+					ExpectedErrorOutput: "Build pod has been removed, reading logs from archive 'some-registry.io/some-repo:build-four.logs'\n",
+				}.TestKpack(t, cmdFunc)
+			})
+
+			it("errors when there is no log archive", func() {
+				testhelpers.CommandTest{
+					Objects:             []runtime.Object{completedBuild},
+					Args:                []string{image},
+					ExpectErr:           true,
+					ExpectedOutput:      "Error: unable to read log archive: image not found: \"some-registry.io/some-repo:build-four.logs\"\n",
+					ExpectedErrorOutput: "Build pod has been removed, reading logs from archive 'some-registry.io/some-repo:build-four.logs'\n",
+				}.TestKpack(t, cmdFunc)
+			})
+		})
+
+		it("errors on an invalid step", func() {
+			testhelpers.CommandTest{
+				Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+				Args:           []string{image, "--step", "compile"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: invalid step \"compile\", must be one of prepare, detect, analyze, restore, build, export\n",
+			}.TestKpack(t, cmdFunc)
+
+			require.Len(t, fakeLogsClient.Calls, 0)
+		})
+
 		when("in the default namespace", func() {
 			when("the build does not exist", func() {
 				when("the build flag is provided", func() {
@@ -0,0 +1,271 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+const formatCSV = "csv"
+
+type imageStats struct {
+	Namespace   string         `json:"namespace"`
+	Image       string         `json:"image"`
+	Builds      int            `json:"builds"`
+	Succeeded   int            `json:"succeeded"`
+	Failed      int            `json:"failed"`
+	Running     int            `json:"running"`
+	SuccessRate *float64       `json:"successRate,omitempty"`
+	P50         string         `json:"p50,omitempty"`
+	P95         string         `json:"p95,omitempty"`
+	Reasons     map[string]int `json:"reasons,omitempty"`
+
+	p50, p95 *time.Duration
+}
+
+func NewStatsCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	var (
+		namespace     string
+		allNamespaces bool
+		since         string
+		output        string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stats [image-name]",
+		Short: "Summarize build durations and success rates",
+		Long: `Prints the number of builds, success rate, median (p50) and p95 build durations and the build
+counts by reason of every image in the provided namespace.
+
+When an image name is provided, only the builds of that image are summarized.
+Durations are measured from the creation of a build to its completion, running builds are not included.
+
+The "--since" flag only summarizes builds started within a duration. Days may be used, e.g. "7d".
+The "-o csv" and "-o json" flags print the summary in a machine readable form.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example: `kp build stats
+kp build stats my-image --since 7d
+kp build stats --all-namespaces --since 24h -o csv`,
+		Args:         commands.MaxArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch output {
+			case "", k8s.FormatJSON, formatCSV:
+			default:
+				return errors.Errorf("unsupported output format: %q, supported formats are csv, json", output)
+			}
+
+			sinceDuration, err := parseSince(since)
+			if err != nil {
+				return err
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			listNamespace := cs.Namespace
+			if allNamespaces {
+				listNamespace = metav1.NamespaceAll
+			}
+
+			var selector string
+			if len(args) == 1 {
+				selector = v1alpha1.ImageLabel + "=" + args[0]
+			}
+
+			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(listNamespace).List(metav1.ListOptions{
+				LabelSelector: selector,
+			})
+			if err != nil {
+				return err
+			}
+
+			var builds []v1alpha1.Build
+			for _, bld := range buildList.Items {
+				if sinceDuration > 0 && bld.CreationTimestamp.Time.Before(time.Now().Add(-sinceDuration)) {
+					continue
+				}
+				builds = append(builds, bld)
+			}
+
+			if len(builds) == 0 {
+				return errors.New("no builds found")
+			}
+
+			stats := newImageStats(build.Aggregate(builds))
+
+			switch output {
+			case k8s.FormatJSON:
+				return printStatsJSON(cmd.OutOrStdout(), stats)
+			case formatCSV:
+				return printStatsCSV(cmd.OutOrStdout(), stats)
+			default:
+				return displayStatsTable(cmd.OutOrStdout(), stats, allNamespaces)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "summarize builds across all namespaces")
+	cmd.Flags().StringVar(&since, "since", "", "only summarize builds started within this duration (e.g. 7d or 24h)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output format, supported formats are: csv, json")
+
+	return cmd
+}
+
+func newImageStats(stats []build.Stats) []imageStats {
+	var result []imageStats
+	for _, s := range stats {
+		is := imageStats{
+			Namespace: s.Namespace,
+			Image:     s.Image,
+			Builds:    s.Builds,
+			Succeeded: s.Succeeded,
+			Failed:    s.Failed,
+			Running:   s.Running,
+			Reasons:   s.Reasons,
+		}
+
+		if rate, ok := s.SuccessRate(); ok {
+			is.SuccessRate = &rate
+		}
+		if d, ok := s.Percentile(50); ok {
+			is.p50 = &d
+			is.P50 = d.Round(time.Second).String()
+		}
+		if d, ok := s.Percentile(95); ok {
+			is.p95 = &d
+			is.P95 = d.Round(time.Second).String()
+		}
+
+		result = append(result, is)
+	}
+	return result
+}
+
+func (s imageStats) successRate() string {
+	if s.SuccessRate == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.0f%%", *s.SuccessRate*100)
+}
+
+func (s imageStats) reasons(sep string) string {
+	var names []string
+	for name := range s.Reasons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var reasons []string
+	for _, name := range names {
+		reasons = append(reasons, fmt.Sprintf("%s=%d", name, s.Reasons[name]))
+	}
+	return strings.Join(reasons, sep)
+}
+
+func displayStatsTable(out io.Writer, stats []imageStats, allNamespaces bool) error {
+	headers := []string{"Image", "Builds", "Success", "Failure", "Building", "Success Rate", "P50", "P95", "Reasons"}
+	if allNamespaces {
+		headers = append([]string{"Namespace"}, headers...)
+	}
+
+	writer, err := commands.NewTableWriter(out, headers...)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range stats {
+		row := []string{
+			s.Image,
+			strconv.Itoa(s.Builds),
+			strconv.Itoa(s.Succeeded),
+			strconv.Itoa(s.Failed),
+			strconv.Itoa(s.Running),
+			s.successRate(),
+			s.P50,
+			s.P95,
+			s.reasons(", "),
+		}
+		if allNamespaces {
+			row = append([]string{s.Namespace}, row...)
+		}
+
+		if err := writer.AddRow(row...); err != nil {
+			return err
+		}
+	}
+
+	return writer.Write()
+}
+
+func printStatsJSON(out io.Writer, stats []imageStats) error {
+	buf, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, string(buf))
+	return err
+}
+
+func printStatsCSV(out io.Writer, stats []imageStats) error {
+	writer := csv.NewWriter(out)
+
+	err := writer.Write([]string{"namespace", "image", "builds", "succeeded", "failed", "running", "success_rate", "p50_seconds", "p95_seconds", "reasons"})
+	if err != nil {
+		return err
+	}
+
+	seconds := func(d *time.Duration) string {
+		if d == nil {
+			return ""
+		}
+		return strconv.FormatFloat(d.Seconds(), 'f', 0, 64)
+	}
+
+	for _, s := range stats {
+		var rate string
+		if s.SuccessRate != nil {
+			rate = strconv.FormatFloat(*s.SuccessRate, 'f', 2, 64)
+		}
+
+		err := writer.Write([]string{
+			s.Namespace,
+			s.Image,
+			strconv.Itoa(s.Builds),
+			strconv.Itoa(s.Succeeded),
+			strconv.Itoa(s.Failed),
+			strconv.Itoa(s.Running),
+			rate,
+			seconds(s.p50),
+			seconds(s.p95),
+			s.reasons(";"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,160 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildStatsCommand(t *testing.T) {
+	spec.Run(t, "TestBuildStatsCommand", testBuildStatsCommand)
+}
+
+func testBuildStatsCommand(t *testing.T, when spec.G, it spec.S) {
+	const defaultNamespace = "some-default-namespace"
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return build.NewStatsCommand(clientSetProvider)
+	}
+
+	now := time.Now()
+
+	makeBuild := func(namespace, image, number string, age time.Duration, status corev1.ConditionStatus, duration time.Duration, reasons string) *v1alpha1.Build {
+		created := now.Add(-age)
+		return &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              image + "-build-" + number,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.Time{Time: created},
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       image,
+					v1alpha1.BuildNumberLabel: number,
+				},
+				Annotations: map[string]string{
+					v1alpha1.BuildReasonAnnotation: reasons,
+				},
+			},
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{
+						{
+							Type:               corev1alpha1.ConditionSucceeded,
+							Status:             status,
+							LastTransitionTime: corev1alpha1.VolatileTime{Inner: metav1.Time{Time: created.Add(duration)}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	builds := []runtime.Object{
+		makeBuild(defaultNamespace, "image-a", "1", 10*24*time.Hour, corev1.ConditionFalse, 10*time.Minute, "CONFIG"),
+		makeBuild(defaultNamespace, "image-a", "2", 3*24*time.Hour, corev1.ConditionTrue, 2*time.Minute, "COMMIT"),
+		makeBuild(defaultNamespace, "image-a", "3", 2*24*time.Hour, corev1.ConditionFalse, time.Minute, "COMMIT,BUILDPACK"),
+		makeBuild(defaultNamespace, "image-a", "4", 24*time.Hour, corev1.ConditionTrue, 3*time.Minute, "STACK"),
+		makeBuild(defaultNamespace, "image-b", "1", time.Hour, corev1.ConditionUnknown, 0, "CONFIG"),
+		makeBuild("other-namespace", "image-c", "1", time.Hour, corev1.ConditionTrue, 90*time.Second, "CONFIG"),
+	}
+
+	it("summarizes the builds of every image in the namespace", func() {
+		testhelpers.CommandTest{
+			Objects: builds,
+			Args:    []string{},
+			ExpectedOutput: `IMAGE      BUILDS    SUCCESS    FAILURE    BUILDING    SUCCESS RATE    P50     P95      REASONS
+image-a    4         2          2          0           50%             2m0s    10m0s    BUILDPACK=1, COMMIT=2, CONFIG=1, STACK=1
+image-b    1         0          0          1                                            CONFIG=1
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("summarizes the builds of an image started within the since duration", func() {
+		testhelpers.CommandTest{
+			Objects: builds,
+			Args:    []string{"image-a", "--since", "7d"},
+			ExpectedOutput: `IMAGE      BUILDS    SUCCESS    FAILURE    BUILDING    SUCCESS RATE    P50     P95     REASONS
+image-a    3         2          1          0           67%             2m0s    3m0s    BUILDPACK=1, COMMIT=2, STACK=1
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("summarizes builds across all namespaces as csv", func() {
+		testhelpers.CommandTest{
+			Objects: builds,
+			Args:    []string{"-A", "--since", "36h", "-o", "csv"},
+			ExpectedOutput: `namespace,image,builds,succeeded,failed,running,success_rate,p50_seconds,p95_seconds,reasons
+other-namespace,image-c,1,1,0,0,1.00,90,90,CONFIG=1
+some-default-namespace,image-a,1,1,0,0,1.00,180,180,STACK=1
+some-default-namespace,image-b,1,0,0,1,,,,CONFIG=1
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("summarizes builds as json", func() {
+		testhelpers.CommandTest{
+			Objects: builds,
+			Args:    []string{"image-a", "--since", "36h", "-o", "json"},
+			ExpectedOutput: `[
+  {
+    "namespace": "some-default-namespace",
+    "image": "image-a",
+    "builds": 1,
+    "succeeded": 1,
+    "failed": 0,
+    "running": 0,
+    "successRate": 1,
+    "p50": "3m0s",
+    "p95": "3m0s",
+    "reasons": {
+      "STACK": 1
+    }
+  }
+]
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error when no builds are found", func() {
+		testhelpers.CommandTest{
+			Objects:        builds,
+			Args:           []string{"image-a", "--since", "1h"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: no builds found\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error for an invalid since duration", func() {
+		testhelpers.CommandTest{
+			Objects:        builds,
+			Args:           []string{"--since", "a week"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: invalid since \"a week\", must be a positive duration (e.g. 7d or 24h)\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error for an unsupported output format", func() {
+		testhelpers.CommandTest{
+			Objects:        builds,
+			Args:           []string{"-o", "yaml"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: unsupported output format: \"yaml\", supported formats are csv, json\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
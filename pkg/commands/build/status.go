@@ -4,14 +4,22 @@
 package build
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/yaml"
 
 	"github.com/pivotal/build-service-cli/pkg/build"
 	"github.com/pivotal/build-service-cli/pkg/commands"
@@ -22,6 +30,8 @@ func NewStatusCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 	var (
 		namespace   string
 		buildNumber string
+		output      string
+		watchFlag   bool
 	)
 
 	cmd := &cobra.Command{
@@ -29,9 +39,16 @@ func NewStatusCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		Short: "Display status for an image build",
 		Long: `Prints detailed information about the status of a specific build of an image in the provided namespace.
 
+The status includes the duration of every lifecycle step, the cache volume, the build reasons,
+the env vars of the build with their values masked and the resolved git commit.
+The "-o json" and "-o yaml" flags print the same details in a structured form.
+
 The build defaults to the latest build number.
-The namespace defaults to the kubernetes current-context namespace.`,
-		Example:      "kp build status my-image\nkp build status my-image -b 2 -n my-namespace",
+The namespace defaults to the kubernetes current-context namespace.
+
+The "--watch" flag keeps the command running and updates the status as the build progresses.
+The status is redrawn in place on a terminal. With "-o json", one json event is printed per change.`,
+		Example:      "kp build status my-image\nkp build status my-image -b 2 -n my-namespace\nkp build status my-image -o yaml\nkp build status my-image --watch",
 		Args:         commands.ExactArgsWithUsage(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -40,8 +57,17 @@ The namespace defaults to the kubernetes current-context namespace.`,
 				return err
 			}
 
+			if output != "" && output != k8s.FormatJSON && output != k8s.FormatYAML {
+				return errors.Errorf("unsupported output format: %q, supported formats are json, yaml", output)
+			}
+
+			if watchFlag && output == k8s.FormatYAML {
+				return errors.New("--watch only supports the json output format")
+			}
+
+			selector := v1alpha1.ImageLabel + "=" + args[0]
 			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
-				LabelSelector: v1alpha1.ImageLabel + "=" + args[0],
+				LabelSelector: selector,
 			})
 			if err != nil {
 				return err
@@ -49,26 +75,68 @@ The namespace defaults to the kubernetes current-context namespace.`,
 
 			if len(buildList.Items) == 0 {
 				return errors.New("no builds found")
-			} else {
-				sort.Slice(buildList.Items, build.Sort(buildList.Items))
-				bld, err := findBuild(buildList, buildNumber, args[0], cs.Namespace)
-				if err != nil {
-					return err
-				}
-				return displayBuildStatus(cmd, bld)
 			}
+
+			if watchFlag {
+				return watchBuildStatus(cmd, cs, buildList, selector, buildNumber, output)
+			}
+
+			sort.Slice(buildList.Items, build.Sort(buildList.Items))
+			bld, err := findBuild(buildList.Items, buildNumber)
+			if err != nil {
+				return err
+			}
+
+			if output != "" {
+				return printBuildStatus(cmd.OutOrStdout(), bld, output)
+			}
+			return displayBuildStatus(cmd.OutOrStdout(), bld)
 		},
 	}
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
 	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output format, supported formats are: json, yaml")
+	cmd.Flags().BoolVarP(&watchFlag, commands.WatchFlag, "w", false, "watch for changes to the build")
 
 	return cmd
 }
 
-func findBuild(buildList *v1alpha1.BuildList, buildNumberString string, img, namespace string) (v1alpha1.Build, error) {
+func watchBuildStatus(cmd *cobra.Command, cs k8s.ClientSet, buildList *v1alpha1.BuildList, selector, buildNumber, output string) error {
+	out := cmd.OutOrStdout()
+	redrawer := commands.NewRedrawer(out)
+
+	return watchBuilds(cmd, cs, cs.Namespace, buildList, selector, func(builds []v1alpha1.Build, event *watch.Event) error {
+		if len(builds) == 0 {
+			return errors.New("no builds found")
+		}
+
+		bld, err := findBuild(builds, buildNumber)
+		if err != nil {
+			return err
+		}
+
+		if output != k8s.FormatJSON {
+			return redrawer.Redraw(func(out io.Writer) error {
+				return displayBuildStatus(out, bld)
+			})
+		}
+
+		eventType := watch.Added
+		if event != nil {
+			// only changes to the displayed build are printed
+			if event.Object.(*v1alpha1.Build).Name != bld.Name {
+				return nil
+			}
+			eventType = event.Type
+		}
+		return commands.WriteWatchEvent(out, eventType, setBuildTypeMeta(&bld))
+	})
+}
+
+func findBuild(builds []v1alpha1.Build, buildNumberString string) (v1alpha1.Build, error) {
 
 	if buildNumberString == "" {
-		return buildList.Items[len(buildList.Items)-1], nil
+		return builds[len(builds)-1], nil
 	}
 
 	buildNumber, err := strconv.Atoi(buildNumberString)
@@ -76,7 +144,7 @@ func findBuild(buildList *v1alpha1.BuildList, buildNumberString string, img, nam
 		return v1alpha1.Build{}, errors.Errorf("build number should be an integer: %v", buildNumberString)
 	}
 
-	for _, b := range buildList.Items {
+	for _, b := range builds {
 		val, err := strconv.Atoi(b.Labels[v1alpha1.BuildNumberLabel])
 		if err != nil {
 			return v1alpha1.Build{}, err
@@ -90,20 +158,184 @@ func findBuild(buildList *v1alpha1.BuildList, buildNumberString string, img, nam
 	return v1alpha1.Build{}, errors.Errorf("build \"%d\" not found", buildNumber)
 }
 
-func displayBuildStatus(cmd *cobra.Command, bld v1alpha1.Build) error {
-	statusWriter := commands.NewStatusWriter(cmd.OutOrStdout())
+const maskedEnvValue = "********"
+
+type buildStatus struct {
+	Image         string                 `json:"image"`
+	Build         string                 `json:"build"`
+	Namespace     string                 `json:"namespace"`
+	Status        string                 `json:"status"`
+	Reasons       []string               `json:"reasons,omitempty"`
+	StatusReason  string                 `json:"statusReason,omitempty"`
+	StatusMessage string                 `json:"statusMessage,omitempty"`
+	LatestImage   string                 `json:"latestImage,omitempty"`
+	Started       *metav1.Time           `json:"started,omitempty"`
+	Finished      *metav1.Time           `json:"finished,omitempty"`
+	Duration      string                 `json:"duration,omitempty"`
+	PodName       string                 `json:"podName,omitempty"`
+	CacheVolume   string                 `json:"cacheVolume,omitempty"`
+	Builder       string                 `json:"builder,omitempty"`
+	RunImage      string                 `json:"runImage,omitempty"`
+	Source        buildStatusSource      `json:"source"`
+	Env           []buildStatusEnv       `json:"env,omitempty"`
+	Steps         []buildStatusStep      `json:"steps,omitempty"`
+	Buildpacks    []buildStatusBuildpack `json:"buildpacks,omitempty"`
+}
+
+type buildStatusSource struct {
+	Type    string `json:"type"`
+	Url     string `json:"url,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+	Image   string `json:"image,omitempty"`
+	SubPath string `json:"subPath,omitempty"`
+}
+
+type buildStatusEnv struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type buildStatusStep struct {
+	Name     string       `json:"name"`
+	State    string       `json:"state"`
+	ExitCode int32        `json:"exitCode,omitempty"`
+	Started  *metav1.Time `json:"started,omitempty"`
+	Finished *metav1.Time `json:"finished,omitempty"`
+	Duration string       `json:"duration,omitempty"`
+}
+
+type buildStatusBuildpack struct {
+	Id      string `json:"id"`
+	Version string `json:"version"`
+}
+
+func newBuildStatus(bld v1alpha1.Build) buildStatus {
+	status := buildStatus{
+		Image:       bld.Labels[v1alpha1.ImageLabel],
+		Build:       bld.Labels[v1alpha1.BuildNumberLabel],
+		Namespace:   bld.Namespace,
+		Status:      build.Status(bld),
+		Reasons:     build.Reasons(bld),
+		LatestImage: bld.Status.LatestImage,
+		Duration:    getDuration(bld),
+		PodName:     bld.Status.PodName,
+		CacheVolume: bld.Spec.CacheName,
+		Builder:     bld.Spec.Builder.Image,
+		RunImage:    bld.Status.Stack.RunImage,
+		Source:      newBuildStatusSource(bld.Spec.Source),
+	}
+
+	if !bld.CreationTimestamp.IsZero() {
+		status.Started = &bld.CreationTimestamp
+	}
+
+	if cond := bld.Status.GetCondition(corev1alpha1.ConditionSucceeded); cond != nil {
+		status.StatusReason = cond.Reason
+		status.StatusMessage = cond.Message
+		if !bld.IsRunning() && !cond.LastTransitionTime.Inner.IsZero() {
+			status.Finished = &cond.LastTransitionTime.Inner
+		}
+	}
+
+	for _, env := range bld.Spec.Env {
+		status.Env = append(status.Env, buildStatusEnv{Name: env.Name, Value: maskEnvValue(env)})
+	}
+
+	for _, step := range build.StepStatuses(&bld) {
+		s := buildStatusStep{
+			Name:     step.Name,
+			State:    step.State,
+			ExitCode: step.ExitCode,
+			Started:  optionalTime(step.Started),
+			Finished: optionalTime(step.Finished),
+		}
+		if d, ok := step.Duration(); ok {
+			s.Duration = d.Round(time.Second).String()
+		}
+		status.Steps = append(status.Steps, s)
+	}
+
+	for _, bp := range bld.Status.BuildMetadata {
+		status.Buildpacks = append(status.Buildpacks, buildStatusBuildpack{Id: bp.Id, Version: bp.Version})
+	}
+
+	return status
+}
+
+func newBuildStatusSource(source v1alpha1.SourceConfig) buildStatusSource {
+	switch {
+	case source.Git != nil:
+		// the revision of a build is the commit resolved from the image revision
+		return buildStatusSource{Type: "GitUrl", Url: source.Git.URL, Commit: source.Git.Revision, SubPath: source.SubPath}
+	case source.Blob != nil:
+		return buildStatusSource{Type: "Blob", Url: source.Blob.URL, SubPath: source.SubPath}
+	case source.Registry != nil:
+		return buildStatusSource{Type: "Registry", Image: source.Registry.Image, SubPath: source.SubPath}
+	default:
+		return buildStatusSource{Type: "Local Source"}
+	}
+}
+
+// maskEnvValue hides the value of an env var, only the source of values
+// read from secrets and config maps is shown.
+func maskEnvValue(env corev1.EnvVar) string {
+	switch {
+	case env.ValueFrom == nil:
+		return maskedEnvValue
+	case env.ValueFrom.SecretKeyRef != nil:
+		return fmt.Sprintf("from secret %s/%s", env.ValueFrom.SecretKeyRef.Name, env.ValueFrom.SecretKeyRef.Key)
+	case env.ValueFrom.ConfigMapKeyRef != nil:
+		return fmt.Sprintf("from config map %s/%s", env.ValueFrom.ConfigMapKeyRef.Name, env.ValueFrom.ConfigMapKeyRef.Key)
+	case env.ValueFrom.FieldRef != nil:
+		return fmt.Sprintf("from field %s", env.ValueFrom.FieldRef.FieldPath)
+	default:
+		return maskedEnvValue
+	}
+}
+
+func optionalTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &metav1.Time{Time: t}
+}
+
+func printBuildStatus(out io.Writer, bld v1alpha1.Build, output string) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	status := newBuildStatus(bld)
+	if output == k8s.FormatYAML {
+		data, err = yaml.Marshal(status)
+	} else {
+		data, err = json.MarshalIndent(status, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(data)
+	return err
+}
+
+func displayBuildStatus(out io.Writer, bld v1alpha1.Build) error {
+	status := newBuildStatus(bld)
+	statusWriter := commands.NewStatusWriter(out)
 
 	statusItems := []string{
-		"Image", bld.Status.LatestImage,
-		"Status", getStatus(bld),
-		"Build Reason", bld.Annotations[v1alpha1.BuildReasonAnnotation],
+		"Image", status.LatestImage,
+		"Status", status.Status,
+		"Build Reason", strings.Join(status.Reasons, ", "),
 	}
 
-	if cond := bld.Status.GetCondition(corev1alpha1.ConditionSucceeded); cond.Reason != "" {
-		statusItems = append(statusItems, "Status Reason", cond.Reason)
+	if status.StatusReason != "" {
+		statusItems = append(statusItems, "Status Reason", status.StatusReason)
 	}
-	if cond := bld.Status.GetCondition(corev1alpha1.ConditionSucceeded); cond.Message != "" {
-		statusItems = append(statusItems, "Status Message", cond.Message)
+	if status.StatusMessage != "" {
+		statusItems = append(statusItems, "Status Message", status.StatusMessage)
 	}
 
 	err := statusWriter.AddBlock("", statusItems...)
@@ -115,60 +347,93 @@ func displayBuildStatus(cmd *cobra.Command, bld v1alpha1.Build) error {
 		"",
 		"Started", getStarted(bld),
 		"Finished", getFinished(bld),
+		"Duration", status.Duration,
 	)
+	if err != nil {
+		return err
+	}
 
-	err = statusWriter.AddBlock("",
-		"Pod Name", bld.Status.PodName)
+	err = statusWriter.AddBlock(
+		"",
+		"Pod Name", status.PodName,
+		"Cache Volume", status.CacheVolume,
+	)
 	if err != nil {
 		return err
 	}
 
 	err = statusWriter.AddBlock(
 		"",
-		"Builder", bld.Spec.Builder.Image,
-		"Run Image", bld.Status.Stack.RunImage,
+		"Builder", status.Builder,
+		"Run Image", status.RunImage,
 	)
 	if err != nil {
 		return err
 	}
 
-	if bld.Spec.Source.Git != nil {
-		err = statusWriter.AddBlock(
-			"",
-			"Source", "GitUrl",
-			"Url", bld.Spec.Source.Git.URL,
-			"Revision", bld.Spec.Source.Git.Revision,
-		)
+	sourceItems := []string{"Source", status.Source.Type}
+	switch status.Source.Type {
+	case "GitUrl":
+		sourceItems = append(sourceItems, "Url", status.Source.Url, "Commit", status.Source.Commit)
+	case "Blob":
+		sourceItems = append(sourceItems, "Url", status.Source.Url)
+	case "Registry":
+		sourceItems = append(sourceItems, "Image", status.Source.Image)
+	}
+	if status.Source.SubPath != "" {
+		sourceItems = append(sourceItems, "Sub Path", status.Source.SubPath)
+	}
+
+	err = statusWriter.AddBlock("", sourceItems...)
+	if err != nil {
+		return err
+	}
+
+	err = statusWriter.Write()
+	if err != nil {
+		return err
+	}
+
+	if len(status.Env) > 0 {
+		envWriter, err := commands.NewTableWriter(out, "Env Var", "Value")
 		if err != nil {
 			return err
 		}
-	} else if bld.Spec.Source.Blob != nil {
-		err = statusWriter.AddBlock(
-			"",
-			"Source", "Blob",
-			"Url", bld.Spec.Source.Blob.URL,
-		)
-		if err != nil {
+
+		for _, env := range status.Env {
+			if err := envWriter.AddRow(env.Name, env.Value); err != nil {
+				return err
+			}
+		}
+
+		if err := envWriter.Write(); err != nil {
 			return err
 		}
-	} else {
-		err = statusWriter.AddBlock("", "Source", "Local Source")
+	}
+
+	if len(status.Steps) > 0 {
+		stepWriter, err := commands.NewTableWriter(out, "Step", "State", "Duration")
 		if err != nil {
 			return err
 		}
-	}
 
-	err = statusWriter.Write()
-	if err != nil {
-		return err
+		for _, step := range status.Steps {
+			if err := stepWriter.AddRow(step.Name, step.State, step.Duration); err != nil {
+				return err
+			}
+		}
+
+		if err := stepWriter.Write(); err != nil {
+			return err
+		}
 	}
 
-	tableWriter, err := commands.NewTableWriter(cmd.OutOrStdout(), "Buildpack Id", "Buildpack Version")
+	tableWriter, err := commands.NewTableWriter(out, "Buildpack Id", "Buildpack Version")
 	if err != nil {
 		return err
 	}
 
-	for _, buildpack := range bld.Status.BuildMetadata {
+	for _, buildpack := range status.Buildpacks {
 		err := tableWriter.AddRow(buildpack.Id, buildpack.Version)
 		if err != nil {
 			return err
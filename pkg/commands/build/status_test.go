@@ -15,6 +15,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
 
 	"github.com/pivotal/build-service-cli/pkg/commands/build"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
@@ -34,8 +36,10 @@ Build Reason:    TRIGGER
 
 Started:     0001-01-01 05:00:00
 Finished:    --
+Duration:    --
 
-Pod Name:    pod-three
+Pod Name:        pod-three
+Cache Volume:    --
 
 Builder:      some-repo.com/my-builder
 Run Image:    some-repo.com/run-image
@@ -53,8 +57,10 @@ Build Reason:    CONFIG
 
 Started:     0001-01-01 00:00:00
 Finished:    0001-01-01 00:00:00
+Duration:    --
 
-Pod Name:    pod-one
+Pod Name:        pod-one
+Cache Volume:    --
 
 Builder:      some-repo.com/my-builder
 Run Image:    some-repo.com/run-image
@@ -68,7 +74,16 @@ bp-id-2         bp-version-2
 `
 	)
 
+	var buildWatcher *watch.FakeWatcher
+
+	it.Before(func() {
+		buildWatcher = nil
+	})
+
 	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		if buildWatcher != nil {
+			clientSet.PrependWatchReactor("builds", k8stesting.DefaultWatchReactor(buildWatcher, nil))
+		}
 		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
 		return build.NewStatusCommand(clientSetProvider)
 	}
@@ -180,8 +195,10 @@ Status Message:    some-message
 
 Started:     0001-01-01 05:00:00
 Finished:    --
+Duration:    --
 
-Pod Name:    some-pod
+Pod Name:        some-pod
+Cache Volume:    --
 
 Builder:      some-repo.com/my-builder
 Run Image:    some-repo.com/run-image
@@ -247,4 +264,319 @@ bp-id-2         bp-version-2
 			})
 		})
 	})
+
+	when("the build has lifecycle details", func() {
+		start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+		terminated := func(started, finished time.Duration) corev1.ContainerState {
+			return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				StartedAt:  metav1.Time{Time: start.Add(started)},
+				FinishedAt: metav1.Time{Time: start.Add(finished)},
+			}}
+		}
+
+		bld := &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "build-one",
+				Namespace:         defaultNamespace,
+				CreationTimestamp: metav1.Time{Time: start},
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       image,
+					v1alpha1.BuildNumberLabel: "1",
+				},
+				Annotations: map[string]string{
+					v1alpha1.BuildReasonAnnotation: "COMMIT,BUILDPACK",
+				},
+			},
+			Spec: v1alpha1.BuildSpec{
+				Builder:   v1alpha1.BuildBuilderSpec{Image: "some-repo.com/my-builder"},
+				CacheName: "test-image-cache",
+				Source: v1alpha1.SourceConfig{
+					Git: &v1alpha1.Git{
+						URL:      "https://github.com/some-org/some-repo",
+						Revision: "b5ad3a8cf3f3d5c1d2db2a0d4d5e4c6d0bb6e5f1",
+					},
+					SubPath: "app",
+				},
+				Env: []corev1.EnvVar{
+					{Name: "BP_JAVA_VERSION", Value: "11"},
+					{Name: "API_TOKEN", ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "some-secret"},
+							Key:                  "token",
+						},
+					}},
+				},
+			},
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{
+						{
+							Type:               corev1alpha1.ConditionSucceeded,
+							Status:             corev1.ConditionTrue,
+							LastTransitionTime: corev1alpha1.VolatileTime{Inner: metav1.Time{Time: start.Add(2 * time.Minute)}},
+						},
+					},
+				},
+				BuildMetadata: v1alpha1.BuildpackMetadataList{
+					{Id: "bp-id-1", Version: "bp-version-1"},
+				},
+				Stack:       v1alpha1.BuildStack{RunImage: "some-repo.com/run-image"},
+				LatestImage: "repo.com/image-1:tag",
+				PodName:     "pod-one",
+				StepStates: []corev1.ContainerState{
+					terminated(0, 5*time.Second),
+					terminated(5*time.Second, 8*time.Second),
+					terminated(8*time.Second, 10*time.Second),
+					terminated(10*time.Second, 20*time.Second),
+					terminated(20*time.Second, 100*time.Second),
+					terminated(100*time.Second, 119*time.Second),
+				},
+				StepsCompleted: []string{"prepare", "detect", "analyze", "restore", "build", "export"},
+			},
+		}
+
+		it("shows the steps, cache, env and commit of the build", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{bld},
+				Args:    []string{image},
+				ExpectedOutput: `Image:           repo.com/image-1:tag
+Status:          SUCCESS
+Build Reason:    COMMIT, BUILDPACK
+
+Started:     2020-10-01 12:00:00
+Finished:    2020-10-01 12:02:00
+Duration:    2m0s
+
+Pod Name:        pod-one
+Cache Volume:    test-image-cache
+
+Builder:      some-repo.com/my-builder
+Run Image:    some-repo.com/run-image
+
+Source:      GitUrl
+Url:         https://github.com/some-org/some-repo
+Commit:      b5ad3a8cf3f3d5c1d2db2a0d4d5e4c6d0bb6e5f1
+Sub Path:    app
+
+ENV VAR            VALUE
+BP_JAVA_VERSION    ********
+API_TOKEN          from secret some-secret/token
+
+STEP       STATE        DURATION
+prepare    Succeeded    5s
+detect     Succeeded    3s
+analyze    Succeeded    2s
+restore    Succeeded    10s
+build      Succeeded    1m20s
+export     Succeeded    19s
+
+BUILDPACK ID    BUILDPACK VERSION
+bp-id-1         bp-version-1
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("prints the status as json", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{bld},
+				Args:    []string{image, "-o", "json"},
+				ExpectedOutput: `{
+  "image": "test-image",
+  "build": "1",
+  "namespace": "some-default-namespace",
+  "status": "SUCCESS",
+  "reasons": [
+    "COMMIT",
+    "BUILDPACK"
+  ],
+  "latestImage": "repo.com/image-1:tag",
+  "started": "2020-10-01T12:00:00Z",
+  "finished": "2020-10-01T12:02:00Z",
+  "duration": "2m0s",
+  "podName": "pod-one",
+  "cacheVolume": "test-image-cache",
+  "builder": "some-repo.com/my-builder",
+  "runImage": "some-repo.com/run-image",
+  "source": {
+    "type": "GitUrl",
+    "url": "https://github.com/some-org/some-repo",
+    "commit": "b5ad3a8cf3f3d5c1d2db2a0d4d5e4c6d0bb6e5f1",
+    "subPath": "app"
+  },
+  "env": [
+    {
+      "name": "BP_JAVA_VERSION",
+      "value": "********"
+    },
+    {
+      "name": "API_TOKEN",
+      "value": "from secret some-secret/token"
+    }
+  ],
+  "steps": [
+    {
+      "name": "prepare",
+      "state": "Succeeded",
+      "started": "2020-10-01T12:00:00Z",
+      "finished": "2020-10-01T12:00:05Z",
+      "duration": "5s"
+    },
+    {
+      "name": "detect",
+      "state": "Succeeded",
+      "started": "2020-10-01T12:00:05Z",
+      "finished": "2020-10-01T12:00:08Z",
+      "duration": "3s"
+    },
+    {
+      "name": "analyze",
+      "state": "Succeeded",
+      "started": "2020-10-01T12:00:08Z",
+      "finished": "2020-10-01T12:00:10Z",
+      "duration": "2s"
+    },
+    {
+      "name": "restore",
+      "state": "Succeeded",
+      "started": "2020-10-01T12:00:10Z",
+      "finished": "2020-10-01T12:00:20Z",
+      "duration": "10s"
+    },
+    {
+      "name": "build",
+      "state": "Succeeded",
+      "started": "2020-10-01T12:00:20Z",
+      "finished": "2020-10-01T12:01:40Z",
+      "duration": "1m20s"
+    },
+    {
+      "name": "export",
+      "state": "Succeeded",
+      "started": "2020-10-01T12:01:40Z",
+      "finished": "2020-10-01T12:01:59Z",
+      "duration": "19s"
+    }
+  ],
+  "buildpacks": [
+    {
+      "id": "bp-id-1",
+      "version": "bp-version-1"
+    }
+  ]
+}
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("prints the status as yaml", func() {
+			bld := bld.DeepCopy()
+			bld.Spec.Env = nil
+			bld.Status.StepStates = bld.Status.StepStates[:1]
+			bld.Status.StepsCompleted = bld.Status.StepsCompleted[:1]
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{bld},
+				Args:    []string{image, "-o", "yaml"},
+				ExpectedOutput: `build: "1"
+builder: some-repo.com/my-builder
+buildpacks:
+- id: bp-id-1
+  version: bp-version-1
+cacheVolume: test-image-cache
+duration: 2m0s
+finished: "2020-10-01T12:02:00Z"
+image: test-image
+latestImage: repo.com/image-1:tag
+namespace: some-default-namespace
+podName: pod-one
+reasons:
+- COMMIT
+- BUILDPACK
+runImage: some-repo.com/run-image
+source:
+  commit: b5ad3a8cf3f3d5c1d2db2a0d4d5e4c6d0bb6e5f1
+  subPath: app
+  type: GitUrl
+  url: https://github.com/some-org/some-repo
+started: "2020-10-01T12:00:00Z"
+status: SUCCESS
+steps:
+- duration: 5s
+  finished: "2020-10-01T12:00:05Z"
+  name: prepare
+  started: "2020-10-01T12:00:00Z"
+  state: Succeeded
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+	})
+
+	when("watch is provided", func() {
+		makeBuild := func(name, number string, created time.Duration) *v1alpha1.Build {
+			return &v1alpha1.Build{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         defaultNamespace,
+					CreationTimestamp: metav1.Time{Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Add(created)},
+					Labels: map[string]string{
+						v1alpha1.ImageLabel:       image,
+						v1alpha1.BuildNumberLabel: number,
+					},
+				},
+			}
+		}
+
+		it("prints a json event for every change to the latest build", func() {
+			buildWatcher = watch.NewFakeWithChanSize(2, false)
+			buildWatcher.Add(makeBuild("build-two", "2", time.Hour))
+			buildWatcher.Modify(makeBuild("build-one", "1", 0))
+			buildWatcher.Stop()
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{makeBuild("build-one", "1", 0)},
+				Args:    []string{image, "--watch", "-o", "json"},
+				ExpectedOutput: `{"type":"ADDED","object":{"kind":"Build","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"build-one","namespace":"some-default-namespace","creationTimestamp":"2020-01-01T00:00:00Z","labels":{"image.kpack.io/buildNumber":"1","image.kpack.io/image":"test-image"}},"spec":{"builder":{},"source":{},"resources":{}},"status":{"stack":{}}}}
+{"type":"ADDED","object":{"kind":"Build","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"build-two","namespace":"some-default-namespace","creationTimestamp":"2020-01-01T01:00:00Z","labels":{"image.kpack.io/buildNumber":"2","image.kpack.io/image":"test-image"}},"spec":{"builder":{},"source":{},"resources":{}},"status":{"stack":{}}}}
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("prints a json event for every change to the requested build", func() {
+			buildWatcher = watch.NewFakeWithChanSize(2, false)
+			buildWatcher.Add(makeBuild("build-two", "2", time.Hour))
+			buildWatcher.Modify(makeBuild("build-one", "1", 0))
+			buildWatcher.Stop()
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{makeBuild("build-one", "1", 0)},
+				Args:    []string{image, "-b", "1", "--watch", "-o", "json"},
+				ExpectedOutput: `{"type":"ADDED","object":{"kind":"Build","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"build-one","namespace":"some-default-namespace","creationTimestamp":"2020-01-01T00:00:00Z","labels":{"image.kpack.io/buildNumber":"1","image.kpack.io/image":"test-image"}},"spec":{"builder":{},"source":{},"resources":{}},"status":{"stack":{}}}}
+{"type":"MODIFIED","object":{"kind":"Build","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"build-one","namespace":"some-default-namespace","creationTimestamp":"2020-01-01T00:00:00Z","labels":{"image.kpack.io/buildNumber":"1","image.kpack.io/image":"test-image"}},"spec":{"builder":{},"source":{},"resources":{}},"status":{"stack":{}}}}
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+	})
+
+	when("watch is provided with the yaml output format", func() {
+		it("returns an error", func() {
+			testhelpers.CommandTest{
+				Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+				Args:           []string{image, "--watch", "-o", "yaml"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: --watch only supports the json output format\n",
+			}.TestKpack(t, cmdFunc)
+		})
+	})
+
+	when("an unsupported output format is provided", func() {
+		it("returns an error", func() {
+			testhelpers.CommandTest{
+				Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+				Args:           []string{image, "-o", "table"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: unsupported output format: \"table\", supported formats are json, yaml\n",
+			}.TestKpack(t, cmdFunc)
+		})
+	})
 }
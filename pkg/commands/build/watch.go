@@ -0,0 +1,72 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"sort"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+// watchBuilds calls onChange with the sorted builds matching a selector, first
+// for the listed builds and then for every change reported by a watch started
+// at the list resource version. The event is nil for the initial call.
+func watchBuilds(cmd *cobra.Command, cs k8s.ClientSet, namespace string, buildList *v1alpha1.BuildList, selector string, onChange func(builds []v1alpha1.Build, event *watch.Event) error) error {
+	w, err := cs.KpackClient.KpackV1alpha1().Builds(namespace).Watch(metav1.ListOptions{
+		LabelSelector:   selector,
+		ResourceVersion: buildList.ResourceVersion,
+	})
+	if err != nil {
+		return err
+	}
+
+	builds := map[string]v1alpha1.Build{}
+	for _, bld := range buildList.Items {
+		builds[buildKey(bld)] = bld
+	}
+
+	sorted := func() []v1alpha1.Build {
+		var items []v1alpha1.Build
+		for _, bld := range builds {
+			items = append(items, bld)
+		}
+		sort.Slice(items, build.Sort(items))
+		return items
+	}
+
+	if err := onChange(sorted(), nil); err != nil {
+		return err
+	}
+
+	return commands.Watch(cmd.Context(), w, func(event watch.Event) error {
+		bld, ok := event.Object.(*v1alpha1.Build)
+		if !ok {
+			return nil
+		}
+
+		if event.Type == watch.Deleted {
+			delete(builds, buildKey(*bld))
+		} else {
+			builds[buildKey(*bld)] = *bld
+		}
+		return onChange(sorted(), &event)
+	})
+}
+
+func buildKey(bld v1alpha1.Build) string {
+	return bld.Namespace + "/" + bld.Name
+}
+
+func setBuildTypeMeta(bld *v1alpha1.Build) *v1alpha1.Build {
+	bld.Kind = "Build"
+	bld.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	return bld
+}
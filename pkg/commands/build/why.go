@@ -0,0 +1,291 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+// buildConfig is the configuration of an image recorded on a build, a change
+// in it causes a CONFIG build.
+type buildConfig struct {
+	Tag            string                      `json:"tag"`
+	ServiceAccount string                      `json:"serviceAccount,omitempty"`
+	Source         v1alpha1.SourceConfig       `json:"source"`
+	Env            []buildStatusEnv            `json:"env,omitempty"`
+	Resources      corev1.ResourceRequirements `json:"resources,omitempty"`
+	Bindings       v1alpha1.Bindings           `json:"bindings,omitempty"`
+}
+
+func NewWhyCommand(clientSetProvider k8s.ClientSetProvider, differ Differ) *cobra.Command {
+	var (
+		namespace   string
+		buildNumber string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "why <image-name>",
+		Short: "Explain the reasons of an image build",
+		Long: `Prints why a build of an image in the provided namespace was started.
+
+Every build reason is explained by comparing the build with the build before it:
+COMMIT shows the previous and new source revision, CONFIG shows a diff of the image configuration,
+BUILDPACK shows the buildpacks that were added, removed or changed and STACK shows the run image change.
+Env var values are masked, a changed value is marked as changed.
+
+The build defaults to the latest build number.
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build why my-image\nkp build why my-image -b 2 -n my-namespace",
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
+				LabelSelector: v1alpha1.ImageLabel + "=" + args[0],
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(buildList.Items) == 0 {
+				return errors.New("no builds found")
+			}
+
+			sort.Slice(buildList.Items, build.Sort(buildList.Items))
+			bld, err := findBuild(buildList.Items, buildNumber)
+			if err != nil {
+				return err
+			}
+
+			var previous []v1alpha1.Build
+			for _, b := range buildList.Items {
+				if b.Name == bld.Name {
+					break
+				}
+				previous = append(previous, b)
+			}
+
+			return explainBuild(cmd.OutOrStdout(), differ, args[0], bld, previous)
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+
+	return cmd
+}
+
+// explainBuild writes a section for every reason of a build. The previous
+// builds are ordered oldest first.
+func explainBuild(out io.Writer, differ Differ, image string, bld v1alpha1.Build, previous []v1alpha1.Build) error {
+	number := bld.Labels[v1alpha1.BuildNumberLabel]
+	reasons := build.Reasons(bld)
+	if len(reasons) == 0 {
+		_, err := fmt.Fprintf(out, "Build %q of Image %q has no build reason\n", number, image)
+		return err
+	}
+
+	_, err := fmt.Fprintf(out, "Build %q of Image %q was built because of: %s\n", number, image, strings.Join(reasons, ", "))
+	if err != nil {
+		return err
+	}
+
+	if len(previous) == 0 {
+		_, err := fmt.Fprintln(out, "\nIt is the first build of the image, there is no earlier build to compare with")
+		return err
+	}
+
+	for _, reason := range reasons {
+		var lines []string
+		switch reason {
+		case v1alpha1.BuildReasonCommit:
+			lines = explainCommit(bld, previous)
+		case v1alpha1.BuildReasonConfig:
+			lines, err = explainConfig(differ, bld, previous)
+			if err != nil {
+				return err
+			}
+		case v1alpha1.BuildReasonBuildpack:
+			lines = explainBuildpack(bld, previous)
+		case v1alpha1.BuildReasonStack:
+			lines = explainStack(bld, previous)
+		case v1alpha1.BuildReasonTrigger:
+			lines = []string{`The build was requested manually, e.g. with "kp image trigger"`}
+		default:
+			lines = []string{"No explanation is available for this reason"}
+		}
+
+		if _, err := fmt.Fprintf(out, "\n%s\n%s\n", reason, strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func explainCommit(bld v1alpha1.Build, previous []v1alpha1.Build) []string {
+	last := previous[len(previous)-1]
+	lastNumber := last.Labels[v1alpha1.BuildNumberLabel]
+
+	oldRevision, newRevision := sourceRevision(last.Spec.Source), sourceRevision(bld.Spec.Source)
+	if oldRevision == newRevision {
+		return []string{fmt.Sprintf("No source change found compared to build %q", lastNumber)}
+	}
+
+	return []string{
+		fmt.Sprintf("Source changed since build %q:", lastNumber),
+		fmt.Sprintf("  %s -> %s", orNone(oldRevision), orNone(newRevision)),
+	}
+}
+
+// sourceRevision returns the git commit, blob url or source image of a build.
+func sourceRevision(source v1alpha1.SourceConfig) string {
+	s := newBuildStatusSource(source)
+	switch {
+	case s.Commit != "":
+		return s.Commit
+	case s.Image != "":
+		return s.Image
+	default:
+		return s.Url
+	}
+}
+
+func explainConfig(differ Differ, bld v1alpha1.Build, previous []v1alpha1.Build) ([]string, error) {
+	last := previous[len(previous)-1]
+	lastNumber := last.Labels[v1alpha1.BuildNumberLabel]
+
+	oldConfig := newBuildConfig(last, nil)
+	newConfig := newBuildConfig(bld, last.Spec.Env)
+	if reflect.DeepEqual(oldConfig, newConfig) {
+		return []string{fmt.Sprintf("No configuration change found compared to build %q", lastNumber)}, nil
+	}
+
+	diff, err := differ.Diff(oldConfig, newConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{
+		fmt.Sprintf("Configuration changed since build %q:", lastNumber),
+		strings.TrimSuffix(diff, "\n"),
+	}, nil
+}
+
+// newBuildConfig returns the configuration of a build. Env var values that
+// changed since the previous env are marked.
+func newBuildConfig(bld v1alpha1.Build, previousEnv []corev1.EnvVar) buildConfig {
+	config := buildConfig{
+		ServiceAccount: bld.Spec.ServiceAccount,
+		Source:         *bld.Spec.Source.DeepCopy(),
+		Env:            newChangedEnv(previousEnv, bld.Spec.Env),
+		Resources:      bld.Spec.Resources,
+		Bindings:       bld.Spec.Bindings,
+	}
+
+	// the other tags contain the build number and always change
+	if len(bld.Spec.Tags) > 0 {
+		config.Tag = bld.Spec.Tags[0]
+	}
+
+	// a revision change is a COMMIT, not a CONFIG change
+	if config.Source.Git != nil {
+		config.Source.Git.Revision = ""
+	}
+
+	return config
+}
+
+func explainBuildpack(bld v1alpha1.Build, previous []v1alpha1.Build) []string {
+	if len(bld.Status.BuildMetadata) == 0 {
+		return []string{"The buildpacks of the build are not known until the build succeeds"}
+	}
+
+	last, ok := lastBuildWith(previous, func(b v1alpha1.Build) bool {
+		return len(b.Status.BuildMetadata) > 0
+	})
+	if !ok {
+		return []string{"No earlier build with known buildpacks to compare with"}
+	}
+	lastNumber := last.Labels[v1alpha1.BuildNumberLabel]
+
+	changes := build.DiffBuildpacks(last.Status.BuildMetadata, bld.Status.BuildMetadata)
+	if len(changes) == 0 {
+		return []string{fmt.Sprintf("No buildpack change found compared to build %q", lastNumber)}
+	}
+
+	lines := []string{fmt.Sprintf("Buildpacks changed since build %q:", lastNumber)}
+	for _, c := range changes {
+		lines = append(lines, "  "+c.String())
+	}
+	return lines
+}
+
+func explainStack(bld v1alpha1.Build, previous []v1alpha1.Build) []string {
+	if bld.Status.Stack.RunImage == "" {
+		return []string{"The run image of the build is not known until the build succeeds"}
+	}
+
+	last, ok := lastBuildWith(previous, func(b v1alpha1.Build) bool {
+		return b.Status.Stack.RunImage != ""
+	})
+	if !ok {
+		return []string{"No earlier build with a known run image to compare with"}
+	}
+	lastNumber := last.Labels[v1alpha1.BuildNumberLabel]
+
+	var lines []string
+	if last.Status.Stack.RunImage != bld.Status.Stack.RunImage {
+		lines = append(lines,
+			fmt.Sprintf("Run image changed since build %q:", lastNumber),
+			fmt.Sprintf("  %s -> %s", last.Status.Stack.RunImage, bld.Status.Stack.RunImage),
+		)
+	}
+
+	if last.Status.Stack.ID != bld.Status.Stack.ID {
+		lines = append(lines,
+			fmt.Sprintf("Stack changed since build %q:", lastNumber),
+			fmt.Sprintf("  %s -> %s", orNone(last.Status.Stack.ID), orNone(bld.Status.Stack.ID)),
+		)
+	}
+
+	if len(lines) == 0 {
+		return []string{fmt.Sprintf("No run image change found compared to build %q", lastNumber)}
+	}
+	return lines
+}
+
+// lastBuildWith returns the most recent build that matches.
+func lastBuildWith(builds []v1alpha1.Build, matches func(v1alpha1.Build) bool) (v1alpha1.Build, bool) {
+	for i := len(builds) - 1; i >= 0; i-- {
+		if matches(builds[i]) {
+			return builds[i], true
+		}
+	}
+	return v1alpha1.Build{}, false
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
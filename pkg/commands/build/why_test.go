@@ -0,0 +1,223 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	commandsfakes "github.com/pivotal/build-service-cli/pkg/commands/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildWhyCommand(t *testing.T) {
+	spec.Run(t, "TestBuildWhyCommand", testBuildWhyCommand)
+}
+
+func testBuildWhyCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+	)
+
+	var fakeDiffer *commandsfakes.FakeDiffer
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return build.NewWhyCommand(clientSetProvider, fakeDiffer)
+	}
+
+	makeBuild := func(number string, created time.Duration, reasons string) *v1alpha1.Build {
+		return &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "build-" + number,
+				Namespace:         defaultNamespace,
+				CreationTimestamp: metav1.Time{Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Add(created)},
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       image,
+					v1alpha1.BuildNumberLabel: number,
+				},
+				Annotations: map[string]string{
+					v1alpha1.BuildReasonAnnotation: reasons,
+				},
+			},
+			Spec: v1alpha1.BuildSpec{
+				Tags:    []string{"some-repo.com/app", "some-repo.com/app:b" + number},
+				Builder: v1alpha1.BuildBuilderSpec{Image: "some-repo.com/builder@sha256:123"},
+				Source: v1alpha1.SourceConfig{
+					Git: &v1alpha1.Git{URL: "https://github.com/some-org/some-repo", Revision: "sha-one"},
+				},
+				Env: []corev1.EnvVar{
+					{Name: "BP_JAVA_VERSION", Value: "11"},
+				},
+			},
+			Status: v1alpha1.BuildStatus{
+				Stack: v1alpha1.BuildStack{RunImage: "some-repo.com/run@sha256:456", ID: "io.buildpacks.stacks.bionic"},
+				BuildMetadata: v1alpha1.BuildpackMetadataList{
+					{Id: "bp-id-1", Version: "1.0.0"},
+					{Id: "bp-id-2", Version: "2.0.0"},
+				},
+			},
+		}
+	}
+
+	build1 := makeBuild("1", 0, "CONFIG")
+
+	build2 := makeBuild("2", time.Hour, "COMMIT,CONFIG")
+	build2.Spec.Source.Git.Revision = "sha-two"
+	build2.Spec.Env[0].Value = "14"
+
+	build3 := makeBuild("3", 2*time.Hour, "BUILDPACK,STACK")
+	build3.Spec.Source.Git.Revision = "sha-two"
+	build3.Spec.Env[0].Value = "14"
+	build3.Status.Stack.RunImage = "some-repo.com/run@sha256:789"
+	build3.Status.BuildMetadata = v1alpha1.BuildpackMetadataList{
+		{Id: "bp-id-1", Version: "1.1.0"},
+		{Id: "bp-id-3", Version: "3.0.0"},
+	}
+
+	it.Before(func() {
+		fakeDiffer = &commandsfakes.FakeDiffer{DiffResult: "some-diff\n"}
+	})
+
+	it("explains the commit and config changes compared to the previous build", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{build1, build2, build3},
+			Args:    []string{image, "-b", "2"},
+			ExpectedOutput: `Build "2" of Image "test-image" was built because of: COMMIT, CONFIG
+
+COMMIT
+Source changed since build "1":
+  sha-one -> sha-two
+
+CONFIG
+Configuration changed since build "1":
+some-diff
+`,
+		}.TestKpack(t, cmdFunc)
+
+		_, newArg := fakeDiffer.Args()
+		newData, err := yaml.Marshal(newArg)
+		require.NoError(t, err)
+		require.Equal(t, `env:
+- name: BP_JAVA_VERSION
+  value: '******** (changed)'
+resources: {}
+source:
+  git:
+    revision: ""
+    url: https://github.com/some-org/some-repo
+tag: some-repo.com/app
+`, string(newData))
+	})
+
+	it("explains the buildpack and stack changes of the latest build", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{build1, build2, build3},
+			Args:    []string{image},
+			ExpectedOutput: `Build "3" of Image "test-image" was built because of: BUILDPACK, STACK
+
+BUILDPACK
+Buildpacks changed since build "2":
+  bp-id-1 1.0.0 -> 1.1.0
+  -bp-id-2 2.0.0
+  +bp-id-3 3.0.0
+
+STACK
+Run image changed since build "2":
+  some-repo.com/run@sha256:456 -> some-repo.com/run@sha256:789
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("compares with the last build with known buildpacks and run image", func() {
+		running := makeBuild("3", 2*time.Hour, "BUILDPACK")
+		running.Status = v1alpha1.BuildStatus{}
+
+		build4 := makeBuild("4", 3*time.Hour, "BUILDPACK,STACK")
+		build4.Status.Stack.RunImage = "some-repo.com/run@sha256:789"
+		build4.Status.BuildMetadata[1].Version = "2.1.0"
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{build1, running, build4},
+			Args:    []string{image, "-b", "4"},
+			ExpectedOutput: `Build "4" of Image "test-image" was built because of: BUILDPACK, STACK
+
+BUILDPACK
+Buildpacks changed since build "1":
+  bp-id-2 2.0.0 -> 2.1.0
+
+STACK
+Run image changed since build "1":
+  some-repo.com/run@sha256:456 -> some-repo.com/run@sha256:789
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("explains builds that are not finished and have no changes", func() {
+		running := makeBuild("2", time.Hour, "CONFIG,BUILDPACK,STACK,TRIGGER")
+		running.Status = v1alpha1.BuildStatus{}
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{build1, running},
+			Args:    []string{image},
+			ExpectedOutput: `Build "2" of Image "test-image" was built because of: CONFIG, BUILDPACK, STACK, TRIGGER
+
+CONFIG
+No configuration change found compared to build "1"
+
+BUILDPACK
+The buildpacks of the build are not known until the build succeeds
+
+STACK
+The run image of the build is not known until the build succeeds
+
+TRIGGER
+The build was requested manually, e.g. with "kp image trigger"
+`,
+		}.TestKpack(t, cmdFunc)
+
+		oldArg, _ := fakeDiffer.Args()
+		require.Nil(t, oldArg)
+	})
+
+	it("explains the first build of an image", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{build1, build2},
+			Args:    []string{image, "-b", "1"},
+			ExpectedOutput: `Build "1" of Image "test-image" was built because of: CONFIG
+
+It is the first build of the image, there is no earlier build to compare with
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error when the build is not found", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{build1},
+			Args:           []string{image, "-b", "5"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: build \"5\" not found\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error when no builds are found", func() {
+		testhelpers.CommandTest{
+			Args:           []string{image},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: no builds found\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
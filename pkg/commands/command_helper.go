@@ -183,6 +183,14 @@ func (ch CommandHelper) Writer() io.Writer {
 	return ch.OutOrErrWriter()
 }
 
+// WithWriter returns a copy of the helper that writes all output to w, for
+// example to collect the output of concurrent operations.
+func (ch CommandHelper) WithWriter(w io.Writer) *CommandHelper {
+	ch.outWriter = w
+	ch.errWriter = w
+	return &ch
+}
+
 func GetBoolFlag(name string, cmd *cobra.Command) (bool, error) {
 	flag := cmd.Flags().Lookup(name)
 	if flag == nil {
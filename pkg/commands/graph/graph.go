@@ -0,0 +1,262 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/graph"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+const (
+	outputTree = "tree"
+	outputDot  = "dot"
+)
+
+type jsonGraph struct {
+	Nodes []jsonNode `json:"nodes"`
+	Edges []jsonEdge `json:"edges"`
+}
+
+type jsonNode struct {
+	ID             string `json:"id"`
+	Kind           string `json:"kind"`
+	Namespace      string `json:"namespace,omitempty"`
+	Name           string `json:"name"`
+	Missing        bool   `json:"missing,omitempty"`
+	AffectedImages int    `json:"affectedImages"`
+}
+
+type jsonEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func NewGraphCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	var (
+		clusterStack string
+		clusterStore string
+		output       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Display the dependency graph of images",
+		Long: `Prints the graph of resources that images depend on, across all namespaces:
+cluster stacks and cluster stores, the builders and cluster builders that use them, and the images built by those builders.
+Each node shows the number of images that are rebuilt when it changes.
+
+The "--clusterstack" and "--clusterstore" flags limit the graph to the dependents of a single resource,
+answering which images will rebuild when it is updated.
+
+The graph is printed as a tree by default. Use "--output json" for a list of nodes and edges
+or "--output dot" for a Graphviz DOT graph.`,
+		Example: `kp graph
+kp graph --clusterstore default
+kp graph --clusterstack base --output json
+kp graph --output dot | dot -Tsvg > graph.svg`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch output {
+			case outputTree, k8s.FormatJSON, outputDot:
+			default:
+				return errors.Errorf("unsupported output format: %q, supported formats are tree, json, dot", output)
+			}
+
+			if clusterStack != "" && clusterStore != "" {
+				return errors.New("only one of clusterstack or clusterstore can be provided")
+			}
+
+			cs, err := clientSetProvider.GetClientSet("")
+			if err != nil {
+				return err
+			}
+
+			g, err := buildGraph(cs)
+			if err != nil {
+				return err
+			}
+
+			roots := g.Roots()
+			if clusterStack != "" {
+				roots, err = findRoot(g, v1alpha1.ClusterStackKind, clusterStack)
+			} else if clusterStore != "" {
+				roots, err = findRoot(g, v1alpha1.ClusterStoreKind, clusterStore)
+			}
+			if err != nil {
+				return err
+			}
+
+			switch output {
+			case k8s.FormatJSON:
+				return writeJSON(cmd.OutOrStdout(), roots)
+			case outputDot:
+				return writeDot(cmd.OutOrStdout(), roots)
+			default:
+				return writeTree(cmd.OutOrStdout(), roots)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&clusterStack, "clusterstack", "", "only show the dependents of this cluster stack")
+	cmd.Flags().StringVar(&clusterStore, "clusterstore", "", "only show the dependents of this cluster store")
+	cmd.Flags().StringVarP(&output, "output", "o", outputTree, "output format, supported formats are: tree, json, dot")
+	return cmd
+}
+
+func buildGraph(cs k8s.ClientSet) (*graph.Graph, error) {
+	client := cs.KpackClient.KpackV1alpha1()
+
+	stacks, err := client.ClusterStacks().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	stores, err := client.ClusterStores().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	clusterBuilders, err := client.ClusterBuilders().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	builders, err := client.Builders(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := client.Images(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return graph.New(stacks.Items, stores.Items, clusterBuilders.Items, builders.Items, images.Items), nil
+}
+
+func findRoot(g *graph.Graph, kind, name string) ([]*graph.Node, error) {
+	node := g.Find(kind, "", name)
+	if node == nil {
+		return nil, errors.Errorf("%s %q not found", kind, name)
+	}
+	return []*graph.Node{node}, nil
+}
+
+func writeTree(out io.Writer, roots []*graph.Node) error {
+	for _, root := range roots {
+		if _, err := fmt.Fprintln(out, nodeText(root)); err != nil {
+			return err
+		}
+
+		if err := writeDependents(out, root, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeDependents(out io.Writer, node *graph.Node, prefix string) error {
+	for i, dependent := range node.Dependents {
+		branch, indent := "├── ", "│   "
+		if i == len(node.Dependents)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		if _, err := fmt.Fprintf(out, "%s%s%s\n", prefix, branch, nodeText(dependent)); err != nil {
+			return err
+		}
+
+		if err := writeDependents(out, dependent, prefix+indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(out io.Writer, roots []*graph.Node) error {
+	g := jsonGraph{Nodes: []jsonNode{}, Edges: []jsonEdge{}}
+
+	for _, node := range graph.Reachable(roots) {
+		g.Nodes = append(g.Nodes, jsonNode{
+			ID:             node.ID(),
+			Kind:           node.Kind,
+			Namespace:      node.Namespace,
+			Name:           node.Name,
+			Missing:        node.Missing,
+			AffectedImages: node.AffectedImages(),
+		})
+
+		for _, dependent := range node.Dependents {
+			g.Edges = append(g.Edges, jsonEdge{From: node.ID(), To: dependent.ID()})
+		}
+	}
+
+	buf, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, string(buf))
+	return err
+}
+
+func writeDot(out io.Writer, roots []*graph.Node) error {
+	b := &strings.Builder{}
+	b.WriteString("digraph kpack {\n  rankdir=LR;\n")
+
+	nodes := graph.Reachable(roots)
+	for _, node := range nodes {
+		label := node.ID()
+		if node.Kind != graph.ImageKind {
+			label = fmt.Sprintf("%s\n%s", label, imageCount(node.AffectedImages()))
+		}
+
+		style := ""
+		if node.Missing {
+			style = ", style=dashed"
+		}
+
+		fmt.Fprintf(b, "  %q [label=%q%s];\n", node.ID(), label, style)
+	}
+
+	for _, node := range nodes {
+		for _, dependent := range node.Dependents {
+			fmt.Fprintf(b, "  %q -> %q;\n", node.ID(), dependent.ID())
+		}
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func nodeText(node *graph.Node) string {
+	text := node.ID()
+	if node.Missing {
+		text += " (not found)"
+	}
+
+	if node.Kind != graph.ImageKind {
+		text += fmt.Sprintf(" (%s)", imageCount(node.AffectedImages()))
+	}
+	return text
+}
+
+func imageCount(count int) string {
+	if count == 1 {
+		return "1 image"
+	}
+	return fmt.Sprintf("%d images", count)
+}
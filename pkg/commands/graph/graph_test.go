@@ -0,0 +1,231 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/graph"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestGraphCommand(t *testing.T) {
+	spec.Run(t, "TestGraphCommand", testGraphCommand)
+}
+
+func testGraphCommand(t *testing.T, when spec.G, it spec.S) {
+	const defaultNamespace = "some-default-namespace"
+
+	stack := &v1alpha1.ClusterStack{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-stack"},
+	}
+
+	store := &v1alpha1.ClusterStore{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-store"},
+	}
+
+	builderSpec := v1alpha1.BuilderSpec{
+		Stack: corev1.ObjectReference{Kind: v1alpha1.ClusterStackKind, Name: "some-stack"},
+		Store: corev1.ObjectReference{Kind: v1alpha1.ClusterStoreKind, Name: "some-store"},
+	}
+
+	clusterBuilder := &v1alpha1.ClusterBuilder{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-cluster-builder"},
+		Spec:       v1alpha1.ClusterBuilderSpec{BuilderSpec: builderSpec},
+	}
+
+	builder := &v1alpha1.Builder{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-builder", Namespace: "team-a"},
+		Spec:       v1alpha1.NamespacedBuilderSpec{BuilderSpec: builderSpec},
+	}
+
+	makeImage := func(namespace, name string, builderRef corev1.ObjectReference) *v1alpha1.Image {
+		return &v1alpha1.Image{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       v1alpha1.ImageSpec{Builder: builderRef},
+		}
+	}
+
+	objects := []runtime.Object{
+		stack,
+		store,
+		clusterBuilder,
+		builder,
+		makeImage("team-a", "app", corev1.ObjectReference{Kind: v1alpha1.ClusterBuilderKind, Name: "some-cluster-builder"}),
+		makeImage("team-b", "api", corev1.ObjectReference{Kind: v1alpha1.ClusterBuilderKind, Name: "some-cluster-builder"}),
+		makeImage("team-a", "worker", corev1.ObjectReference{Kind: v1alpha1.BuilderKind, Name: "some-builder"}),
+		makeImage("team-b", "legacy", corev1.ObjectReference{Kind: v1alpha1.ClusterBuilderKind, Name: "old-cluster-builder"}),
+	}
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return graph.NewGraphCommand(clientSetProvider)
+	}
+
+	it("prints the dependency graph as a tree", func() {
+		testhelpers.CommandTest{
+			Objects: objects,
+			ExpectedOutput: `ClusterStack/some-stack (3 images)
+├── ClusterBuilder/some-cluster-builder (2 images)
+│   ├── Image/team-a/app
+│   └── Image/team-b/api
+└── Builder/team-a/some-builder (1 image)
+    └── Image/team-a/worker
+ClusterStore/some-store (3 images)
+├── ClusterBuilder/some-cluster-builder (2 images)
+│   ├── Image/team-a/app
+│   └── Image/team-b/api
+└── Builder/team-a/some-builder (1 image)
+    └── Image/team-a/worker
+ClusterBuilder/old-cluster-builder (not found) (1 image)
+└── Image/team-b/legacy
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("limits the graph to the dependents of a cluster store", func() {
+		testhelpers.CommandTest{
+			Objects: objects,
+			Args:    []string{"--clusterstore", "some-store"},
+			ExpectedOutput: `ClusterStore/some-store (3 images)
+├── ClusterBuilder/some-cluster-builder (2 images)
+│   ├── Image/team-a/app
+│   └── Image/team-b/api
+└── Builder/team-a/some-builder (1 image)
+    └── Image/team-a/worker
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("prints the graph as json", func() {
+		testhelpers.CommandTest{
+			Objects: objects,
+			Args:    []string{"--clusterstack", "some-stack", "-o", "json"},
+			ExpectedOutput: `{
+  "nodes": [
+    {
+      "id": "ClusterStack/some-stack",
+      "kind": "ClusterStack",
+      "name": "some-stack",
+      "affectedImages": 3
+    },
+    {
+      "id": "ClusterBuilder/some-cluster-builder",
+      "kind": "ClusterBuilder",
+      "name": "some-cluster-builder",
+      "affectedImages": 2
+    },
+    {
+      "id": "Builder/team-a/some-builder",
+      "kind": "Builder",
+      "namespace": "team-a",
+      "name": "some-builder",
+      "affectedImages": 1
+    },
+    {
+      "id": "Image/team-a/app",
+      "kind": "Image",
+      "namespace": "team-a",
+      "name": "app",
+      "affectedImages": 1
+    },
+    {
+      "id": "Image/team-a/worker",
+      "kind": "Image",
+      "namespace": "team-a",
+      "name": "worker",
+      "affectedImages": 1
+    },
+    {
+      "id": "Image/team-b/api",
+      "kind": "Image",
+      "namespace": "team-b",
+      "name": "api",
+      "affectedImages": 1
+    }
+  ],
+  "edges": [
+    {
+      "from": "ClusterStack/some-stack",
+      "to": "ClusterBuilder/some-cluster-builder"
+    },
+    {
+      "from": "ClusterStack/some-stack",
+      "to": "Builder/team-a/some-builder"
+    },
+    {
+      "from": "ClusterBuilder/some-cluster-builder",
+      "to": "Image/team-a/app"
+    },
+    {
+      "from": "ClusterBuilder/some-cluster-builder",
+      "to": "Image/team-b/api"
+    },
+    {
+      "from": "Builder/team-a/some-builder",
+      "to": "Image/team-a/worker"
+    }
+  ]
+}
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("prints the graph as graphviz dot", func() {
+		testhelpers.CommandTest{
+			Objects: objects,
+			Args:    []string{"--clusterstore", "some-store", "-o", "dot"},
+			ExpectedOutput: `digraph kpack {
+  rankdir=LR;
+  "ClusterStore/some-store" [label="ClusterStore/some-store\n3 images"];
+  "ClusterBuilder/some-cluster-builder" [label="ClusterBuilder/some-cluster-builder\n2 images"];
+  "Builder/team-a/some-builder" [label="Builder/team-a/some-builder\n1 image"];
+  "Image/team-a/app" [label="Image/team-a/app"];
+  "Image/team-a/worker" [label="Image/team-a/worker"];
+  "Image/team-b/api" [label="Image/team-b/api"];
+  "ClusterStore/some-store" -> "ClusterBuilder/some-cluster-builder";
+  "ClusterStore/some-store" -> "Builder/team-a/some-builder";
+  "ClusterBuilder/some-cluster-builder" -> "Image/team-a/app";
+  "ClusterBuilder/some-cluster-builder" -> "Image/team-b/api";
+  "Builder/team-a/some-builder" -> "Image/team-a/worker";
+}
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when the cluster store does not exist", func() {
+		testhelpers.CommandTest{
+			Objects:        objects,
+			Args:           []string{"--clusterstore", "other-store"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: ClusterStore \"other-store\" not found\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when both a cluster stack and a cluster store are provided", func() {
+		testhelpers.CommandTest{
+			Objects:        objects,
+			Args:           []string{"--clusterstack", "some-stack", "--clusterstore", "some-store"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: only one of clusterstack or clusterstore can be provided\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors on an unsupported output format", func() {
+		testhelpers.CommandTest{
+			Objects:        objects,
+			Args:           []string{"-o", "yaml"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: unsupported output format: \"yaml\", supported formats are tree, json, dot\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
@@ -0,0 +1,178 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+const defaultBulkConcurrency = 10
+
+type ConfirmationProvider interface {
+	Confirm(message string, okayResponses ...string) (bool, error)
+}
+
+type bulkOptions struct {
+	selector      string
+	allNamespaces bool
+	concurrency   int
+	force         bool
+}
+
+type bulkResult struct {
+	namespace string
+	name      string
+	result    string
+	err       error
+}
+
+func setBulkFlags(cmd *cobra.Command, opts *bulkOptions) {
+	cmd.Flags().StringVarP(&opts.selector, "selector", "l", "", "label selector to select images (e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVarP(&opts.allNamespaces, "all-namespaces", "A", false, "select images across all namespaces")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", defaultBulkConcurrency, "maximum number of images processed at once when selecting multiple images")
+	cmd.Flags().BoolVarP(&opts.force, "force", "f", false, "skip confirmation when selecting multiple images")
+}
+
+func (o bulkOptions) isBulk() bool {
+	return o.selector != "" || o.allNamespaces
+}
+
+func imageNameOrSelectorArgs(opts *bulkOptions) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if opts.isBulk() {
+			if len(args) != 0 {
+				return fmt.Errorf("image name cannot be provided with --selector or --all-namespaces\n\n%s", cmd.UsageString())
+			}
+			return nil
+		}
+		return commands.ExactArgsWithUsage(1)(cmd, args)
+	}
+}
+
+func listSelectedImages(cs k8s.ClientSet, opts bulkOptions) ([]v1alpha1.Image, error) {
+	namespace := cs.Namespace
+	if opts.allNamespaces {
+		namespace = metav1.NamespaceAll
+	}
+
+	imageList, err := cs.KpackClient.KpackV1alpha1().Images(namespace).List(metav1.ListOptions{
+		LabelSelector: opts.selector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(imageList.Items) == 0 {
+		return nil, errors.New("no images found")
+	}
+
+	images := imageList.Items
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Namespace != images[j].Namespace {
+			return images[i].Namespace < images[j].Namespace
+		}
+		return images[i].Name < images[j].Name
+	})
+	return images, nil
+}
+
+func confirmBulk(out io.Writer, confirmationProvider ConfirmationProvider, opts bulkOptions, images []v1alpha1.Image, action string) (bool, error) {
+	if opts.force {
+		return true, nil
+	}
+
+	_, err := fmt.Fprintf(out, "The following images were selected for %s:\n", action)
+	if err != nil {
+		return false, err
+	}
+
+	writer, err := commands.NewTableWriter(out, "Namespace", "Name")
+	if err != nil {
+		return false, err
+	}
+
+	for _, img := range images {
+		if err := writer.AddRow(img.Namespace, img.Name); err != nil {
+			return false, err
+		}
+	}
+
+	if err := writer.Write(); err != nil {
+		return false, err
+	}
+
+	message := fmt.Sprintf("Please confirm %s of %d image(s) by typing 'y': ", action, len(images))
+	return confirmationProvider.Confirm(message)
+}
+
+func runBulk(images []v1alpha1.Image, concurrency int, op func(i int, img v1alpha1.Image) (string, error)) []bulkResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]bulkResult, len(images))
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+
+	for i := range images {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := op(i, images[i])
+			results[i] = bulkResult{
+				namespace: images[i].Namespace,
+				name:      images[i].Name,
+				result:    result,
+				err:       err,
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func displayBulkResults(out io.Writer, results []bulkResult, action string) error {
+	writer, err := commands.NewTableWriter(out, "Namespace", "Name", "Result")
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		result := r.result
+		if r.err != nil {
+			failed++
+			result = fmt.Sprintf("error: %s", r.err)
+		}
+
+		if err := writer.AddRow(r.namespace, r.name, result); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Write(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%s failed for %d of %d image(s)", action, failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/git"
+	"github.com/pivotal/build-service-cli/pkg/image"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+func NewBumpCommand(clientSetProvider k8s.ClientSetProvider, newImageWaiter func(k8s.ClientSet) ImageWaiter, newGitValidator func(k8s.ClientSet) image.GitValidator) *cobra.Command {
+	var (
+		namespace string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bump <name>",
+		Short: "Update a pinned git revision to the latest commit",
+		Long: `Update the pinned git revision of an image to the commit its branch or tag currently points to.
+
+The image must have been created or patched with "--pin-revision".
+The range of commits between the previous and the new revision is printed with a link to compare it
+for repositories on github.com or gitlab.com, and with the git commands to list it otherwise.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example: `kp image bump my-image
+kp image bump my-image --wait
+kp image bump my-image --dry-run`,
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			ref := img.Annotations[image.PinnedRevisionAnnotation]
+			if img.Spec.Source.Git == nil || ref == "" {
+				return errors.Errorf("image %q does not have a pinned git revision", img.Name)
+			}
+
+			if err := ch.PrintStatus("Bumping Image..."); err != nil {
+				return err
+			}
+
+			sha, err := newGitValidator(cs).ResolveRevision(cs.Namespace, img.Spec.ServiceAccount, img.Spec.Source.Git.URL, ref)
+			if err != nil {
+				return err
+			}
+
+			oldSha := img.Spec.Source.Git.Revision
+			hasChange := sha != oldSha
+
+			if hasChange {
+				if err := printCommitRange(ch, img.Spec.Source.Git.URL, ref, oldSha, sha); err != nil {
+					return err
+				}
+			} else if err := ch.Printlnf("Git revision %q is up to date at %s", ref, sha); err != nil {
+				return err
+			}
+
+			bumpedImg := img.DeepCopy()
+			bumpedImg.Spec.Source.Git.Revision = sha
+
+			if hasChange && !ch.IsDryRun() {
+				patch, err := k8s.CreatePatch(img, bumpedImg)
+				if err != nil {
+					return err
+				}
+
+				bumpedImg, err = cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Patch(img.Name, types.MergePatchType, patch)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := ch.PrintObj(bumpedImg); err != nil {
+				return err
+			}
+
+			if err := ch.PrintChangeResult(hasChange, "Image %q bumped", img.Name); err != nil {
+				return err
+			}
+
+			if hasChange && ch.ShouldWait() {
+				_, err = newImageWaiter(cs).Wait(cmd.Context(), cmd.OutOrStdout(), bumpedImg)
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().BoolP("wait", "w", false, "wait for image bump to be reconciled and tail resulting build logs")
+	commands.SetDryRunOutputFlags(cmd)
+	return cmd
+}
+
+func printCommitRange(ch *commands.CommandHelper, url, ref, oldSha, newSha string) error {
+	if err := ch.Printlnf("Git revision %q: %s..%s", ref, oldSha, newSha); err != nil {
+		return err
+	}
+
+	if compareURL := git.CompareURL(url, oldSha, newSha); compareURL != "" {
+		return ch.Printlnf("Compare the commits at %s", compareURL)
+	}
+	return ch.Printlnf("List the commits with: git fetch %s %s && git log --oneline %s..%s", url, ref, oldSha, newSha)
+}
@@ -0,0 +1,169 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	imgcmds "github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/image"
+	"github.com/pivotal/build-service-cli/pkg/image/fakes"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestImageBumpCommand(t *testing.T) {
+	spec.Run(t, "TestImageBumpCommand", testImageBumpCommand)
+}
+
+func testImageBumpCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		oldSha           = "1111111111111111111111111111111111111111"
+		newSha           = "2222222222222222222222222222222222222222"
+	)
+
+	var (
+		fakeImageWaiter  *fakes.FakeImageWaiter
+		fakeGitValidator *fakes.FakeGitValidator
+	)
+
+	it.Before(func() {
+		fakeImageWaiter = &fakes.FakeImageWaiter{}
+		fakeGitValidator = &fakes.FakeGitValidator{
+			Revisions: map[string]string{"main": newSha},
+		}
+	})
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return imgcmds.NewBumpCommand(clientSetProvider, func(set k8s.ClientSet) imgcmds.ImageWaiter {
+			return fakeImageWaiter
+		}, func(set k8s.ClientSet) image.GitValidator {
+			return fakeGitValidator
+		})
+	}
+
+	pinnedImage := &v1alpha1.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "some-image",
+			Namespace: defaultNamespace,
+			Annotations: map[string]string{
+				image.PinnedRevisionAnnotation: "main",
+			},
+		},
+		Spec: v1alpha1.ImageSpec{
+			Tag:            "some-tag",
+			ServiceAccount: "some-service-account",
+			Source: v1alpha1.SourceConfig{
+				Git: &v1alpha1.Git{
+					URL:      "https://github.com/some/repo",
+					Revision: oldSha,
+				},
+			},
+		},
+	}
+
+	it("patches the image to the latest commit of the pinned revision", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{pinnedImage},
+			Args:    []string{"some-image"},
+			ExpectedOutput: `Bumping Image...
+Git revision "main": 1111111111111111111111111111111111111111..2222222222222222222222222222222222222222
+Compare the commits at https://github.com/some/repo/compare/1111111111111111111111111111111111111111...2222222222222222222222222222222222222222
+Image "some-image" bumped
+`,
+			ExpectPatches: []string{
+				`{"spec":{"source":{"git":{"revision":"2222222222222222222222222222222222222222"}}}}`,
+			},
+		}.TestKpack(t, cmdFunc)
+
+		assert.Equal(t, []fakes.GitValidateCall{
+			{Namespace: defaultNamespace, ServiceAccount: "some-service-account", URL: "https://github.com/some/repo", Revision: "main"},
+		}, fakeGitValidator.ResolveCalls)
+		assert.Len(t, fakeImageWaiter.Calls, 0)
+	})
+
+	it("waits on the image when requested", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{pinnedImage},
+			Args:    []string{"some-image", "--wait"},
+			ExpectedOutput: `Bumping Image...
+Git revision "main": 1111111111111111111111111111111111111111..2222222222222222222222222222222222222222
+Compare the commits at https://github.com/some/repo/compare/1111111111111111111111111111111111111111...2222222222222222222222222222222222222222
+Image "some-image" bumped
+`,
+			ExpectPatches: []string{
+				`{"spec":{"source":{"git":{"revision":"2222222222222222222222222222222222222222"}}}}`,
+			},
+		}.TestKpack(t, cmdFunc)
+
+		assert.Len(t, fakeImageWaiter.Calls, 1)
+	})
+
+	it("prints the git commands to list the commits of other hosts", func() {
+		selfHostedImage := pinnedImage.DeepCopy()
+		selfHostedImage.Spec.Source.Git.URL = "https://git.example.com/some/repo"
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{selfHostedImage},
+			Args:    []string{"some-image"},
+			ExpectedOutput: `Bumping Image...
+Git revision "main": 1111111111111111111111111111111111111111..2222222222222222222222222222222222222222
+List the commits with: git fetch https://git.example.com/some/repo main && git log --oneline 1111111111111111111111111111111111111111..2222222222222222222222222222222222222222
+Image "some-image" bumped
+`,
+			ExpectPatches: []string{
+				`{"spec":{"source":{"git":{"revision":"2222222222222222222222222222222222222222"}}}}`,
+			},
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("does not patch when the revision is up to date", func() {
+		fakeGitValidator.Revisions["main"] = oldSha
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{pinnedImage},
+			Args:    []string{"some-image", "--wait"},
+			ExpectedOutput: `Bumping Image...
+Git revision "main" is up to date at 1111111111111111111111111111111111111111
+Image "some-image" bumped (no change)
+`,
+		}.TestKpack(t, cmdFunc)
+
+		assert.Len(t, fakeImageWaiter.Calls, 0)
+	})
+
+	it("does not patch on dry run", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{pinnedImage},
+			Args:    []string{"some-image", "--dry-run"},
+			ExpectedOutput: `Bumping Image... (dry run)
+Git revision "main": 1111111111111111111111111111111111111111..2222222222222222222222222222222222222222
+Compare the commits at https://github.com/some/repo/compare/1111111111111111111111111111111111111111...2222222222222222222222222222222222222222
+Image "some-image" bumped (dry run)
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when the image does not have a pinned revision", func() {
+		unpinnedImage := pinnedImage.DeepCopy()
+		unpinnedImage.Annotations = nil
+
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{unpinnedImage},
+			Args:           []string{"some-image"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: image \"some-image\" does not have a pinned git revision\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
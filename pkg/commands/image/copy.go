@@ -0,0 +1,262 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+const defaultServiceAccount = "default"
+
+func NewCopyCommand(clientSetProvider k8s.ClientSetProvider, newContextClientSetProvider func(kubeContext string) k8s.ClientSetProvider) *cobra.Command {
+	var (
+		namespace   string
+		toNamespace string
+		toContext   string
+		tag         string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "copy <name> --to-namespace <namespace>",
+		Aliases: []string{"clone"},
+		Short:   "Copy an image configuration to another namespace or cluster",
+		Long: `Copy an image configuration to another namespace, optionally in another kubernetes context.
+
+The image spec is duplicated, with namespace-scoped builder references rewritten to the target namespace.
+The target is checked for the referenced builder or cluster builder, service account and secrets.
+Anything missing in the target is reported as a warning and the image is still created.
+
+The "--tag" flag sets a new registry location for the copied image. The source image tag is used when omitted.
+
+The namespace defaults to the kubernetes current-context namespace.
+The target context defaults to the kubernetes current-context.`,
+		Example: `kp image copy my-image --to-namespace prod
+kp image copy my-image -n dev --to-namespace prod --tag my-registry.com/prod/my-image
+kp image copy my-image --to-namespace prod --to-context prod-cluster`,
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srcCS, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			targetProvider := clientSetProvider
+			if toContext != "" {
+				targetProvider = newContextClientSetProvider(toContext)
+			}
+
+			targetCS, err := targetProvider.GetClientSet(toNamespace)
+			if err != nil {
+				return err
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			if toContext == "" && srcCS.Namespace == targetCS.Namespace {
+				return errors.New("target namespace must differ from the source namespace when copying within a context")
+			}
+
+			srcImg, err := srcCS.KpackClient.KpackV1alpha1().Images(srcCS.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			if err := ch.PrintStatus("Copying Image..."); err != nil {
+				return err
+			}
+
+			img := makeImageCopy(srcImg, targetCS.Namespace, tag)
+
+			if err := warnMissingCopyDependencies(ch, srcCS, targetCS, img); err != nil {
+				return err
+			}
+
+			if err := k8s.SetLastAppliedCfg(img); err != nil {
+				return err
+			}
+
+			if !ch.IsDryRun() {
+				img, err = targetCS.KpackClient.KpackV1alpha1().Images(targetCS.Namespace).Create(img)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := ch.PrintObj(img); err != nil {
+				return err
+			}
+
+			return ch.PrintResult("Image %q copied to namespace %q", img.Name, img.Namespace)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace of the source image")
+	cmd.Flags().StringVar(&toNamespace, "to-namespace", "", "kubernetes namespace to copy the image to")
+	cmd.Flags().StringVar(&toContext, "to-context", "", "kubernetes context to copy the image to")
+	cmd.Flags().StringVarP(&tag, "tag", "t", "", "registry location where the copied image will be created")
+	commands.SetDryRunOutputFlags(cmd)
+	_ = cmd.MarkFlagRequired("to-namespace")
+	return cmd
+}
+
+func makeImageCopy(src *v1alpha1.Image, namespace, tag string) *v1alpha1.Image {
+	img := &v1alpha1.Image{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Image",
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        src.Name,
+			Namespace:   namespace,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		Spec: *src.Spec.DeepCopy(),
+	}
+
+	for k, v := range src.Labels {
+		img.Labels[k] = v
+	}
+
+	for k, v := range src.Annotations {
+		if !isSystemAnnotation(k) {
+			img.Annotations[k] = v
+		}
+	}
+
+	if tag != "" {
+		img.Spec.Tag = tag
+	}
+
+	if img.Spec.Builder.Kind == v1alpha1.BuilderKind && img.Spec.Builder.Namespace != "" {
+		img.Spec.Builder.Namespace = namespace
+	}
+
+	return img
+}
+
+// isSystemAnnotation reports annotations that are managed by kubernetes or
+// kpack, or that request a build of the source image, they are not copied.
+func isSystemAnnotation(key string) bool {
+	if key == TriggerReasonAnnotation {
+		return true
+	}
+
+	idx := strings.Index(key, "/")
+	if idx == -1 {
+		return false
+	}
+
+	domain := key[:idx]
+	for _, system := range []string{"kubernetes.io", "k8s.io", "image.kpack.io"} {
+		if domain == system || strings.HasSuffix(domain, "."+system) {
+			return true
+		}
+	}
+	return false
+}
+
+// warnMissingCopyDependencies reports the builder, service account and
+// secrets referenced by the copied image that do not exist in the target.
+// Secrets are discovered through the service account in the source namespace.
+func warnMissingCopyDependencies(ch *commands.CommandHelper, srcCS, targetCS k8s.ClientSet, img *v1alpha1.Image) error {
+	var err error
+	switch img.Spec.Builder.Kind {
+	case v1alpha1.BuilderKind:
+		_, err = targetCS.KpackClient.KpackV1alpha1().Builders(targetCS.Namespace).Get(img.Spec.Builder.Name, metav1.GetOptions{})
+	case v1alpha1.ClusterBuilderKind:
+		_, err = targetCS.KpackClient.KpackV1alpha1().ClusterBuilders().Get(img.Spec.Builder.Name, metav1.GetOptions{})
+	}
+	if err := warnIfNotFound(ch, err, "%s %q not found in target", img.Spec.Builder.Kind, img.Spec.Builder.Name); err != nil {
+		return err
+	}
+
+	serviceAccount := img.Spec.ServiceAccount
+	if serviceAccount == "" {
+		serviceAccount = defaultServiceAccount
+	}
+
+	_, err = targetCS.K8sClient.CoreV1().ServiceAccounts(targetCS.Namespace).Get(serviceAccount, metav1.GetOptions{})
+	if err := warnIfNotFound(ch, err, "ServiceAccount %q not found in namespace %q", serviceAccount, targetCS.Namespace); err != nil {
+		return err
+	}
+
+	secrets, err := copySecretNames(srcCS, serviceAccount, img)
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range secrets {
+		_, err = targetCS.K8sClient.CoreV1().Secrets(targetCS.Namespace).Get(secret, metav1.GetOptions{})
+		if err := warnIfNotFound(ch, err, "Secret %q not found in namespace %q", secret, targetCS.Namespace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copySecretNames(srcCS k8s.ClientSet, serviceAccount string, img *v1alpha1.Image) ([]string, error) {
+	var names []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	sa, err := srcCS.K8sClient.CoreV1().ServiceAccounts(srcCS.Namespace).Get(serviceAccount, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		for _, s := range sa.Secrets {
+			secret, err := srcCS.K8sClient.CoreV1().Secrets(srcCS.Namespace).Get(s.Name, metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			// service account tokens are generated per namespace and are never copied
+			if secret.Type != corev1.SecretTypeServiceAccountToken {
+				add(s.Name)
+			}
+		}
+		for _, s := range sa.ImagePullSecrets {
+			add(s.Name)
+		}
+	}
+
+	if img.Spec.Source.Registry != nil {
+		for _, s := range img.Spec.Source.Registry.ImagePullSecrets {
+			add(s.Name)
+		}
+	}
+
+	return names, nil
+}
+
+func warnIfNotFound(ch *commands.CommandHelper, err error, format string, args ...interface{}) error {
+	if k8serrors.IsNotFound(err) {
+		return ch.Printlnf("Warning: "+format, args...)
+	}
+	return err
+}
@@ -0,0 +1,269 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfakes "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestImageCopyCommand(t *testing.T) {
+	spec.Run(t, "TestImageCopyCommand", testImageCopyCommand)
+}
+
+func testImageCopyCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		targetNamespace  = "some-target-namespace"
+	)
+
+	srcImage := &v1alpha1.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "some-image",
+			Namespace: defaultNamespace,
+			Labels:    map[string]string{"team": "some-team"},
+		},
+		Spec: v1alpha1.ImageSpec{
+			Tag: "some-registry.io/some-repo",
+			Builder: corev1.ObjectReference{
+				Kind:      v1alpha1.BuilderKind,
+				Namespace: defaultNamespace,
+				Name:      "some-builder",
+			},
+			ServiceAccount: "default",
+			Source: v1alpha1.SourceConfig{
+				Git: &v1alpha1.Git{
+					URL:      "some-git-url",
+					Revision: "some-git-rev",
+				},
+			},
+		},
+		Status: v1alpha1.ImageStatus{
+			LatestImage: "some-registry.io/some-repo@sha256:123",
+		},
+	}
+
+	srcServiceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default",
+			Namespace: defaultNamespace,
+		},
+		Secrets: []corev1.ObjectReference{
+			{Name: "git-secret"},
+			{Name: "default-token-abcde"},
+		},
+		ImagePullSecrets: []corev1.LocalObjectReference{
+			{Name: "registry-secret"},
+		},
+	}
+
+	srcSecrets := []runtime.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "git-secret", Namespace: defaultNamespace},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "default-token-abcde", Namespace: defaultNamespace},
+			Type:       corev1.SecretTypeServiceAccountToken,
+		},
+	}
+
+	targetBuilder := &v1alpha1.Builder{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-builder", Namespace: targetNamespace},
+	}
+
+	targetServiceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: targetNamespace},
+	}
+
+	targetSecrets := []runtime.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "git-secret", Namespace: targetNamespace},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry-secret", Namespace: targetNamespace},
+		},
+	}
+
+	makeExpectedImage := func(tag, lastApplied string) *v1alpha1.Image {
+		return &v1alpha1.Image{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Image",
+				APIVersion: "kpack.io/v1alpha1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-image",
+				Namespace: targetNamespace,
+				Labels:    map[string]string{"team": "some-team"},
+				Annotations: map[string]string{
+					"kubectl.kubernetes.io/last-applied-configuration": lastApplied,
+				},
+			},
+			Spec: v1alpha1.ImageSpec{
+				Tag: tag,
+				Builder: corev1.ObjectReference{
+					Kind:      v1alpha1.BuilderKind,
+					Namespace: targetNamespace,
+					Name:      "some-builder",
+				},
+				ServiceAccount: "default",
+				Source: v1alpha1.SourceConfig{
+					Git: &v1alpha1.Git{
+						URL:      "some-git-url",
+						Revision: "some-git-rev",
+					},
+				},
+			},
+		}
+	}
+
+	cmdFunc := func(k8sClientSet *k8sfakes.Clientset, kpackClientSet *kpackfakes.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeClusterProvider(k8sClientSet, kpackClientSet)
+		return image.NewCopyCommand(clientSetProvider, func(string) k8s.ClientSetProvider {
+			t.Fatal("unexpected context client set provider")
+			return nil
+		})
+	}
+
+	when("the target namespace has all referenced resources", func() {
+		it("copies the image and rewrites the builder namespace", func() {
+			expectedImage := makeExpectedImage("some-registry.io/some-repo",
+				`{"kind":"Image","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"some-image","namespace":"some-target-namespace","creationTimestamp":null,"labels":{"team":"some-team"}},"spec":{"tag":"some-registry.io/some-repo","builder":{"kind":"Builder","namespace":"some-target-namespace","name":"some-builder"},"serviceAccount":"default","source":{"git":{"url":"some-git-url","revision":"some-git-rev"}}},"status":{}}`)
+
+			testhelpers.CommandTest{
+				K8sObjects:   append([]runtime.Object{srcServiceAccount, targetServiceAccount}, append(srcSecrets, targetSecrets...)...),
+				KpackObjects: []runtime.Object{srcImage, targetBuilder},
+				Args:         []string{"some-image", "-n", defaultNamespace, "--to-namespace", targetNamespace},
+				ExpectedOutput: `Copying Image...
+Image "some-image" copied to namespace "some-target-namespace"
+`,
+				ExpectCreates: []runtime.Object{expectedImage},
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+	})
+
+	when("the source image has annotations", func() {
+		it("copies the annotations that are not managed by kubernetes or kpack", func() {
+			annotatedImage := srcImage.DeepCopy()
+			annotatedImage.Annotations = map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": "some-old-config",
+				image.BuildNeededAnnotation:                        "some-time",
+				image.TriggerReasonAnnotation:                      "some-reason",
+				"kp.kpack.io/pinnedRevision":                       "some-git-rev",
+				"some-org.io/owner":                                "some-owner",
+			}
+
+			expectedImage := makeExpectedImage("some-registry.io/some-repo",
+				`{"kind":"Image","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"some-image","namespace":"some-target-namespace","creationTimestamp":null,"labels":{"team":"some-team"},"annotations":{"kp.kpack.io/pinnedRevision":"some-git-rev","some-org.io/owner":"some-owner"}},"spec":{"tag":"some-registry.io/some-repo","builder":{"kind":"Builder","namespace":"some-target-namespace","name":"some-builder"},"serviceAccount":"default","source":{"git":{"url":"some-git-url","revision":"some-git-rev"}}},"status":{}}`)
+			expectedImage.Annotations["kp.kpack.io/pinnedRevision"] = "some-git-rev"
+			expectedImage.Annotations["some-org.io/owner"] = "some-owner"
+
+			testhelpers.CommandTest{
+				K8sObjects:   append([]runtime.Object{srcServiceAccount, targetServiceAccount}, append(srcSecrets, targetSecrets...)...),
+				KpackObjects: []runtime.Object{annotatedImage, targetBuilder},
+				Args:         []string{"some-image", "-n", defaultNamespace, "--to-namespace", targetNamespace},
+				ExpectedOutput: `Copying Image...
+Image "some-image" copied to namespace "some-target-namespace"
+`,
+				ExpectCreates: []runtime.Object{expectedImage},
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+	})
+
+	when("the target namespace is missing referenced resources", func() {
+		it("warns about each missing resource and still copies the image", func() {
+			expectedImage := makeExpectedImage("some-registry.io/prod/some-repo",
+				`{"kind":"Image","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"some-image","namespace":"some-target-namespace","creationTimestamp":null,"labels":{"team":"some-team"}},"spec":{"tag":"some-registry.io/prod/some-repo","builder":{"kind":"Builder","namespace":"some-target-namespace","name":"some-builder"},"serviceAccount":"default","source":{"git":{"url":"some-git-url","revision":"some-git-rev"}}},"status":{}}`)
+
+			testhelpers.CommandTest{
+				K8sObjects:   append([]runtime.Object{srcServiceAccount}, srcSecrets...),
+				KpackObjects: []runtime.Object{srcImage},
+				Args:         []string{"some-image", "-n", defaultNamespace, "--to-namespace", targetNamespace, "--tag", "some-registry.io/prod/some-repo"},
+				ExpectedOutput: `Copying Image...
+Warning: Builder "some-builder" not found in target
+Warning: ServiceAccount "default" not found in namespace "some-target-namespace"
+Warning: Secret "git-secret" not found in namespace "some-target-namespace"
+Warning: Secret "registry-secret" not found in namespace "some-target-namespace"
+Image "some-image" copied to namespace "some-target-namespace"
+`,
+				ExpectCreates: []runtime.Object{expectedImage},
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+	})
+
+	when("dry run is provided", func() {
+		it("does not create the image", func() {
+			testhelpers.CommandTest{
+				K8sObjects:   append([]runtime.Object{srcServiceAccount, targetServiceAccount}, append(srcSecrets, targetSecrets...)...),
+				KpackObjects: []runtime.Object{srcImage, targetBuilder},
+				Args:         []string{"some-image", "-n", defaultNamespace, "--to-namespace", targetNamespace, "--dry-run"},
+				ExpectedOutput: `Copying Image... (dry run)
+Image "some-image" copied to namespace "some-target-namespace" (dry run)
+`,
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+	})
+
+	when("the target namespace is the source namespace", func() {
+		it("returns an error", func() {
+			testhelpers.CommandTest{
+				KpackObjects:   []runtime.Object{srcImage},
+				Args:           []string{"some-image", "-n", defaultNamespace, "--to-namespace", defaultNamespace},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: target namespace must differ from the source namespace when copying within a context\n",
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+	})
+
+	when("a target context is provided", func() {
+		it("creates the image with the target context client set", func() {
+			srcK8sClient := k8sfakes.NewSimpleClientset(append([]runtime.Object{srcServiceAccount}, srcSecrets...)...)
+			srcKpackClient := kpackfakes.NewSimpleClientset(srcImage)
+			targetK8sClient := k8sfakes.NewSimpleClientset(append([]runtime.Object{targetServiceAccount}, targetSecrets...)...)
+			targetKpackClient := kpackfakes.NewSimpleClientset(targetBuilder)
+
+			var requestedContext string
+			cmd := image.NewCopyCommand(
+				testhelpers.GetFakeClusterProvider(srcK8sClient, srcKpackClient),
+				func(kubeContext string) k8s.ClientSetProvider {
+					requestedContext = kubeContext
+					return testhelpers.GetFakeClusterProvider(targetK8sClient, targetKpackClient)
+				},
+			)
+
+			out := &bytes.Buffer{}
+			cmd.SetOut(out)
+			cmd.SetArgs([]string{"some-image", "-n", defaultNamespace, "--to-namespace", targetNamespace, "--to-context", "prod-cluster"})
+
+			err := cmd.Execute()
+			require.NoError(t, err)
+			require.Equal(t, "prod-cluster", requestedContext)
+			require.Equal(t, `Copying Image...
+Image "some-image" copied to namespace "some-target-namespace"
+`, out.String())
+
+			srcActions, err := testhelpers.ActionRecorderList{srcKpackClient}.ActionsByVerb()
+			require.NoError(t, err)
+			require.Len(t, srcActions.Creates, 0)
+
+			targetActions, err := testhelpers.ActionRecorderList{targetKpackClient}.ActionsByVerb()
+			require.NoError(t, err)
+			require.Len(t, targetActions.Creates, 1)
+			require.Equal(t, targetNamespace, targetActions.Creates[0].GetObject().(*v1alpha1.Image).Namespace)
+		})
+	})
+}
@@ -13,7 +13,7 @@ import (
 	"github.com/pivotal/build-service-cli/pkg/registry"
 )
 
-func NewCreateCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider, newImageWaiter func(k8s.ClientSet) ImageWaiter) *cobra.Command {
+func NewCreateCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider, newImageWaiter func(k8s.ClientSet) ImageWaiter, newGitValidator func(k8s.ClientSet) image.GitValidator) *cobra.Command {
 	var (
 		tag       string
 		namespace string
@@ -34,16 +34,26 @@ The flags for this command determine how the build will retrieve source code:
   "--git" and "--git-revision" to use Git based source
   "--blob" to use source code hosted in a blob store
   "--local-path" to use source code from the local machine
+  "--source-image" to use a source image already published to a registry
+
+Git sources are validated before the image is saved: the repository must be reachable over http(s),
+the revision must resolve to a commit, and a warning is shown when no git secret targets the repository host.
+Source images must exist and be readable from this machine with the provided registry TLS options.
+Use "--skip-source-validation" to disable these checks.
+
+The "--pin-revision" flag resolves a git branch or tag to the commit it currently points to.
+The image is built from that commit until the revision is updated, for example with "kp image bump".
 
 Local source code will be pushed to the same registry provided for the image tag.
 Therefore, you must have credentials to access the registry on your machine.
---registry-ca-cert-path and --registry-verify-certs are only used for local source type.
+--registry-ca-cert-path and --registry-verify-certs are only used for local source and source image types.
 
 Environment variables may be provided by using the "--env" flag.
 For each environment variable, supply the "--env" flag followed by the key value pair.
 For example, "--env key1=value1 --env key2=value2 ...".`,
 		Example: `kp image create my-image --tag my-registry.com/my-repo --git https://my-repo.com/my-app.git --git-revision my-branch
 kp image create my-image --tag my-registry.com/my-repo --blob https://my-blob-host.com/my-blob
+kp image create my-image --tag my-registry.com/my-repo --source-image my-registry.com/my-app-source@sha256:<digest>
 kp image create my-image --tag my-registry.com/my-repo --local-path /path/to/local/source/code
 kp image create my-image --tag my-registry.com/my-repo --local-path /path/to/local/source/code --builder my-builder -n my-namespace
 kp image create my-image --tag my-registry.com/my-repo --blob https://my-blob-host.com/my-blob --env foo=bar --env color=red --env food=apple`,
@@ -64,8 +74,11 @@ kp image create my-image --tag my-registry.com/my-repo --blob https://my-blob-ho
 
 			factory.SubPath = &subPath
 			factory.SourceUploader = rup.SourceUploader(ch.CanChangeState())
+			factory.Fetcher = rup.Fetcher()
 			factory.Printer = ch
 
+			factory.GitValidator = newGitValidator(cs)
+
 			img, err := create(name, tag, &factory, ch, cs)
 			if err != nil {
 				return err
@@ -86,6 +99,7 @@ kp image create my-image --tag my-registry.com/my-repo --blob https://my-blob-ho
 	cmd.Flags().StringVar(&factory.GitRevision, "git-revision", "", "git revision (default \"master\")")
 	cmd.Flags().StringVar(&factory.Blob, "blob", "", "source code blob url")
 	cmd.Flags().StringVar(&factory.LocalPath, "local-path", "", "path to local source code")
+	cmd.Flags().StringVar(&factory.SourceImage, "source-image", "", "published source code image")
 	cmd.Flags().StringVar(&subPath, "sub-path", "", "build code at the sub path located within the source code directory")
 	cmd.Flags().StringVarP(&factory.Builder, "builder", "b", "", "builder name")
 	cmd.Flags().StringVarP(&factory.ClusterBuilder, "cluster-builder", "c", "", "cluster builder name")
@@ -94,6 +108,8 @@ kp image create my-image --tag my-registry.com/my-repo --blob https://my-blob-ho
 	cmd.Flags().BoolP("wait", "w", false, "wait for image create to be reconciled and tail resulting build logs")
 	commands.SetImgUploadDryRunOutputFlags(cmd)
 	commands.SetTLSFlags(cmd, &factory.TLSConfig)
+	cmd.Flags().BoolVar(&factory.SkipSourceValidation, "skip-source-validation", false, "skip validation of the git repository, revision, git secrets and source image")
+	cmd.Flags().BoolVar(&factory.PinRevision, "pin-revision", false, "resolve the git revision to a commit sha")
 	_ = cmd.MarkFlagRequired("tag")
 	return cmd
 }
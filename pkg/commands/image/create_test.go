@@ -8,6 +8,7 @@ import (
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/pkg/errors"
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -17,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	imgcmds "github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/image"
 	"github.com/pivotal/build-service-cli/pkg/image/fakes"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 	registryfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
@@ -31,16 +33,26 @@ func testImageCreateCommand(t *testing.T, when spec.G, it spec.S) {
 	const defaultNamespace = "some-default-namespace"
 
 	fakeSourceUploader := registryfakes.NewSourceUploader("some-registry.io/some-repo-source:source-id")
+	fakeFetcher := &registryfakes.Fetcher{}
 	registryUtilProvider := registryfakes.UtilProvider{
 		FakeSourceUploader: fakeSourceUploader,
+		FakeFetcher:        fakeFetcher,
 	}
 
 	fakeImageWaiter := &fakes.FakeImageWaiter{}
 
+	var fakeGitValidator *fakes.FakeGitValidator
+
+	it.Before(func() {
+		fakeGitValidator = &fakes.FakeGitValidator{}
+	})
+
 	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
 		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
 		return imgcmds.NewCreateCommand(clientSetProvider, registryUtilProvider, func(set k8s.ClientSet) imgcmds.ImageWaiter {
 			return fakeImageWaiter
+		}, func(set k8s.ClientSet) image.GitValidator {
+			return fakeGitValidator
 		})
 	}
 
@@ -150,7 +162,7 @@ Image "some-image" created
 					},
 					ExpectErr: true,
 					ExpectedOutput: `Creating Image...
-Error: image source must be one of git, blob, or local-path
+Error: image source must be one of git, blob, local-path, or source-image
 `,
 				}.TestKpack(t, cmdFunc)
 
@@ -231,7 +243,7 @@ Image "some-image" created
 					},
 					ExpectErr: true,
 					ExpectedOutput: `Creating Image...
-Error: image source must be one of git, blob, or local-path
+Error: image source must be one of git, blob, local-path, or source-image
 `,
 				}.TestKpack(t, cmdFunc)
 
@@ -240,6 +252,176 @@ Error: image source must be one of git, blob, or local-path
 		})
 	})
 
+	when("the image uses a git source", func() {
+		expectedImage := &v1alpha1.Image{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Image",
+				APIVersion: "kpack.io/v1alpha1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-image",
+				Namespace: defaultNamespace,
+				Annotations: map[string]string{
+					"kubectl.kubernetes.io/last-applied-configuration": `{"kind":"Image","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"some-image","namespace":"some-default-namespace","creationTimestamp":null},"spec":{"tag":"some-registry.io/some-repo","builder":{"kind":"ClusterBuilder","name":"default"},"serviceAccount":"default","source":{"git":{"url":"some-git-url","revision":"some-git-rev"}},"build":{"resources":{}}},"status":{}}`,
+				},
+			},
+			Spec: v1alpha1.ImageSpec{
+				Tag: "some-registry.io/some-repo",
+				Builder: corev1.ObjectReference{
+					Kind: v1alpha1.ClusterBuilderKind,
+					Name: "default",
+				},
+				ServiceAccount: "default",
+				Source: v1alpha1.SourceConfig{
+					Git: &v1alpha1.Git{
+						URL:      "some-git-url",
+						Revision: "some-git-rev",
+					},
+				},
+				Build: &v1alpha1.ImageBuild{},
+			},
+		}
+
+		args := []string{
+			"some-image",
+			"--tag", "some-registry.io/some-repo",
+			"--git", "some-git-url",
+			"--git-revision", "some-git-rev",
+		}
+
+		it("validates the git source and prints warnings", func() {
+			fakeGitValidator.Warnings = []string{"some-warning"}
+
+			testhelpers.CommandTest{
+				Args: args,
+				ExpectedOutput: `Creating Image...
+Warning: some-warning
+Image "some-image" created
+`,
+				ExpectCreates: []runtime.Object{
+					expectedImage,
+				},
+			}.TestKpack(t, cmdFunc)
+
+			assert.Equal(t, []fakes.GitValidateCall{
+				{Namespace: defaultNamespace, ServiceAccount: "default", URL: "some-git-url", Revision: "some-git-rev"},
+			}, fakeGitValidator.Calls)
+		})
+
+		it("does not create the image when the git source is invalid", func() {
+			fakeGitValidator.Err = errors.New("invalid git source: git revision \"some-git-rev\" not found")
+
+			testhelpers.CommandTest{
+				Args:      args,
+				ExpectErr: true,
+				ExpectedOutput: `Creating Image...
+Error: invalid git source: git revision "some-git-rev" not found
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("pins the git revision when pin-revision is provided", func() {
+			fakeGitValidator.Revisions = map[string]string{"some-git-rev": "1111111111111111111111111111111111111111"}
+
+			pinnedImage := expectedImage.DeepCopy()
+			pinnedImage.Spec.Source.Git.Revision = "1111111111111111111111111111111111111111"
+			pinnedImage.Annotations = map[string]string{
+				"kp.kpack.io/pinnedRevision":                       "some-git-rev",
+				"kubectl.kubernetes.io/last-applied-configuration": `{"kind":"Image","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"some-image","namespace":"some-default-namespace","creationTimestamp":null,"annotations":{"kp.kpack.io/pinnedRevision":"some-git-rev"}},"spec":{"tag":"some-registry.io/some-repo","builder":{"kind":"ClusterBuilder","name":"default"},"serviceAccount":"default","source":{"git":{"url":"some-git-url","revision":"1111111111111111111111111111111111111111"}},"build":{"resources":{}}},"status":{}}`,
+			}
+
+			testhelpers.CommandTest{
+				Args: append(args, "--pin-revision"),
+				ExpectedOutput: `Creating Image...
+Pinned git revision "some-git-rev" to 1111111111111111111111111111111111111111
+Image "some-image" created
+`,
+				ExpectCreates: []runtime.Object{
+					pinnedImage,
+				},
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("skips validation when skip-source-validation is provided", func() {
+			fakeGitValidator.Err = errors.New("some-error")
+
+			testhelpers.CommandTest{
+				Args: append(args, "--skip-source-validation"),
+				ExpectedOutput: `Creating Image...
+Image "some-image" created
+`,
+				ExpectCreates: []runtime.Object{
+					expectedImage,
+				},
+			}.TestKpack(t, cmdFunc)
+
+			assert.Len(t, fakeGitValidator.Calls, 0)
+		})
+	})
+
+	when("the image uses a source image", func() {
+		const sourceImage = "some-registry.io/some-repo-source@sha256:1f3c4d8e2b7a6f5e9d0c1b2a3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e"
+
+		args := []string{
+			"some-image",
+			"--tag", "some-registry.io/some-repo",
+			"--source-image", sourceImage,
+		}
+
+		it("validates the source image and creates the image config", func() {
+			fakeFetcher.AddImage(sourceImage, registryfakes.NewFakeLabeledImage("some-label", "some-value", "some-digest"))
+
+			expectedImage := &v1alpha1.Image{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Image",
+					APIVersion: "kpack.io/v1alpha1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "some-image",
+					Namespace: defaultNamespace,
+					Annotations: map[string]string{
+						"kubectl.kubernetes.io/last-applied-configuration": `{"kind":"Image","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"some-image","namespace":"some-default-namespace","creationTimestamp":null},"spec":{"tag":"some-registry.io/some-repo","builder":{"kind":"ClusterBuilder","name":"default"},"serviceAccount":"default","source":{"registry":{"image":"` + sourceImage + `"}},"build":{"resources":{}}},"status":{}}`,
+					},
+				},
+				Spec: v1alpha1.ImageSpec{
+					Tag: "some-registry.io/some-repo",
+					Builder: corev1.ObjectReference{
+						Kind: v1alpha1.ClusterBuilderKind,
+						Name: "default",
+					},
+					ServiceAccount: "default",
+					Source: v1alpha1.SourceConfig{
+						Registry: &v1alpha1.Registry{
+							Image: sourceImage,
+						},
+					},
+					Build: &v1alpha1.ImageBuild{},
+				},
+			}
+
+			testhelpers.CommandTest{
+				Args: args,
+				ExpectedOutput: `Creating Image...
+Image "some-image" created
+`,
+				ExpectCreates: []runtime.Object{
+					expectedImage,
+				},
+			}.TestKpack(t, cmdFunc)
+			assert.Equal(t, 1, fakeFetcher.CallCount())
+		})
+
+		it("does not create the image when the source image cannot be read", func() {
+			testhelpers.CommandTest{
+				Args:      args,
+				ExpectErr: true,
+				ExpectedOutput: `Creating Image...
+Error: unable to read source image "` + sourceImage + `": image not found: "` + sourceImage + `"
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+	})
+
 	when("the image uses local source code", func() {
 		it("uploads the source image and creates the image config", func() {
 			expectedImage := &v1alpha1.Image{
@@ -411,7 +593,7 @@ Image "some-image" created
 					},
 					ExpectErr: true,
 					ExpectedOutput: `Creating Image...
-Error: image source must be one of git, blob, or local-path
+Error: image source must be one of git, blob, local-path, or source-image
 `,
 				}.TestKpack(t, cmdFunc)
 				assert.Len(t, fakeImageWaiter.Calls, 0)
@@ -580,7 +762,7 @@ status: {}
 					},
 					ExpectErr: true,
 					ExpectedOutput: `Creating Image...
-Error: image source must be one of git, blob, or local-path
+Error: image source must be one of git, blob, local-path, or source-image
 `,
 				}.TestKpack(t, cmdFunc)
 			})
@@ -670,7 +852,7 @@ status: {}
 					},
 					ExpectErr: true,
 					ExpectedOutput: `Creating Image... (dry run with image upload)
-Error: image source must be one of git, blob, or local-path
+Error: image source must be one of git, blob, local-path, or source-image
 `,
 				}.TestKpack(t, cmdFunc)
 			})
@@ -5,17 +5,38 @@ package image
 
 import (
 	"fmt"
+	"io"
+	"sort"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/pivotal/build-service-cli/pkg/build"
 	"github.com/pivotal/build-service-cli/pkg/commands"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/registry"
 )
 
-func NewDeleteCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+type cleanupOptions struct {
+	registryImages bool
+	sourceImages   bool
+	keepLatest     bool
+	tlsCfg         registry.TLSConfig
+}
+
+func (o cleanupOptions) enabled() bool {
+	return o.registryImages || o.sourceImages
+}
+
+func NewDeleteCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider, confirmationProvider ConfirmationProvider) *cobra.Command {
 	var (
-		namespace string
+		namespace   string
+		bulkOpts    bulkOptions
+		cleanupOpts cleanupOptions
 	)
 
 	cmd := &cobra.Command{
@@ -23,27 +44,238 @@ func NewDeleteCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		Short: "Delete an image",
 		Long: `Delete an image and its associated image builds in the provided namespace.
 
+The "--delete-registry-images" flag also deletes the images built for the image from the registry.
+The "--delete-source-images" flag also deletes the local source code uploaded to the "-source" repository
+next to the image tag. Both use the images recorded by the image builds. Deleting an image from the registry
+also removes the tags that point to it.
+The "--keep-latest" flag keeps the latest built image and its source in the registry.
+The registry images are listed for confirmation unless "--force" is provided, and "--dry-run" lists them
+without deleting anything.
+
+Multiple images may be deleted at once by using the "--selector" and/or "--all-namespaces" flags
+instead of an image name. The selected images are listed for confirmation unless "--force" is provided.
+Registry images cannot be deleted when deleting multiple images.
+
 namespace defaults to the kubernetes current-context namespace.`,
-		Example: "kp image delete my-image",
-		Args:    commands.ExactArgsWithUsage(1),
+		Example: `kp image delete my-image
+kp image delete my-image --delete-registry-images --delete-source-images
+kp image delete my-image --delete-registry-images --keep-latest --dry-run
+kp image delete -l team=my-team`,
+		Args: imageNameOrSelectorArgs(&bulkOpts),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if cleanupOpts.keepLatest && !cleanupOpts.enabled() {
+				return errors.New("keep-latest requires delete-registry-images or delete-source-images")
+			}
+
 			cs, err := clientSetProvider.GetClientSet(namespace)
 			if err != nil {
 				return err
 			}
 
-			err = cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Delete(args[0], &metav1.DeleteOptions{})
+			ch, err := commands.NewCommandHelper(cmd)
 			if err != nil {
 				return err
 			}
 
-			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Image %q deleted\n", args[0])
-			return err
+			if bulkOpts.isBulk() {
+				if cleanupOpts.enabled() {
+					return errors.New("registry images cannot be deleted when deleting multiple images")
+				}
+
+				if ch.IsDryRun() {
+					return errors.New("dry-run cannot be used when deleting multiple images")
+				}
+
+				return bulkDelete(cmd, cs, confirmationProvider, bulkOpts)
+			}
+
+			if !cleanupOpts.enabled() {
+				return deleteImage(ch, cs, args[0])
+			}
+
+			return deleteImageWithRegistryImages(ch, cs, rup, confirmationProvider, args[0], cleanupOpts, bulkOpts.force)
 		},
 		SilenceUsage: true,
 	}
 
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().BoolVar(&cleanupOpts.registryImages, "delete-registry-images", false, "delete the images built for the image from the registry")
+	cmd.Flags().BoolVar(&cleanupOpts.sourceImages, "delete-source-images", false, "delete the uploaded local source code images from the registry")
+	cmd.Flags().BoolVar(&cleanupOpts.keepLatest, "keep-latest", false, "keep the latest built image and its source in the registry")
+	cmd.Flags().Bool(commands.DryRunFlag, false, "list what would be deleted without deleting anything")
+	commands.SetTLSFlags(cmd, &cleanupOpts.tlsCfg)
+	setBulkFlags(cmd, &bulkOpts)
 
 	return cmd
 }
+
+func deleteImage(ch *commands.CommandHelper, cs k8s.ClientSet, imageName string) error {
+	var err error
+	if ch.IsDryRun() {
+		_, err = cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(imageName, metav1.GetOptions{})
+	} else {
+		err = cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Delete(imageName, &metav1.DeleteOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	return ch.PrintResult("Image %q deleted", imageName)
+}
+
+func deleteImageWithRegistryImages(ch *commands.CommandHelper, cs k8s.ClientSet, rup registry.UtilProvider, confirmationProvider ConfirmationProvider, imageName string, opts cleanupOptions, force bool) error {
+	img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(imageName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
+		LabelSelector: v1alpha1.ImageLabel + "=" + img.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(buildList.Items, build.Sort(buildList.Items))
+
+	refs, err := registryImagesToDelete(img, buildList.Items, opts)
+	if err != nil {
+		return err
+	}
+
+	if !ch.IsDryRun() && !force && len(refs) > 0 {
+		confirmed, err := confirmRegistryDeletion(ch.Writer(), confirmationProvider, refs)
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			return ch.Printlnf("Skipping image deletion")
+		}
+	}
+
+	if !ch.IsDryRun() {
+		if err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Delete(img.Name, &metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+
+		for _, bld := range buildList.Items {
+			err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).Delete(bld.Name, &metav1.DeleteOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	var failed int
+	if len(refs) > 0 {
+		if err := ch.PrintStatus("Deleting registry images..."); err != nil {
+			return err
+		}
+
+		deleter := rup.Deleter(ch.CanChangeState())
+		for _, ref := range refs {
+			if err := deleter.Delete(ref, ch.Writer(), opts.tlsCfg); err != nil {
+				failed++
+				if err := ch.Printlnf("Warning: %s", err); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := ch.PrintResult("Image %q deleted", img.Name); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return errors.Errorf("failed to delete %d registry image(s)", failed)
+	}
+	return nil
+}
+
+// registryImagesToDelete returns the built images and uploaded source images
+// recorded by builds, in build order. Source images are only included when
+// they were uploaded to the "-source" repository next to the image tag.
+func registryImagesToDelete(img *v1alpha1.Image, builds []v1alpha1.Build, opts cleanupOptions) ([]string, error) {
+	keep := map[string]bool{}
+	if opts.keepLatest && img.Status.LatestImage != "" {
+		keep[img.Status.LatestImage] = true
+		for _, bld := range builds {
+			if bld.Status.LatestImage == img.Status.LatestImage && bld.Spec.Source.Registry != nil {
+				keep[bld.Spec.Source.Registry.Image] = true
+			}
+		}
+	}
+
+	tag, err := name.ParseReference(img.Spec.Tag, name.WeakValidation)
+	if err != nil {
+		return nil, err
+	}
+	sourceRepo := tag.Context().Name() + "-source"
+
+	var refs []string
+	add := func(ref string) {
+		if ref != "" && !keep[ref] {
+			keep[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, bld := range builds {
+		if opts.registryImages {
+			add(bld.Status.LatestImage)
+		}
+
+		if opts.sourceImages && bld.Spec.Source.Registry != nil {
+			ref, err := name.ParseReference(bld.Spec.Source.Registry.Image, name.WeakValidation)
+			if err != nil {
+				return nil, err
+			}
+
+			if ref.Context().Name() == sourceRepo {
+				add(bld.Spec.Source.Registry.Image)
+			}
+		}
+	}
+
+	return refs, nil
+}
+
+func confirmRegistryDeletion(out io.Writer, confirmationProvider ConfirmationProvider, refs []string) (bool, error) {
+	if _, err := fmt.Fprintln(out, "The following registry images will be deleted:"); err != nil {
+		return false, err
+	}
+
+	for _, ref := range refs {
+		if _, err := fmt.Fprintf(out, "\t%s\n", ref); err != nil {
+			return false, err
+		}
+	}
+
+	message := fmt.Sprintf("Please confirm deletion of %d registry image(s) by typing 'y': ", len(refs))
+	return confirmationProvider.Confirm(message)
+}
+
+func bulkDelete(cmd *cobra.Command, cs k8s.ClientSet, confirmationProvider ConfirmationProvider, opts bulkOptions) error {
+	images, err := listSelectedImages(cs, opts)
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := confirmBulk(cmd.OutOrStdout(), confirmationProvider, opts, images, "deletion")
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), "Skipping image deletion")
+		return err
+	}
+
+	results := runBulk(images, opts.concurrency, func(_ int, img v1alpha1.Image) (string, error) {
+		return "deleted", cs.KpackClient.KpackV1alpha1().Images(img.Namespace).Delete(img.Name, &metav1.DeleteOptions{})
+	})
+
+	return displayBulkResults(cmd.OutOrStdout(), results, "deletion")
+}
@@ -7,14 +7,20 @@ import (
 	"testing"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/pkg/errors"
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgotesting "k8s.io/client-go/testing"
 
+	commandsfakes "github.com/pivotal/build-service-cli/pkg/commands/fakes"
 	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	registryfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
 )
 
@@ -25,9 +31,19 @@ func TestImageDeleteCommand(t *testing.T) {
 func testImageDeleteCommand(t *testing.T, when spec.G, it spec.S) {
 	const defaultNamespace = "some-default-namespace"
 
+	var (
+		fakeConfirmationProvider *commandsfakes.FakeConfirmationProvider
+		fakeDeleter              *registryfakes.Deleter
+	)
+
+	it.Before(func() {
+		fakeConfirmationProvider = commandsfakes.NewFakeConfirmationProvider(true, nil)
+		fakeDeleter = &registryfakes.Deleter{}
+	})
+
 	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
 		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-		return image.NewDeleteCommand(clientSetProvider)
+		return image.NewDeleteCommand(clientSetProvider, registryfakes.UtilProvider{FakeDeleter: fakeDeleter}, fakeConfirmationProvider)
 	}
 
 	when("a namespace is provided", func() {
@@ -125,4 +141,234 @@ func testImageDeleteCommand(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 	})
+
+	when("all namespaces are selected", func() {
+		firstImage := &v1alpha1.Image{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "some-image",
+				Namespace: "some-namespace",
+			},
+		}
+		secondImage := &v1alpha1.Image{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "some-image",
+				Namespace: defaultNamespace,
+			},
+		}
+
+		it("deletes every image without confirmation when forced", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					secondImage,
+					firstImage,
+				},
+				Args: []string{"--all-namespaces", "--force", "--concurrency", "1"},
+				ExpectedOutput: `NAMESPACE                 NAME          RESULT
+some-default-namespace    some-image    deleted
+some-namespace            some-image    deleted
+
+`,
+				ExpectDeletes: []clientgotesting.DeleteActionImpl{
+					{
+						ActionImpl: clientgotesting.ActionImpl{
+							Namespace: defaultNamespace,
+						},
+						Name: "some-image",
+					},
+					{
+						ActionImpl: clientgotesting.ActionImpl{
+							Namespace: "some-namespace",
+						},
+						Name: "some-image",
+					},
+				},
+			}.TestKpack(t, cmdFunc)
+			require.False(t, fakeConfirmationProvider.WasRequested())
+		})
+
+		it("does not delete when confirmation is not given", func() {
+			fakeConfirmationProvider = commandsfakes.NewFakeConfirmationProvider(false, nil)
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					secondImage,
+					firstImage,
+				},
+				Args: []string{"--all-namespaces"},
+				ExpectedOutput: `The following images were selected for deletion:
+NAMESPACE                 NAME
+some-default-namespace    some-image
+some-namespace            some-image
+
+Skipping image deletion
+`,
+			}.TestKpack(t, cmdFunc)
+			require.NoError(t, fakeConfirmationProvider.WasRequestedWithMsg("Please confirm deletion of 2 image(s) by typing 'y': "))
+		})
+
+		it("returns an error when no images are found", func() {
+			testhelpers.CommandTest{
+				Args:           []string{"-A", "-l", "team=some-team"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: no images found\n",
+			}.TestKpack(t, cmdFunc)
+		})
+	})
+
+	when("registry images are deleted", func() {
+		img := &v1alpha1.Image{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "some-image",
+				Namespace: defaultNamespace,
+			},
+			Spec: v1alpha1.ImageSpec{
+				Tag: "registry.io/some-repo",
+			},
+			Status: v1alpha1.ImageStatus{
+				LatestImage: "registry.io/some-repo@sha256:2222222222222222222222222222222222222222222222222222222222222222",
+			},
+		}
+
+		makeBuild := func(number, latestImage, sourceImage string) *v1alpha1.Build {
+			return &v1alpha1.Build{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "some-image-build-" + number,
+					Namespace: defaultNamespace,
+					Labels: map[string]string{
+						v1alpha1.ImageLabel:       "some-image",
+						v1alpha1.BuildNumberLabel: number,
+					},
+				},
+				Spec: v1alpha1.BuildSpec{
+					Source: v1alpha1.SourceConfig{
+						Registry: &v1alpha1.Registry{Image: sourceImage},
+					},
+				},
+				Status: v1alpha1.BuildStatus{
+					Status: corev1alpha1.Status{
+						Conditions: corev1alpha1.Conditions{
+							{Type: corev1alpha1.ConditionSucceeded, Status: corev1.ConditionTrue},
+						},
+					},
+					LatestImage: latestImage,
+				},
+			}
+		}
+
+		build1 := makeBuild("1", "registry.io/some-repo@sha256:1111111111111111111111111111111111111111111111111111111111111111", "registry.io/some-repo-source@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+		build2 := makeBuild("2", "registry.io/some-repo@sha256:2222222222222222222222222222222222222222222222222222222222222222", "registry.io/some-repo-source@sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+		build3 := makeBuild("3", "registry.io/some-repo@sha256:2222222222222222222222222222222222222222222222222222222222222222", "registry.io/shared-source@sha256:cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc")
+
+		expectedDeletes := []clientgotesting.DeleteActionImpl{
+			{ActionImpl: clientgotesting.ActionImpl{Namespace: defaultNamespace}, Name: "some-image"},
+			{ActionImpl: clientgotesting.ActionImpl{Namespace: defaultNamespace}, Name: "some-image-build-1"},
+			{ActionImpl: clientgotesting.ActionImpl{Namespace: defaultNamespace}, Name: "some-image-build-2"},
+			{ActionImpl: clientgotesting.ActionImpl{Namespace: defaultNamespace}, Name: "some-image-build-3"},
+		}
+
+		it("deletes the built and uploaded source images recorded by the builds after confirmation", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{img, build1, build2, build3},
+				Args:    []string{"some-image", "--delete-registry-images", "--delete-source-images"},
+				ExpectedOutput: `The following registry images will be deleted:
+	registry.io/some-repo@sha256:1111111111111111111111111111111111111111111111111111111111111111
+	registry.io/some-repo-source@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa
+	registry.io/some-repo@sha256:2222222222222222222222222222222222222222222222222222222222222222
+	registry.io/some-repo-source@sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb
+Deleting registry images...
+	Deleting 'registry.io/some-repo@sha256:1111111111111111111111111111111111111111111111111111111111111111'
+	Deleting 'registry.io/some-repo-source@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa'
+	Deleting 'registry.io/some-repo@sha256:2222222222222222222222222222222222222222222222222222222222222222'
+	Deleting 'registry.io/some-repo-source@sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb'
+Image "some-image" deleted
+`,
+				ExpectDeletes: expectedDeletes,
+			}.TestKpack(t, cmdFunc)
+
+			require.NoError(t, fakeConfirmationProvider.WasRequestedWithMsg("Please confirm deletion of 4 registry image(s) by typing 'y': "))
+		})
+
+		it("keeps the latest image and its source when keep-latest is provided", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{img, build1, build2, build3},
+				Args:    []string{"some-image", "--delete-registry-images", "--delete-source-images", "--keep-latest", "--force"},
+				ExpectedOutput: `Deleting registry images...
+	Deleting 'registry.io/some-repo@sha256:1111111111111111111111111111111111111111111111111111111111111111'
+	Deleting 'registry.io/some-repo-source@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa'
+Image "some-image" deleted
+`,
+				ExpectDeletes: expectedDeletes,
+			}.TestKpack(t, cmdFunc)
+
+			require.False(t, fakeConfirmationProvider.WasRequested())
+			require.Equal(t, []string{"registry.io/some-repo@sha256:1111111111111111111111111111111111111111111111111111111111111111", "registry.io/some-repo-source@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}, fakeDeleter.Calls())
+		})
+
+		it("lists what would be deleted on dry run", func() {
+			fakeDeleter.SetSkip(true)
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{img, build1, build2, build3},
+				Args:    []string{"some-image", "--delete-registry-images", "--dry-run"},
+				ExpectedOutput: `Deleting registry images... (dry run)
+	Skipping deletion of 'registry.io/some-repo@sha256:1111111111111111111111111111111111111111111111111111111111111111'
+	Skipping deletion of 'registry.io/some-repo@sha256:2222222222222222222222222222222222222222222222222222222222222222'
+Image "some-image" deleted (dry run)
+`,
+			}.TestKpack(t, cmdFunc)
+
+			require.False(t, fakeConfirmationProvider.WasRequested())
+		})
+
+		it("does not delete when confirmation is not given", func() {
+			fakeConfirmationProvider = commandsfakes.NewFakeConfirmationProvider(false, nil)
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{img, build1},
+				Args:    []string{"some-image", "--delete-registry-images"},
+				ExpectedOutput: `The following registry images will be deleted:
+	registry.io/some-repo@sha256:1111111111111111111111111111111111111111111111111111111111111111
+Skipping image deletion
+`,
+			}.TestKpack(t, cmdFunc)
+
+			require.Len(t, fakeDeleter.Calls(), 0)
+		})
+
+		it("reports registry images that could not be deleted", func() {
+			fakeDeleter.SetError("registry.io/some-repo@sha256:1111111111111111111111111111111111111111111111111111111111111111", errors.New("some-error"))
+
+			testhelpers.CommandTest{
+				Objects:   []runtime.Object{img, build1, build2},
+				Args:      []string{"some-image", "--delete-registry-images", "--force"},
+				ExpectErr: true,
+				ExpectedOutput: `Deleting registry images...
+	Deleting 'registry.io/some-repo@sha256:1111111111111111111111111111111111111111111111111111111111111111'
+Warning: some-error
+	Deleting 'registry.io/some-repo@sha256:2222222222222222222222222222222222222222222222222222222222222222'
+Image "some-image" deleted
+Error: failed to delete 1 registry image(s)
+`,
+				ExpectDeletes: expectedDeletes[:3],
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("returns an error when keep-latest is provided without a delete flag", func() {
+			testhelpers.CommandTest{
+				Objects:        []runtime.Object{img},
+				Args:           []string{"some-image", "--keep-latest"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: keep-latest requires delete-registry-images or delete-source-images\n",
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("returns an error when deleting multiple images", func() {
+			testhelpers.CommandTest{
+				Objects:        []runtime.Object{img},
+				Args:           []string{"-A", "--delete-registry-images"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: registry images cannot be deleted when deleting multiple images\n",
+			}.TestKpack(t, cmdFunc)
+		})
+	})
 }
@@ -0,0 +1,211 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/detect"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+func NewDetectCommand(clientSetProvider k8s.ClientSetProvider, namespaceProvider k8s.NamespaceProvider, newBuilderCache func() (detect.BuilderCache, error)) *cobra.Command {
+	var (
+		namespace      string
+		localPath      string
+		builder        string
+		clusterBuilder string
+		offline        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "detect --local-path <path>",
+		Short: "Preview which buildpacks would detect local source code",
+		Long: `Previews which group of the builder order is likely to pass detection for local source code, before an image is created.
+
+Detection is approximated on the client using well-known files, such as "pom.xml" for java, "package.json" for node.js
+and "go.mod" for go. Buildpacks that are not known to these heuristics are reported as unknown.
+The result is a preview: the buildpacks run their own detection when the image is built.
+
+The builder order and buildpack metadata are fetched from the cluster and cached locally.
+Use "--offline" to detect with the cached metadata without contacting the cluster,
+a kubeconfig is then only read for the namespace of a builder when "--namespace" is not provided.
+
+The builder defaults to the "default" cluster builder.
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example: `kp image detect --local-path .
+kp image detect --local-path /path/to/local/source/code --cluster-builder my-cluster-builder
+kp image detect --local-path . --builder my-builder -n my-namespace --offline`,
+		Args:         commands.ExactArgsWithUsage(0),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if builder != "" && clusterBuilder != "" {
+				return errors.New("must provide one of builder or cluster-builder")
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			cache, err := newBuilderCache()
+			if err != nil {
+				return err
+			}
+
+			var cs k8s.ClientSet
+			if !offline {
+				if cs, err = clientSetProvider.GetClientSet(namespace); err != nil {
+					return err
+				}
+			}
+
+			kind, builderNamespace, name := v1alpha1.ClusterBuilderKind, "", clusterBuilder
+			if builder != "" {
+				kind, name = v1alpha1.BuilderKind, builder
+				if builderNamespace, err = namespaceProvider.GetNamespace(namespace); err != nil {
+					return err
+				}
+			} else if name == "" {
+				name = "default"
+			}
+
+			var info detect.BuilderInfo
+			if offline {
+				info, err = cache.Load(kind, builderNamespace, name)
+			} else {
+				info, err = fetchBuilderInfo(cs, kind, builderNamespace, name)
+			}
+			if err != nil {
+				return err
+			}
+
+			if len(info.Order) == 0 {
+				return errors.Errorf("%s %q has no buildpack order, it may not be ready", kind, name)
+			}
+
+			if !offline {
+				if err := cache.Save(info); err != nil {
+					if err := ch.Printlnf("Warning: unable to cache builder metadata: %s", err); err != nil {
+						return err
+					}
+				}
+			}
+
+			result, err := detect.Detect(localPath, info.Order, info.Buildpacks)
+			if err != nil {
+				return err
+			}
+
+			return displayDetectResult(cmd.OutOrStdout(), result)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace of the builder")
+	cmd.Flags().StringVar(&localPath, "local-path", ".", "path to local source code")
+	cmd.Flags().StringVarP(&builder, "builder", "b", "", "builder name")
+	cmd.Flags().StringVarP(&clusterBuilder, "cluster-builder", "c", "", "cluster builder name (default \"default\")")
+	cmd.Flags().BoolVar(&offline, "offline", false, "use cached builder metadata instead of contacting the cluster")
+	return cmd
+}
+
+func fetchBuilderInfo(cs k8s.ClientSet, kind, namespace, name string) (detect.BuilderInfo, error) {
+	info := detect.BuilderInfo{Kind: kind, Namespace: namespace, Name: name}
+
+	if kind == v1alpha1.BuilderKind {
+		b, err := cs.KpackClient.KpackV1alpha1().Builders(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return info, err
+		}
+		info.Order, info.Buildpacks = b.Spec.Order, b.Status.BuilderMetadata
+		return info, nil
+	}
+
+	cb, err := cs.KpackClient.KpackV1alpha1().ClusterBuilders().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return info, err
+	}
+	info.Order, info.Buildpacks = cb.Spec.Order, cb.Status.BuilderMetadata
+	return info, nil
+}
+
+func displayDetectResult(out io.Writer, result detect.OrderResult) error {
+	writer, err := commands.NewTableWriter(out, "Group", "Buildpack", "Version", "Optional", "Detect")
+	if err != nil {
+		return err
+	}
+
+	for i, group := range result.Groups {
+		for _, bp := range group.Buildpacks {
+			optional := "no"
+			if bp.Optional {
+				optional = "yes"
+			}
+
+			detected := string(bp.Result)
+			if bp.Evidence != "" {
+				detected = fmt.Sprintf("%s (%s)", detected, bp.Evidence)
+			}
+
+			err := writer.AddRow(strconv.Itoa(i+1), bp.Id, bp.Version, optional, detected)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := writer.Write(); err != nil {
+		return err
+	}
+
+	// groups before the match that could not be checked may still pass first
+	last := len(result.Groups)
+	if result.Match != -1 {
+		last = result.Match
+	}
+	for i := 0; i < last; i++ {
+		if result.Groups[i].Result == detect.Unknown {
+			if _, err := fmt.Fprintf(out, "Group %d may match, its buildpacks could not be checked: %s\n", i+1, groupText(result.Groups[i], uncheckedBuildpack)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if result.Match == -1 {
+		_, err = fmt.Fprintln(out, "No group is likely to match")
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "Group %d is likely to match: %s\n", result.Match+1, groupText(result.Groups[result.Match], participatingBuildpack))
+	return err
+}
+
+func groupText(group detect.GroupResult, include func(detect.BuildpackResult) bool) string {
+	var ids []string
+	for _, bp := range group.Buildpacks {
+		if include(bp) {
+			ids = append(ids, bp.Id)
+		}
+	}
+	return strings.Join(ids, ", ")
+}
+
+func uncheckedBuildpack(bp detect.BuildpackResult) bool {
+	return bp.Result == detect.Unknown && !bp.Optional
+}
+
+// participatingBuildpack reports whether a buildpack runs when its group
+// passes: optional buildpacks only run when they pass detection.
+func participatingBuildpack(bp detect.BuildpackResult) bool {
+	return !bp.Optional || bp.Result == detect.Pass
+}
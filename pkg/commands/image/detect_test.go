@@ -0,0 +1,210 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/pkg/errors"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/detect"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestImageDetectCommand(t *testing.T) {
+	spec.Run(t, "TestImageDetectCommand", testImageDetectCommand)
+}
+
+func testImageDetectCommand(t *testing.T, when spec.G, it spec.S) {
+	const defaultNamespace = "some-default-namespace"
+
+	var (
+		sourceDir string
+		cache     detect.FileCache
+	)
+
+	it.Before(func() {
+		var err error
+		sourceDir, err = ioutil.TempDir("", "image-detect-test")
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, "package.json"), []byte("{}"), 0644))
+
+		cache = detect.FileCache{Dir: filepath.Join(sourceDir, ".cache")}
+	})
+
+	it.After(func() {
+		require.NoError(t, os.RemoveAll(sourceDir))
+	})
+
+	order := []v1alpha1.OrderEntry{
+		{
+			Group: []v1alpha1.BuildpackRef{
+				{BuildpackInfo: v1alpha1.BuildpackInfo{Id: "paketo-buildpacks/java"}},
+			},
+		},
+		{
+			Group: []v1alpha1.BuildpackRef{
+				{BuildpackInfo: v1alpha1.BuildpackInfo{Id: "some-org/custom"}},
+			},
+		},
+		{
+			Group: []v1alpha1.BuildpackRef{
+				{BuildpackInfo: v1alpha1.BuildpackInfo{Id: "paketo-buildpacks/nodejs"}},
+				{BuildpackInfo: v1alpha1.BuildpackInfo{Id: "paketo-buildpacks/procfile"}, Optional: true},
+			},
+		},
+	}
+
+	metadata := v1alpha1.BuildpackMetadataList{
+		{Id: "paketo-buildpacks/java", Version: "4.0.0"},
+		{Id: "some-org/custom", Version: "1.0.0"},
+		{Id: "paketo-buildpacks/nodejs", Version: "0.0.5"},
+		{Id: "paketo-buildpacks/procfile", Version: "2.0.0"},
+	}
+
+	clusterBuilder := &v1alpha1.ClusterBuilder{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterBuilderSpec{
+			BuilderSpec: v1alpha1.BuilderSpec{Order: order},
+		},
+		Status: v1alpha1.BuilderStatus{BuilderMetadata: metadata},
+	}
+
+	builder := &v1alpha1.Builder{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-builder", Namespace: defaultNamespace},
+		Spec: v1alpha1.NamespacedBuilderSpec{
+			BuilderSpec: v1alpha1.BuilderSpec{Order: order[2:]},
+		},
+		Status: v1alpha1.BuilderStatus{BuilderMetadata: metadata},
+	}
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return image.NewDetectCommand(clientSetProvider, clientSetProvider, func() (detect.BuilderCache, error) {
+			return cache, nil
+		})
+	}
+
+	const expectedClusterBuilderOutput = `GROUP    BUILDPACK                     VERSION    OPTIONAL    DETECT
+1        paketo-buildpacks/java        4.0.0      no          fail
+2        some-org/custom               1.0.0      no          unknown
+3        paketo-buildpacks/nodejs      0.0.5      no          pass (package.json)
+3        paketo-buildpacks/procfile    2.0.0      yes         fail
+
+Group 2 may match, its buildpacks could not be checked: some-org/custom
+Group 3 is likely to match: paketo-buildpacks/nodejs
+`
+
+	it("detects against the default cluster builder and caches its metadata", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{clusterBuilder},
+			Args:           []string{"--local-path", sourceDir},
+			ExpectedOutput: expectedClusterBuilderOutput,
+		}.TestKpack(t, cmdFunc)
+
+		info, err := cache.Load(v1alpha1.ClusterBuilderKind, "", "default")
+		require.NoError(t, err)
+		require.Equal(t, order, info.Order)
+	})
+
+	it("detects against a namespaced builder", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{builder},
+			Args:    []string{"--local-path", sourceDir, "--builder", "some-builder"},
+			ExpectedOutput: `GROUP    BUILDPACK                     VERSION    OPTIONAL    DETECT
+1        paketo-buildpacks/nodejs      0.0.5      no          pass (package.json)
+1        paketo-buildpacks/procfile    2.0.0      yes         fail
+
+Group 1 is likely to match: paketo-buildpacks/nodejs
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("uses cached metadata when offline", func() {
+		require.NoError(t, cache.Save(detect.BuilderInfo{
+			Kind:       v1alpha1.ClusterBuilderKind,
+			Name:       "default",
+			Order:      order,
+			Buildpacks: metadata,
+		}))
+
+		testhelpers.CommandTest{
+			Args:           []string{"--local-path", sourceDir, "--offline"},
+			ExpectedOutput: expectedClusterBuilderOutput,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("does not create clients when offline", func() {
+		require.NoError(t, cache.Save(detect.BuilderInfo{
+			Kind:       v1alpha1.BuilderKind,
+			Namespace:  "some-namespace",
+			Name:       "some-builder",
+			Order:      order[2:],
+			Buildpacks: metadata,
+		}))
+
+		cmd := image.NewDetectCommand(unreachableClusterProvider{}, testhelpers.GetFakeKpackProvider(nil, defaultNamespace), func() (detect.BuilderCache, error) {
+			return cache, nil
+		})
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(out)
+		cmd.SetArgs([]string{"--local-path", sourceDir, "--builder", "some-builder", "-n", "some-namespace", "--offline"})
+
+		require.NoError(t, cmd.Execute())
+		require.Equal(t, `GROUP    BUILDPACK                     VERSION    OPTIONAL    DETECT
+1        paketo-buildpacks/nodejs      0.0.5      no          pass (package.json)
+1        paketo-buildpacks/procfile    2.0.0      yes         fail
+
+Group 1 is likely to match: paketo-buildpacks/nodejs
+`, out.String())
+	})
+
+	it("errors when offline without cached metadata", func() {
+		testhelpers.CommandTest{
+			Args:           []string{"--local-path", sourceDir, "--cluster-builder", "other", "--offline"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: no cached metadata for ClusterBuilder \"other\", run without --offline to fetch it\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when the builder has no order", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{&v1alpha1.ClusterBuilder{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			}},
+			Args:           []string{"--local-path", sourceDir},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: ClusterBuilder \"default\" has no buildpack order, it may not be ready\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when both builder and cluster builder are provided", func() {
+		testhelpers.CommandTest{
+			Args:           []string{"--builder", "some-builder", "--cluster-builder", "default"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: must provide one of builder or cluster-builder\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
+
+type unreachableClusterProvider struct{}
+
+func (unreachableClusterProvider) GetClientSet(string) (k8s.ClientSet, error) {
+	return k8s.ClientSet{}, errors.New("no usable kubeconfig")
+}
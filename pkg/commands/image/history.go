@@ -0,0 +1,208 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+const (
+	shortCommitLength = 7
+	shortDigestLength = len("sha256:") + 12
+)
+
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+type historyEntry struct {
+	Build            string                  `json:"build"`
+	Status           string                  `json:"status"`
+	Reasons          []string                `json:"reasons,omitempty"`
+	Duration         string                  `json:"duration,omitempty"`
+	Revision         string                  `json:"revision,omitempty"`
+	RunImage         string                  `json:"runImage,omitempty"`
+	BuildpackChanges []build.BuildpackChange `json:"buildpackChanges,omitempty"`
+}
+
+func NewHistoryCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	var (
+		namespace string
+		output    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history <name>",
+		Short: "Display the build history of an image",
+		Long: `Prints a timeline of every build of an image in the provided namespace.
+
+Each build is shown with its status, reason, duration, source revision and run image digest.
+The buildpack changes column lists the buildpacks that were added, removed or changed version
+since the previous build that reported buildpacks.
+
+The "--output json" flag prints the full history, including full revisions and run images, as JSON.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example: `kp image history my-image
+kp image history my-image -n my-namespace --output json`,
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "" && output != k8s.FormatJSON {
+				return errors.Errorf("unsupported output format: %q, supported formats are json", output)
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
+				LabelSelector: v1alpha1.ImageLabel + "=" + img.Name,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(buildList.Items) == 0 {
+				return errors.New("no builds found")
+			}
+
+			sort.Slice(buildList.Items, build.Sort(buildList.Items))
+			history := makeHistory(buildList.Items)
+
+			if output == k8s.FormatJSON {
+				return printHistoryJSON(cmd.OutOrStdout(), history)
+			}
+			return displayHistoryTable(cmd.OutOrStdout(), history)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output format, supported formats are: json")
+
+	return cmd
+}
+
+func makeHistory(builds []v1alpha1.Build) []historyEntry {
+	var (
+		history  []historyEntry
+		previous v1alpha1.BuildpackMetadataList
+	)
+
+	for _, bld := range builds {
+		entry := historyEntry{
+			Build:    bld.Labels[v1alpha1.BuildNumberLabel],
+			Status:   build.Status(bld),
+			Reasons:  build.Reasons(bld),
+			Revision: getBuildRevision(bld),
+			RunImage: bld.Status.Stack.RunImage,
+		}
+
+		if d, ok := build.Duration(bld); ok {
+			entry.Duration = d.Round(time.Second).String()
+		}
+
+		if len(bld.Status.BuildMetadata) > 0 {
+			if previous != nil {
+				entry.BuildpackChanges = build.DiffBuildpacks(previous, bld.Status.BuildMetadata)
+			}
+			previous = bld.Status.BuildMetadata
+		}
+
+		history = append(history, entry)
+	}
+
+	return history
+}
+
+func getBuildRevision(bld v1alpha1.Build) string {
+	switch {
+	case bld.Spec.Source.Git != nil:
+		return bld.Spec.Source.Git.Revision
+	case bld.Spec.Source.Blob != nil:
+		return bld.Spec.Source.Blob.URL
+	case bld.Spec.Source.Registry != nil:
+		return bld.Spec.Source.Registry.Image
+	default:
+		return ""
+	}
+}
+
+func displayHistoryTable(out io.Writer, history []historyEntry) error {
+	writer, err := commands.NewTableWriter(out, "Build", "Status", "Reason", "Duration", "Revision", "Run Image", "Buildpack Changes")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range history {
+		var changes []string
+		for _, c := range entry.BuildpackChanges {
+			changes = append(changes, c.String())
+		}
+
+		err := writer.AddRow(
+			entry.Build,
+			entry.Status,
+			strings.Join(entry.Reasons, ","),
+			entry.Duration,
+			shortRevision(entry.Revision),
+			shortDigest(entry.RunImage),
+			strings.Join(changes, ", "),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Write()
+}
+
+func printHistoryJSON(out io.Writer, history []historyEntry) error {
+	buf, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, string(buf))
+	return err
+}
+
+func shortRevision(revision string) string {
+	if commitPattern.MatchString(revision) {
+		return revision[:shortCommitLength]
+	}
+	return revision
+}
+
+func shortDigest(ref string) string {
+	idx := strings.Index(ref, "@")
+	if idx == -1 {
+		return ref
+	}
+
+	digest := ref[idx+1:]
+	if len(digest) > shortDigestLength {
+		return digest[:shortDigestLength]
+	}
+	return digest
+}
@@ -0,0 +1,173 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestImageHistoryCommand(t *testing.T) {
+	spec.Run(t, "TestImageHistoryCommand", testImageHistoryCommand)
+}
+
+func testImageHistoryCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		commitOne        = "1111111111111111111111111111111111111111"
+		commitTwo        = "2222222222222222222222222222222222222222"
+		runImageOne      = "registry.io/run@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+		runImageTwo      = "registry.io/run@sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	)
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return image.NewHistoryCommand(clientSetProvider)
+	}
+
+	img := &v1alpha1.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "some-image",
+			Namespace: defaultNamespace,
+		},
+	}
+
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	makeBuild := func(number string, created time.Time, duration time.Duration, status corev1.ConditionStatus, reason, revision, runImage string, buildpacks v1alpha1.BuildpackMetadataList) *v1alpha1.Build {
+		return &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "some-image-build-" + number,
+				Namespace:         defaultNamespace,
+				CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       "some-image",
+					v1alpha1.BuildNumberLabel: number,
+				},
+				Annotations: map[string]string{
+					v1alpha1.BuildReasonAnnotation: reason,
+				},
+			},
+			Spec: v1alpha1.BuildSpec{
+				Source: v1alpha1.SourceConfig{
+					Git: &v1alpha1.Git{URL: "https://github.com/some/repo", Revision: revision},
+				},
+			},
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{
+						{
+							Type:               corev1alpha1.ConditionSucceeded,
+							Status:             status,
+							LastTransitionTime: corev1alpha1.VolatileTime{Inner: metav1.NewTime(created.Add(duration))},
+						},
+					},
+				},
+				BuildMetadata: buildpacks,
+				Stack:         v1alpha1.BuildStack{RunImage: runImage},
+			},
+		}
+	}
+
+	build1 := makeBuild("1", start, 90*time.Second, corev1.ConditionTrue, "CONFIG", commitOne, runImageOne, v1alpha1.BuildpackMetadataList{
+		{Id: "some/node-engine", Version: "0.1.0"},
+		{Id: "some/npm", Version: "1.0.0"},
+	})
+	build2 := makeBuild("2", start.Add(time.Hour), 2*time.Minute, corev1.ConditionTrue, "STACK,BUILDPACK", commitOne, runImageTwo, v1alpha1.BuildpackMetadataList{
+		{Id: "some/node-engine", Version: "0.2.0"},
+		{Id: "some/yarn", Version: "2.0.0"},
+	})
+	build3 := makeBuild("3", start.Add(2*time.Hour), 30*time.Second, corev1.ConditionFalse, "COMMIT", commitTwo, "", nil)
+	build4 := makeBuild("4", start.Add(3*time.Hour), 0, corev1.ConditionUnknown, "COMMIT", "some-branch", "", nil)
+
+	it("displays a table of every build with buildpack changes", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{img, build3, build1, build4, build2},
+			Args:    []string{"some-image"},
+			ExpectedOutput: `BUILD    STATUS      REASON             DURATION    REVISION       RUN IMAGE              BUILDPACK CHANGES
+1        SUCCESS     CONFIG             1m30s       1111111        sha256:aaaaaaaaaaaa    
+2        SUCCESS     STACK,BUILDPACK    2m0s        1111111        sha256:bbbbbbbbbbbb    some/node-engine 0.1.0 -> 0.2.0, -some/npm 1.0.0, +some/yarn 2.0.0
+3        FAILURE     COMMIT             30s         2222222                               
+4        BUILDING    COMMIT                         some-branch                           
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("prints the history as json", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{img, build1, build2},
+			Args:    []string{"some-image", "-o", "json"},
+			ExpectedOutput: `[
+  {
+    "build": "1",
+    "status": "SUCCESS",
+    "reasons": [
+      "CONFIG"
+    ],
+    "duration": "1m30s",
+    "revision": "1111111111111111111111111111111111111111",
+    "runImage": "registry.io/run@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+  },
+  {
+    "build": "2",
+    "status": "SUCCESS",
+    "reasons": [
+      "STACK",
+      "BUILDPACK"
+    ],
+    "duration": "2m0s",
+    "revision": "1111111111111111111111111111111111111111",
+    "runImage": "registry.io/run@sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+    "buildpackChanges": [
+      {
+        "id": "some/node-engine",
+        "oldVersion": "0.1.0",
+        "newVersion": "0.2.0"
+      },
+      {
+        "id": "some/npm",
+        "oldVersion": "1.0.0"
+      },
+      {
+        "id": "some/yarn",
+        "newVersion": "2.0.0"
+      }
+    ]
+  }
+]
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error when the image has no builds", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{img},
+			Args:           []string{"some-image"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: no builds found\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error for unsupported output formats", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{img, build1},
+			Args:           []string{"some-image", "-o", "yaml"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: unsupported output format: \"yaml\", supported formats are json\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
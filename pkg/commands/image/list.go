@@ -4,19 +4,54 @@
 package image
 
 import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/pivotal/build-service-cli/pkg/commands"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 )
 
+const (
+	sortByName          = "name"
+	sortByLastBuildTime = "last-build-time"
+
+	outputWide = "wide"
+
+	filterBuilder = "builder"
+	filterReady   = "ready"
+	filterSource  = "source"
+
+	sourceTypeGit      = "git"
+	sourceTypeBlob     = "blob"
+	sourceTypeRegistry = "registry"
+)
+
+type listOptions struct {
+	allNamespaces bool
+	selector      string
+	filters       []string
+	sortBy        string
+	output        string
+	watch         bool
+}
+
 func NewListCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 	var (
 		namespace string
+		opts      listOptions
 	)
 
 	cmd := &cobra.Command{
@@ -24,41 +59,332 @@ func NewListCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		Short: "List images",
 		Long: `Prints a table of the most important information about images in the provided namespace.
 
-The namespace defaults to the kubernetes current-context namespace.`,
-		Example: "kp image list\nkp image list -n my-namespace",
+The namespace defaults to the kubernetes current-context namespace.
+
+Images may be filtered with the "--filter" flag using one of the following keys:
+
+  "builder" to match the name of the builder or cluster builder
+  "ready" to match the ready status (True, False or Unknown)
+  "source" to match the source type (git, blob or registry)
+
+For each filter, supply the "--filter" flag followed by the key value pair.
+For example, "--filter builder=my-builder --filter ready=False".
+With "-o json" or "-o yaml", an empty list is printed when no images match.
+
+The "--watch" flag keeps the command running and updates the table as images change.
+The table is redrawn in place on a terminal. With "-o json", one json event is printed per change.`,
+		Example: `kp image list
+kp image list -n my-namespace
+kp image list --all-namespaces -l team=my-team
+kp image list --filter ready=False --sort-by last-build-time -o wide
+kp image list --watch`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cs, err := clientSetProvider.GetClientSet(namespace)
 			if err != nil {
 				return err
 			}
 
-			imageList, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).List(metav1.ListOptions{})
+			filter, err := parseImageFilters(opts.filters)
 			if err != nil {
 				return err
 			}
 
-			if len(imageList.Items) == 0 {
+			if opts.watch && opts.output == k8s.FormatYAML {
+				return errors.New("watch supports wide and json output formats")
+			}
+
+			listNamespace := cs.Namespace
+			if opts.allNamespaces {
+				listNamespace = metav1.NamespaceAll
+			}
+
+			imageList, err := cs.KpackClient.KpackV1alpha1().Images(listNamespace).List(metav1.ListOptions{
+				LabelSelector: opts.selector,
+			})
+			if err != nil {
+				return err
+			}
+
+			var buildTimes *latestBuildTimes
+			if opts.sortBy == sortByLastBuildTime {
+				buildTimes, err = getLatestBuildTimes(cs, listNamespace)
+				if err != nil {
+					return err
+				}
+			}
+
+			if opts.watch {
+				return watchImages(cmd, cs, listNamespace, imageList, filter, buildTimes, opts)
+			}
+
+			imageList.Items = filter.apply(imageList.Items)
+
+			isTable := opts.output == "" || opts.output == outputWide
+			if len(imageList.Items) == 0 && isTable {
 				return errors.New("no images found")
-			} else {
-				return displayImagesTable(cmd, imageList)
 			}
 
+			if err := sortImages(imageList.Items, opts.sortBy, buildTimes); err != nil {
+				return err
+			}
+
+			switch opts.output {
+			case "", outputWide:
+				return displayImagesTable(cmd.OutOrStdout(), imageList.Items, opts)
+			case k8s.FormatJSON, k8s.FormatYAML:
+				return printImageList(cmd, imageList, opts.output)
+			default:
+				return errors.Errorf("unsupported output format: %q, supported formats are wide, json, yaml", opts.output)
+			}
 		},
 		SilenceUsage: true,
 	}
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().BoolVarP(&opts.allNamespaces, "all-namespaces", "A", false, "list images across all namespaces")
+	cmd.Flags().StringVarP(&opts.selector, "selector", "l", "", "label selector to filter images (e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().StringArrayVar(&opts.filters, "filter", []string{}, "filter images by builder, ready or source (e.g. --filter ready=False)")
+	cmd.Flags().StringVar(&opts.sortBy, "sort-by", sortByName, "sort images by name or last-build-time")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "output format; supported formats are: wide, json, yaml")
+	cmd.Flags().BoolVarP(&opts.watch, commands.WatchFlag, "w", false, "watch for changes to images")
 
 	return cmd
 }
 
-func displayImagesTable(cmd *cobra.Command, imageList *v1alpha1.ImageList) error {
-	writer, err := commands.NewTableWriter(cmd.OutOrStdout(), "NAME", "READY", "LATEST IMAGE")
+type imageFilter map[string]string
+
+func parseImageFilters(filters []string) (imageFilter, error) {
+	filter := imageFilter{}
+	for _, f := range filters {
+		idx := strings.Index(f, "=")
+		if idx == -1 {
+			return nil, errors.Errorf("filters are improperly formatted, expected key=value: %q", f)
+		}
+
+		key, value := f[:idx], f[idx+1:]
+		switch key {
+		case filterBuilder, filterReady, filterSource:
+			filter[key] = value
+		default:
+			return nil, errors.Errorf("unsupported filter %q, supported filters are builder, ready, source", key)
+		}
+	}
+	return filter, nil
+}
+
+func (f imageFilter) apply(images []v1alpha1.Image) []v1alpha1.Image {
+	filtered := []v1alpha1.Image{}
+	for _, img := range images {
+		if f.matches(img) {
+			filtered = append(filtered, img)
+		}
+	}
+	return filtered
+}
+
+func (f imageFilter) matches(img v1alpha1.Image) bool {
+	if builder, ok := f[filterBuilder]; ok && img.Spec.Builder.Name != builder {
+		return false
+	}
+
+	if ready, ok := f[filterReady]; ok && !strings.EqualFold(getReadyText(img), ready) {
+		return false
+	}
+
+	if source, ok := f[filterSource]; ok && !strings.EqualFold(getSourceType(img), source) {
+		return false
+	}
+
+	return true
+}
+
+// sortImages sorts the images by name or by the creation time of their
+// latest build, the build times are only needed for the latter.
+func sortImages(images []v1alpha1.Image, sortBy string, buildTimes *latestBuildTimes) error {
+	switch sortBy {
+	case sortByName:
+		sort.SliceStable(images, func(i, j int) bool {
+			if images[i].Namespace != images[j].Namespace {
+				return images[i].Namespace < images[j].Namespace
+			}
+			return images[i].Name < images[j].Name
+		})
+		return nil
+	case sortByLastBuildTime:
+		sort.SliceStable(images, func(i, j int) bool {
+			return buildTimes.get(images[i]).Before(buildTimes.get(images[j]))
+		})
+		return nil
+	default:
+		return errors.Errorf("unsupported sort %q, images can be sorted by name or last-build-time", sortBy)
+	}
+}
+
+// latestBuildTimes caches the latest build of every image, keyed by imageKey,
+// so that the builds are listed once and not for every watch event.
+type latestBuildTimes struct {
+	cs     k8s.ClientSet
+	builds map[string]latestBuild
+}
+
+type latestBuild struct {
+	name    string
+	created time.Time
+}
+
+func getLatestBuildTimes(cs k8s.ClientSet, namespace string) (*latestBuildTimes, error) {
+	buildList, err := cs.KpackClient.KpackV1alpha1().Builds(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	buildTimes := &latestBuildTimes{cs: cs, builds: map[string]latestBuild{}}
+	for _, bld := range buildList.Items {
+		key := fmt.Sprintf("%s/%s", bld.Namespace, bld.Labels[v1alpha1.ImageLabel])
+		if bld.CreationTimestamp.After(buildTimes.builds[key].created) {
+			buildTimes.builds[key] = latestBuild{name: bld.Name, created: bld.CreationTimestamp.Time}
+		}
+	}
+	return buildTimes, nil
+}
+
+func (t *latestBuildTimes) get(img v1alpha1.Image) time.Time {
+	return t.builds[imageKey(img)].created
+}
+
+// update fetches the latest build of an image when it is not the cached one.
+func (t *latestBuildTimes) update(img v1alpha1.Image) error {
+	ref := img.Status.LatestBuildRef
+	if ref == "" || t.builds[imageKey(img)].name == ref {
+		return nil
+	}
+
+	bld, err := t.cs.KpackClient.KpackV1alpha1().Builds(img.Namespace).Get(ref, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	t.builds[imageKey(img)] = latestBuild{name: bld.Name, created: bld.CreationTimestamp.Time}
+	return nil
+}
+
+func (t *latestBuildTimes) delete(img v1alpha1.Image) {
+	delete(t.builds, imageKey(img))
+}
+
+func imageKey(img v1alpha1.Image) string {
+	return fmt.Sprintf("%s/%s", img.Namespace, img.Name)
+}
+
+// watchImages prints the listed images and then updates the output for
+// every change reported by a watch started at the list resource version.
+func watchImages(cmd *cobra.Command, cs k8s.ClientSet, namespace string, imageList *v1alpha1.ImageList, filter imageFilter, buildTimes *latestBuildTimes, opts listOptions) error {
+	w, err := cs.KpackClient.KpackV1alpha1().Images(namespace).Watch(metav1.ListOptions{
+		LabelSelector:   opts.selector,
+		ResourceVersion: imageList.ResourceVersion,
+	})
 	if err != nil {
 		return err
 	}
 
+	out := cmd.OutOrStdout()
+
+	if opts.output == k8s.FormatJSON {
+		for _, img := range filter.apply(imageList.Items) {
+			if err := commands.WriteWatchEvent(out, watch.Added, setImageTypeMeta(img.DeepCopy())); err != nil {
+				return err
+			}
+		}
+
+		return commands.Watch(cmd.Context(), w, func(event watch.Event) error {
+			img, ok := event.Object.(*v1alpha1.Image)
+			if !ok || !filter.matches(*img) {
+				return nil
+			}
+			return commands.WriteWatchEvent(out, event.Type, setImageTypeMeta(img))
+		})
+	}
+
+	images := map[string]v1alpha1.Image{}
 	for _, img := range imageList.Items {
-		err := writer.AddRow(img.Name, getReadyText(img), img.Status.LatestImage)
+		images[imageKey(img)] = img
+	}
+
+	redrawer := commands.NewRedrawer(out)
+	draw := func() error {
+		var items []v1alpha1.Image
+		for _, img := range images {
+			items = append(items, img)
+		}
+
+		items = filter.apply(items)
+		if err := sortImages(items, opts.sortBy, buildTimes); err != nil {
+			return err
+		}
+
+		return redrawer.Redraw(func(out io.Writer) error {
+			return displayImagesTable(out, items, opts)
+		})
+	}
+
+	if err := draw(); err != nil {
+		return err
+	}
+
+	return commands.Watch(cmd.Context(), w, func(event watch.Event) error {
+		img, ok := event.Object.(*v1alpha1.Image)
+		if !ok {
+			return nil
+		}
+
+		if event.Type == watch.Deleted {
+			delete(images, imageKey(*img))
+			if buildTimes != nil {
+				buildTimes.delete(*img)
+			}
+		} else {
+			images[imageKey(*img)] = *img
+			if buildTimes != nil {
+				if err := buildTimes.update(*img); err != nil {
+					return err
+				}
+			}
+		}
+		return draw()
+	})
+}
+
+func displayImagesTable(out io.Writer, images []v1alpha1.Image, opts listOptions) error {
+	headers := []string{"Name", "Ready", "Latest Image"}
+	if opts.output == outputWide {
+		headers = append(headers, "Builder", "Source", "Last Build", "Last Build Reason", "Age")
+	}
+	if opts.allNamespaces {
+		headers = append([]string{"Namespace"}, headers...)
+	}
+
+	writer, err := commands.NewTableWriter(out, headers...)
+	if err != nil {
+		return err
+	}
+
+	for _, img := range images {
+		row := []string{img.Name, getReadyText(img), img.Status.LatestImage}
+		if opts.output == outputWide {
+			row = append(row,
+				getBuilderText(img),
+				getSourceText(img),
+				getLastBuildText(img),
+				img.Status.LatestBuildReason,
+				getAge(img.CreationTimestamp),
+			)
+		}
+		if opts.allNamespaces {
+			row = append([]string{img.Namespace}, row...)
+		}
+
+		err := writer.AddRow(row...)
 		if err != nil {
 			return err
 		}
@@ -67,6 +393,27 @@ func displayImagesTable(cmd *cobra.Command, imageList *v1alpha1.ImageList) error
 	return writer.Write()
 }
 
+func printImageList(cmd *cobra.Command, imageList *v1alpha1.ImageList, format string) error {
+	printer, err := k8s.NewObjectPrinter(format)
+	if err != nil {
+		return err
+	}
+
+	imageList.Kind = "ImageList"
+	imageList.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	for i := range imageList.Items {
+		setImageTypeMeta(&imageList.Items[i])
+	}
+
+	return printer.PrintObject(imageList, cmd.OutOrStdout())
+}
+
+func setImageTypeMeta(img *v1alpha1.Image) *v1alpha1.Image {
+	img.Kind = "Image"
+	img.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	return img
+}
+
 func getReadyText(img v1alpha1.Image) string {
 	cond := img.Status.GetCondition(corev1alpha1.ConditionReady)
 	if cond == nil {
@@ -74,3 +421,50 @@ func getReadyText(img v1alpha1.Image) string {
 	}
 	return string(cond.Status)
 }
+
+func getSourceType(img v1alpha1.Image) string {
+	switch {
+	case img.Spec.Source.Git != nil:
+		return sourceTypeGit
+	case img.Spec.Source.Blob != nil:
+		return sourceTypeBlob
+	case img.Spec.Source.Registry != nil:
+		return sourceTypeRegistry
+	default:
+		return ""
+	}
+}
+
+func getSourceText(img v1alpha1.Image) string {
+	switch {
+	case img.Spec.Source.Git != nil:
+		return fmt.Sprintf("%s@%s", img.Spec.Source.Git.URL, img.Spec.Source.Git.Revision)
+	case img.Spec.Source.Blob != nil:
+		return img.Spec.Source.Blob.URL
+	case img.Spec.Source.Registry != nil:
+		return img.Spec.Source.Registry.Image
+	default:
+		return ""
+	}
+}
+
+func getBuilderText(img v1alpha1.Image) string {
+	if img.Spec.Builder.Name == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", img.Spec.Builder.Kind, img.Spec.Builder.Name)
+}
+
+func getLastBuildText(img v1alpha1.Image) string {
+	if img.Status.BuildCounter == 0 {
+		return ""
+	}
+	return strconv.FormatInt(img.Status.BuildCounter, 10)
+}
+
+func getAge(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
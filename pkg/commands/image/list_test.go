@@ -4,16 +4,21 @@
 package image_test
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
 
 	"github.com/pivotal/build-service-cli/pkg/commands/image"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
@@ -26,7 +31,16 @@ func TestImageListCommand(t *testing.T) {
 func testImageListCommand(t *testing.T, when spec.G, it spec.S) {
 	const defaultNamespace = "some-default-namespace"
 
+	var imageWatcher *watch.FakeWatcher
+
+	it.Before(func() {
+		imageWatcher = nil
+	})
+
 	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		if imageWatcher != nil {
+			clientSet.PrependWatchReactor("images", k8stesting.DefaultWatchReactor(imageWatcher, nil))
+		}
 		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
 		return image.NewListCommand(clientSetProvider)
 	}
@@ -237,4 +251,324 @@ test-image-3    True       test-registry.io/test-image-3@sha256:abcdef123
 			})
 		})
 	})
+
+	when("filtering, sorting and output flags are provided", func() {
+		makeImage := func(namespace, name, builder string, ready corev1.ConditionStatus, source v1alpha1.SourceConfig) *v1alpha1.Image {
+			return &v1alpha1.Image{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.ImageSpec{
+					Builder: corev1.ObjectReference{
+						Kind: v1alpha1.ClusterBuilderKind,
+						Name: builder,
+					},
+					Source: source,
+				},
+				Status: v1alpha1.ImageStatus{
+					Status: corev1alpha1.Status{
+						Conditions: []corev1alpha1.Condition{
+							{
+								Type:   corev1alpha1.ConditionReady,
+								Status: ready,
+							},
+						},
+					},
+					LatestImage:       "test-registry.io/" + name + "@sha256:abcdef123",
+					BuildCounter:      2,
+					LatestBuildReason: "CONFIG",
+				},
+			}
+		}
+
+		gitSource := v1alpha1.SourceConfig{Git: &v1alpha1.Git{URL: "https://github.com/test/repo", Revision: "main"}}
+		blobSource := v1alpha1.SourceConfig{Blob: &v1alpha1.Blob{URL: "https://blob.io/source.zip"}}
+
+		image1 := makeImage(defaultNamespace, "test-image-1", "builder-a", corev1.ConditionTrue, gitSource)
+		image2 := makeImage(defaultNamespace, "test-image-2", "builder-b", corev1.ConditionFalse, blobSource)
+		image3 := makeImage("other-namespace", "test-image-3", "builder-a", corev1.ConditionFalse, gitSource)
+
+		it("lists images across all namespaces", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{image1, image2, image3},
+				Args:    []string{"-A"},
+				ExpectedOutput: `NAMESPACE                 NAME            READY    LATEST IMAGE
+other-namespace           test-image-3    False    test-registry.io/test-image-3@sha256:abcdef123
+some-default-namespace    test-image-1    True     test-registry.io/test-image-1@sha256:abcdef123
+some-default-namespace    test-image-2    False    test-registry.io/test-image-2@sha256:abcdef123
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("filters images by builder, ready status and source", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{image1, image2, image3},
+				Args:    []string{"-A", "--filter", "builder=builder-a", "--filter", "ready=false", "--filter", "source=git"},
+				ExpectedOutput: `NAMESPACE          NAME            READY    LATEST IMAGE
+other-namespace    test-image-3    False    test-registry.io/test-image-3@sha256:abcdef123
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("errors when no images match the filters", func() {
+			testhelpers.CommandTest{
+				Objects:        []runtime.Object{image1, image2},
+				Args:           []string{"--filter", "source=registry"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: no images found\n",
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("errors on an unsupported filter", func() {
+			testhelpers.CommandTest{
+				Objects:        []runtime.Object{image1},
+				Args:           []string{"--filter", "stack=some-stack"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: unsupported filter \"stack\", supported filters are builder, ready, source\n",
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("sorts images by last build time", func() {
+			older := &v1alpha1.Build{
+				ObjectMeta: v1.ObjectMeta{
+					Name:              "test-image-2-build-1",
+					Namespace:         defaultNamespace,
+					Labels:            map[string]string{v1alpha1.ImageLabel: "test-image-2"},
+					CreationTimestamp: v1.NewTime(time.Now().Add(-time.Hour)),
+				},
+			}
+			newer := &v1alpha1.Build{
+				ObjectMeta: v1.ObjectMeta{
+					Name:              "test-image-1-build-1",
+					Namespace:         defaultNamespace,
+					Labels:            map[string]string{v1alpha1.ImageLabel: "test-image-1"},
+					CreationTimestamp: v1.NewTime(time.Now()),
+				},
+			}
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{image1, image2, older, newer},
+				Args:    []string{"--sort-by", "last-build-time"},
+				ExpectedOutput: `NAME            READY    LATEST IMAGE
+test-image-2    False    test-registry.io/test-image-2@sha256:abcdef123
+test-image-1    True     test-registry.io/test-image-1@sha256:abcdef123
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("returns a wide table of image details", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{image1, image2},
+				Args:    []string{"-o", "wide"},
+				ExpectedOutput: `NAME            READY    LATEST IMAGE                                      BUILDER                     SOURCE                               LAST BUILD    LAST BUILD REASON    AGE
+test-image-1    True     test-registry.io/test-image-1@sha256:abcdef123    ClusterBuilder/builder-a    https://github.com/test/repo@main    2             CONFIG               <unknown>
+test-image-2    False    test-registry.io/test-image-2@sha256:abcdef123    ClusterBuilder/builder-b    https://blob.io/source.zip           2             CONFIG               <unknown>
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("errors on an unsupported output format", func() {
+			testhelpers.CommandTest{
+				Objects:        []runtime.Object{image1},
+				Args:           []string{"-o", "xml"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: unsupported output format: \"xml\", supported formats are wide, json, yaml\n",
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("prints the images as yaml", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{image1},
+				Args:    []string{"-o", "yaml"},
+				ExpectedOutput: `apiVersion: kpack.io/v1alpha1
+items:
+- apiVersion: kpack.io/v1alpha1
+  kind: Image
+  metadata:
+    creationTimestamp: null
+    name: test-image-1
+    namespace: some-default-namespace
+  spec:
+    builder:
+      kind: ClusterBuilder
+      name: builder-a
+    source:
+      git:
+        revision: main
+        url: https://github.com/test/repo
+    tag: ""
+  status:
+    buildCounter: 2
+    conditions:
+    - lastTransitionTime: null
+      status: "True"
+      type: Ready
+    latestBuildReason: CONFIG
+    latestImage: test-registry.io/test-image-1@sha256:abcdef123
+kind: ImageList
+metadata: {}
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("prints an empty image list as json when no images match", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{image1},
+				Args:    []string{"--filter", "source=registry", "-o", "json"},
+				ExpectedOutput: `{
+    "kind": "ImageList",
+    "apiVersion": "kpack.io/v1alpha1",
+    "metadata": {},
+    "items": []
+}
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+	})
+
+	when("watch is provided", func() {
+		makeImage := func(name string, status corev1.ConditionStatus) *v1alpha1.Image {
+			return &v1alpha1.Image{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      name,
+					Namespace: defaultNamespace,
+				},
+				Status: v1alpha1.ImageStatus{
+					Status: corev1alpha1.Status{
+						Conditions: []corev1alpha1.Condition{
+							{
+								Type:   corev1alpha1.ConditionReady,
+								Status: status,
+							},
+						},
+					},
+				},
+			}
+		}
+
+		it.Before(func() {
+			imageWatcher = watch.NewFakeWithChanSize(3, false)
+			imageWatcher.Modify(makeImage("test-image-1", corev1.ConditionTrue))
+			imageWatcher.Add(makeImage("test-image-2", corev1.ConditionUnknown))
+			imageWatcher.Delete(makeImage("test-image-1", corev1.ConditionTrue))
+			imageWatcher.Stop()
+		})
+
+		it("prints the table for every change until the watch closes", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{makeImage("test-image-1", corev1.ConditionUnknown)},
+				Args:    []string{"--watch"},
+				ExpectedOutput: `NAME            READY      LATEST IMAGE
+test-image-1    Unknown    
+
+NAME            READY    LATEST IMAGE
+test-image-1    True     
+
+NAME            READY      LATEST IMAGE
+test-image-1    True       
+test-image-2    Unknown    
+
+NAME            READY      LATEST IMAGE
+test-image-2    Unknown    
+
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("prints a json event for every change", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{makeImage("test-image-1", corev1.ConditionUnknown)},
+				Args:    []string{"--watch", "--filter", "ready=True", "-o", "json"},
+				ExpectedOutput: `{"type":"MODIFIED","object":{"kind":"Image","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"test-image-1","namespace":"some-default-namespace","creationTimestamp":null},"spec":{"tag":"","builder":{},"source":{}},"status":{"conditions":[{"type":"Ready","status":"True","lastTransitionTime":null}]}}}
+{"type":"DELETED","object":{"kind":"Image","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"test-image-1","namespace":"some-default-namespace","creationTimestamp":null},"spec":{"tag":"","builder":{},"source":{}},"status":{"conditions":[{"type":"Ready","status":"True","lastTransitionTime":null}]}}}
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		when("sorting by last build time", func() {
+			makeBuild := func(image, name string, created time.Time) *v1alpha1.Build {
+				return &v1alpha1.Build{
+					ObjectMeta: v1.ObjectMeta{
+						Name:              name,
+						Namespace:         defaultNamespace,
+						Labels:            map[string]string{v1alpha1.ImageLabel: image},
+						CreationTimestamp: v1.NewTime(created),
+					},
+				}
+			}
+
+			now := time.Now()
+
+			it.Before(func() {
+				rebuilt := makeImage("test-image-2", corev1.ConditionTrue)
+				rebuilt.Status.LatestBuildRef = "test-image-2-build-2"
+
+				imageWatcher = watch.NewFakeWithChanSize(1, false)
+				imageWatcher.Modify(rebuilt)
+				imageWatcher.Stop()
+			})
+
+			it("lists the builds once and fetches new latest builds from the watch events", func() {
+				build1 := makeBuild("test-image-1", "test-image-1-build-1", now.Add(-time.Hour))
+				build2 := makeBuild("test-image-2", "test-image-2-build-1", now.Add(-2*time.Hour))
+				clientSet := fake.NewSimpleClientset(
+					makeImage("test-image-1", corev1.ConditionTrue),
+					makeImage("test-image-2", corev1.ConditionTrue),
+					build1,
+					build2,
+					makeBuild("test-image-2", "test-image-2-build-2", now),
+				)
+
+				// the new build is created after the builds are listed
+				clientSet.PrependReactor("list", "builds", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, &v1alpha1.BuildList{Items: []v1alpha1.Build{*build1, *build2}}, nil
+				})
+
+				cmd := cmdFunc(clientSet)
+				out := &bytes.Buffer{}
+				cmd.SetOut(out)
+				cmd.SetArgs([]string{"--watch", "--sort-by", "last-build-time"})
+				require.NoError(t, cmd.Execute())
+
+				require.Equal(t, `NAME            READY    LATEST IMAGE
+test-image-2    True     
+test-image-1    True     
+
+NAME            READY    LATEST IMAGE
+test-image-1    True     
+test-image-2    True     
+
+`, out.String())
+
+				var buildLists, buildGets []string
+				for _, action := range clientSet.Actions() {
+					if action.GetResource().Resource != "builds" {
+						continue
+					}
+					switch action.GetVerb() {
+					case "list":
+						buildLists = append(buildLists, action.GetNamespace())
+					case "get":
+						buildGets = append(buildGets, action.(k8stesting.GetAction).GetName())
+					}
+				}
+				require.Equal(t, []string{defaultNamespace}, buildLists)
+				require.Equal(t, []string{"test-image-2-build-2"}, buildGets)
+			})
+		})
+
+		it("errors when yaml output is requested", func() {
+			testhelpers.CommandTest{
+				Objects:        []runtime.Object{makeImage("test-image-1", corev1.ConditionUnknown)},
+				Args:           []string{"--watch", "-o", "yaml"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: watch supports wide and json output formats\n",
+			}.TestKpack(t, cmdFunc)
+		})
+	})
 }
@@ -4,11 +4,14 @@
 package image
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/pivotal/build-service-cli/pkg/commands"
@@ -17,11 +20,12 @@ import (
 	"github.com/pivotal/build-service-cli/pkg/registry"
 )
 
-func NewPatchCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider, newImageWaiter func(k8s.ClientSet) ImageWaiter) *cobra.Command {
+func NewPatchCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider, newImageWaiter func(k8s.ClientSet) ImageWaiter, newGitValidator func(k8s.ClientSet) image.GitValidator, confirmationProvider ConfirmationProvider) *cobra.Command {
 	var (
 		namespace string
 		subPath   string
 		factory   image.Factory
+		bulkOpts  bulkOptions
 	)
 
 	cmd := &cobra.Command{
@@ -37,6 +41,15 @@ The flags for this command determine how the build will retrieve source code:
   "--git" and "--git-revision" to use Git based source
   "--blob" to use source code hosted in a blob store
   "--local-path" to use source code from the local machine
+  "--source-image" to use a source image already published to a registry
+
+Git sources are validated before the image is saved: the repository must be reachable over http(s),
+the revision must resolve to a commit, and a warning is shown when no git secret targets the repository host.
+Source images must exist and be readable from this machine with the provided registry TLS options.
+Use "--skip-source-validation" to disable these checks.
+
+The "--pin-revision" flag resolves a git branch or tag to the commit it currently points to.
+The image is built from that commit until the revision is updated, for example with "kp image bump".
 
 Local source code will be pushed to the same registry as the existing image tag.
 Therefore, you must have credentials to access the registry on your machine.
@@ -50,13 +63,19 @@ For each environment variable, supply the "--delete-env" flag followed by the va
 For example, "--delete-env key1 --delete-env key2 ...".
 
 The --cache-size flag can only be used to increase the size of the existing cache.
+
+Multiple images may be patched at once by using the "--selector" and/or "--all-namespaces" flags
+instead of an image name. The selected images are listed for confirmation unless "--force" is provided.
+The "--local-path" and "--wait" flags cannot be used when patching multiple images.
 `,
 		Example: `kp image patch my-image --git-revision my-other-branch
 kp image patch my-image --blob https://my-blob-host.com/my-blob
+kp image patch my-image --source-image my-registry.com/my-app-source@sha256:<digest>
 kp image patch my-image --local-path /path/to/local/source/code
 kp image patch my-image --local-path /path/to/local/source/code --builder my-builder
-kp image patch my-image --env foo=bar --env color=red --delete-env apple --delete-env potato`,
-		Args:         commands.ExactArgsWithUsage(1),
+kp image patch my-image --env foo=bar --env color=red --delete-env apple --delete-env potato
+kp image patch -l team=my-team --cluster-builder my-other-cluster-builder`,
+		Args:         imageNameOrSelectorArgs(&bulkOpts),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cs, err := clientSetProvider.GetClientSet(namespace)
@@ -69,19 +88,30 @@ kp image patch my-image --env foo=bar --env color=red --delete-env apple --delet
 				return err
 			}
 
-			img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(args[0], metav1.GetOptions{})
-			if err != nil {
-				return err
+			if cmd.Flag("sub-path").Changed {
+				factory.SubPath = &subPath
 			}
 
-			factory.SourceUploader = rup.SourceUploader(ch.CanChangeState())
-			factory.Printer = ch
+			// every bulk worker gets its own factory, the factory flags are shared
+			newFactory := func(printer image.Printer) *image.Factory {
+				f := factory
+				f.SourceUploader = rup.SourceUploader(ch.CanChangeState())
+				f.Fetcher = rup.Fetcher()
+				f.Printer = printer
+				f.GitValidator = newGitValidator(cs)
+				return &f
+			}
 
-			if cmd.Flag("sub-path").Changed {
-				factory.SubPath = &subPath
+			if bulkOpts.isBulk() {
+				return bulkPatch(cs, newFactory, ch, confirmationProvider, bulkOpts)
 			}
 
-			patched, img, err := patch(img, &factory, ch, cs)
+			img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			patched, img, err := patch(img, newFactory(ch), ch, cs)
 			if err != nil {
 				return err
 			}
@@ -101,6 +131,7 @@ kp image patch my-image --env foo=bar --env color=red --delete-env apple --delet
 	cmd.Flags().StringVar(&factory.GitRevision, "git-revision", "", "git revision (default \"master\")")
 	cmd.Flags().StringVar(&factory.Blob, "blob", "", "source code blob url")
 	cmd.Flags().StringVar(&factory.LocalPath, "local-path", "", "path to local source code")
+	cmd.Flags().StringVar(&factory.SourceImage, "source-image", "", "published source code image")
 	cmd.Flags().StringVar(&subPath, "sub-path", "", "build code at the sub path located within the source code directory")
 	cmd.Flags().StringVar(&factory.Builder, "builder", "", "builder name")
 	cmd.Flags().StringVar(&factory.ClusterBuilder, "cluster-builder", "", "cluster builder name")
@@ -110,6 +141,9 @@ kp image patch my-image --env foo=bar --env color=red --delete-env apple --delet
 	cmd.Flags().BoolP("wait", "w", false, "wait for image patch to be reconciled and tail resulting build logs")
 	commands.SetImgUploadDryRunOutputFlags(cmd)
 	commands.SetTLSFlags(cmd, &factory.TLSConfig)
+	cmd.Flags().BoolVar(&factory.SkipSourceValidation, "skip-source-validation", false, "skip validation of the git repository, revision, git secrets and source image")
+	cmd.Flags().BoolVar(&factory.PinRevision, "pin-revision", false, "resolve the git revision to a commit sha")
+	setBulkFlags(cmd, &bulkOpts)
 	return cmd
 }
 
@@ -118,6 +152,19 @@ func patch(img *v1alpha1.Image, factory *image.Factory, ch *commands.CommandHelp
 		return false, nil, err
 	}
 
+	hasPatch, patchedImage, err := patchImage(img, factory, ch, cs)
+	if err != nil {
+		return hasPatch, nil, err
+	}
+
+	if err = ch.PrintObj(patchedImage); err != nil {
+		return hasPatch, nil, err
+	}
+
+	return hasPatch, patchedImage, ch.PrintChangeResult(hasPatch, fmt.Sprintf("Image %q patched", img.Name))
+}
+
+func patchImage(img *v1alpha1.Image, factory *image.Factory, ch *commands.CommandHelper, cs k8s.ClientSet) (bool, *v1alpha1.Image, error) {
 	patchedImage, patch, err := factory.MakePatch(img)
 	if err != nil {
 		return false, nil, err
@@ -125,15 +172,77 @@ func patch(img *v1alpha1.Image, factory *image.Factory, ch *commands.CommandHelp
 
 	hasPatch := len(patch) > 0
 	if hasPatch && !ch.IsDryRun() {
-		patchedImage, err = cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Patch(img.Name, types.MergePatchType, patch)
+		patchedImage, err = cs.KpackClient.KpackV1alpha1().Images(img.Namespace).Patch(img.Name, types.MergePatchType, patch)
 		if err != nil {
 			return hasPatch, nil, err
 		}
 	}
 
-	if err = ch.PrintObj(patchedImage); err != nil {
-		return hasPatch, nil, err
+	return hasPatch, patchedImage, nil
+}
+
+func bulkPatch(cs k8s.ClientSet, newFactory func(image.Printer) *image.Factory, ch *commands.CommandHelper, confirmationProvider ConfirmationProvider, opts bulkOptions) error {
+	factory := newFactory(ch)
+	if factory.LocalPath != "" {
+		return errors.New("local-path cannot be used when patching multiple images")
 	}
 
-	return hasPatch, patchedImage, ch.PrintChangeResult(hasPatch, fmt.Sprintf("Image %q patched", img.Name))
+	if ch.ShouldWait() {
+		return errors.New("wait cannot be used when patching multiple images")
+	}
+
+	images, err := listSelectedImages(cs, opts)
+	if err != nil {
+		return err
+	}
+
+	if !ch.IsDryRun() {
+		confirmed, err := confirmBulk(ch.Writer(), confirmationProvider, opts, images, "patch")
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			return ch.Printlnf("Skipping image patch")
+		}
+	}
+
+	// output is collected per image and written once all images are patched
+	// so that the output of concurrent patches does not interleave
+	outputs := make([]bytes.Buffer, len(images))
+	patchedImages := make([]runtime.Object, len(images))
+	results := runBulk(images, opts.concurrency, func(i int, img v1alpha1.Image) (string, error) {
+		workerCh := ch.WithWriter(&outputs[i])
+		hasPatch, patchedImage, err := patchImage(&img, newFactory(workerCh), workerCh, cs)
+		if err != nil {
+			return "", err
+		}
+
+		patchedImages[i] = patchedImage
+
+		if !hasPatch {
+			return "no change", nil
+		} else if ch.IsDryRun() {
+			return "patched (dry run)", nil
+		}
+		return "patched", nil
+	})
+
+	for i := range outputs {
+		if _, err := outputs[i].WriteTo(ch.Writer()); err != nil {
+			return err
+		}
+	}
+
+	for _, obj := range patchedImages {
+		if obj == nil {
+			continue
+		}
+
+		if err := ch.PrintObj(obj); err != nil {
+			return err
+		}
+	}
+
+	return displayBulkResults(ch.Writer(), results, "patch")
 }
@@ -4,10 +4,12 @@
 package image_test
 
 import (
+	"io/ioutil"
 	"testing"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/pkg/errors"
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -15,7 +17,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	commandsfakes "github.com/pivotal/build-service-cli/pkg/commands/fakes"
 	imgcmds "github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/image"
 	"github.com/pivotal/build-service-cli/pkg/image/fakes"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 	registryfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
@@ -36,11 +40,25 @@ func testImagePatchCommand(t *testing.T, when spec.G, it spec.S) {
 
 	fakeImageWaiter := &fakes.FakeImageWaiter{}
 
+	var fakeGitValidator *fakes.FakeGitValidator
+
+	it.Before(func() {
+		fakeGitValidator = &fakes.FakeGitValidator{}
+	})
+
+	var fakeConfirmationProvider *commandsfakes.FakeConfirmationProvider
+
+	it.Before(func() {
+		fakeConfirmationProvider = commandsfakes.NewFakeConfirmationProvider(true, nil)
+	})
+
 	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
 		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
 		return imgcmds.NewPatchCommand(clientSetProvider, registryUtilProvider, func(set k8s.ClientSet) imgcmds.ImageWaiter {
 			return fakeImageWaiter
-		})
+		}, func(set k8s.ClientSet) image.GitValidator {
+			return fakeGitValidator
+		}, fakeConfirmationProvider)
 	}
 
 	existingImage := &v1alpha1.Image{
@@ -170,6 +188,110 @@ Image "some-image" patched
 				},
 			}.TestKpack(t, cmdFunc)
 			assert.Len(t, fakeImageWaiter.Calls, 0)
+			assert.Equal(t, []fakes.GitValidateCall{
+				{Namespace: defaultNamespace, URL: "some-git-url", Revision: "some-new-revision"},
+			}, fakeGitValidator.Calls)
+		})
+
+		it("prints git source validation warnings", func() {
+			fakeGitValidator.Warnings = []string{"some-warning"}
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					existingImage,
+				},
+				Args: []string{
+					"some-image",
+					"--git-revision", "some-new-revision",
+				},
+				ExpectedOutput: `Patching Image...
+Warning: some-warning
+Image "some-image" patched
+`,
+				ExpectPatches: []string{
+					`{"spec":{"source":{"git":{"revision":"some-new-revision"}}}}`,
+				},
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("does not patch when the git source is invalid", func() {
+			fakeGitValidator.Err = errors.New("invalid git source: git revision \"some-new-revision\" not found")
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					existingImage,
+				},
+				Args: []string{
+					"some-image",
+					"--git-revision", "some-new-revision",
+				},
+				ExpectErr: true,
+				ExpectedOutput: `Patching Image...
+Error: invalid git source: git revision "some-new-revision" not found
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("pins the existing git revision when pin-revision is provided", func() {
+			fakeGitValidator.Revisions = map[string]string{"some-revision": "1111111111111111111111111111111111111111"}
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					existingImage,
+				},
+				Args: []string{
+					"some-image",
+					"--pin-revision",
+				},
+				ExpectedOutput: `Patching Image...
+Pinned git revision "some-revision" to 1111111111111111111111111111111111111111
+Image "some-image" patched
+`,
+				ExpectPatches: []string{
+					`{"metadata":{"annotations":{"kp.kpack.io/pinnedRevision":"some-revision"}},"spec":{"source":{"git":{"revision":"1111111111111111111111111111111111111111"}}}}`,
+				},
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("removes the pinned revision when the git revision is patched without pinning", func() {
+			pinnedImage := existingImage.DeepCopy()
+			pinnedImage.Annotations = map[string]string{"kp.kpack.io/pinnedRevision": "some-revision"}
+			pinnedImage.Spec.Source.Git.Revision = "1111111111111111111111111111111111111111"
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					pinnedImage,
+				},
+				Args: []string{
+					"some-image",
+					"--git-revision", "some-new-revision",
+				},
+				ExpectedOutput: `Patching Image...
+Image "some-image" patched
+`,
+				ExpectPatches: []string{
+					`{"metadata":{"annotations":null},"spec":{"source":{"git":{"revision":"some-new-revision"}}}}`,
+				},
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("does not validate the git source when it is not patched", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					existingImage,
+				},
+				Args: []string{
+					"some-image",
+					"--sub-path", "some-other-path",
+				},
+				ExpectedOutput: `Patching Image...
+Image "some-image" patched
+`,
+				ExpectPatches: []string{
+					`{"spec":{"source":{"subPath":"some-other-path"}}}`,
+				},
+			}.TestKpack(t, cmdFunc)
+			assert.Len(t, fakeGitValidator.Calls, 0)
 		})
 
 		it("git revision defaults to master if not provided with git", func() {
@@ -653,4 +775,143 @@ status: {}
 			})
 		})
 	})
+
+	when("a selector is provided", func() {
+		otherImage := existingImage.DeepCopy()
+		otherImage.Name = "some-other-image"
+		otherImage.Labels = map[string]string{"team": "some-team"}
+
+		labeledImage := existingImage.DeepCopy()
+		labeledImage.Labels = map[string]string{"team": "some-team"}
+
+		unlabeledImage := existingImage.DeepCopy()
+		unlabeledImage.Name = "some-unlabeled-image"
+
+		it("confirms and patches every selected image", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					labeledImage,
+					otherImage,
+					unlabeledImage,
+				},
+				Args: []string{
+					"-l", "team=some-team",
+					"--cluster-builder", "some-other-ccb",
+				},
+				ExpectedOutput: `The following images were selected for patch:
+NAMESPACE                 NAME
+some-default-namespace    some-image
+some-default-namespace    some-other-image
+
+NAMESPACE                 NAME                RESULT
+some-default-namespace    some-image          patched
+some-default-namespace    some-other-image    patched
+
+`,
+				ExpectPatches: []string{
+					`{"spec":{"builder":{"name":"some-other-ccb"}}}`,
+				},
+			}.TestKpack(t, cmdFunc)
+			assert.NoError(t, fakeConfirmationProvider.WasRequestedWithMsg("Please confirm patch of 2 image(s) by typing 'y': "))
+			assert.Len(t, fakeImageWaiter.Calls, 0)
+		})
+
+		it("writes the output of every image in order before the results", func() {
+			concurrentCmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+				return imgcmds.NewPatchCommand(clientSetProvider, registryUtilProvider, func(set k8s.ClientSet) imgcmds.ImageWaiter {
+					return fakeImageWaiter
+				}, func(set k8s.ClientSet) image.GitValidator {
+					return &fakes.FakeGitValidator{Warnings: []string{"some-warning"}}
+				}, fakeConfirmationProvider)
+			}
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					labeledImage,
+					otherImage,
+				},
+				Args: []string{
+					"-l", "team=some-team",
+					"--git-revision", "some-new-revision",
+					"--concurrency", "2",
+					"--force",
+				},
+				ExpectedOutput: `Warning: some-warning
+Warning: some-warning
+NAMESPACE                 NAME                RESULT
+some-default-namespace    some-image          patched
+some-default-namespace    some-other-image    patched
+
+`,
+				ExpectPatches: []string{
+					`{"spec":{"source":{"git":{"revision":"some-new-revision"}}}}`,
+				},
+			}.TestKpack(t, concurrentCmdFunc)
+		})
+
+		it("does not patch when confirmation is not given", func() {
+			fakeConfirmationProvider = commandsfakes.NewFakeConfirmationProvider(false, nil)
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					labeledImage,
+					otherImage,
+				},
+				Args: []string{
+					"-l", "team=some-team",
+					"--cluster-builder", "some-other-ccb",
+				},
+				ExpectedOutput: `The following images were selected for patch:
+NAMESPACE                 NAME
+some-default-namespace    some-image
+some-default-namespace    some-other-image
+
+Skipping image patch
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("does not request confirmation for a dry run", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					labeledImage,
+					otherImage,
+				},
+				Args: []string{
+					"-l", "team=some-team",
+					"--cluster-builder", "some-other-ccb",
+					"--dry-run",
+				},
+				ExpectedOutput: `NAMESPACE                 NAME                RESULT
+some-default-namespace    some-image          patched (dry run)
+some-default-namespace    some-other-image    patched (dry run)
+
+`,
+			}.TestKpack(t, cmdFunc)
+			assert.False(t, fakeConfirmationProvider.WasRequested())
+		})
+
+		it("errors when an image name is also provided", func() {
+			cmd := cmdFunc(fake.NewSimpleClientset())
+			cmd.SetArgs([]string{"some-image", "-l", "team=some-team"})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			assert.Error(t, cmd.Execute())
+		})
+
+		it("errors when local path is provided", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					labeledImage,
+				},
+				Args: []string{
+					"-l", "team=some-team",
+					"--local-path", "some-local-path",
+				},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: local-path cannot be used when patching multiple images\n",
+			}.TestKpack(t, cmdFunc)
+		})
+	})
 }
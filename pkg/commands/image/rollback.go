@@ -0,0 +1,241 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+type Differ interface {
+	Diff(dOld, dNew interface{}) (string, error)
+}
+
+type rollbackSpec struct {
+	Builder corev1.ObjectReference `json:"builder"`
+	Source  v1alpha1.SourceConfig  `json:"source"`
+}
+
+func NewRollbackCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider, differ Differ) *cobra.Command {
+	var (
+		namespace     string
+		toBuild       int
+		toLastSuccess bool
+		retag         bool
+		tlsCfg        registry.TLSConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rollback <name>",
+		Short: "Roll back an image to a previous build",
+		Long: `Roll back an image to the inputs of a previous successful build in the provided namespace.
+
+The image source is pinned to the source resolved by the selected build, including the exact git revision.
+The image builder is set to the builder or cluster builder that currently provides the builder image used by
+the selected build. If no such builder exists, the builder is left unchanged and a warning is printed.
+
+The "--to-last-success" flag selects the most recent successful build that produced an image other than the
+current latest image.
+
+The "--retag" flag tags the image produced by the selected build as the image tag, so the previous image is
+available immediately without waiting for the rolled back build to complete.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example: `kp image rollback my-image --to-build 7
+kp image rollback my-image --to-last-success
+kp image rollback my-image --to-last-success --retag
+kp image rollback my-image --to-build 7 --dry-run --output yaml`,
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("to-build") == toLastSuccess {
+				return errors.New("exactly one of --to-build or --to-last-success must be provided")
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			bld, err := findRollbackBuild(cs, img, toBuild, toLastSuccess)
+			if err != nil {
+				return err
+			}
+
+			if err := ch.PrintStatus("Rolling back Image %q to build %s...", img.Name, bld.Labels[v1alpha1.BuildNumberLabel]); err != nil {
+				return err
+			}
+
+			updatedImg, err := makeRollbackImage(cs, ch, img, bld)
+			if err != nil {
+				return err
+			}
+
+			diff, err := differ.Diff(
+				rollbackSpec{Builder: img.Spec.Builder, Source: img.Spec.Source},
+				rollbackSpec{Builder: updatedImg.Spec.Builder, Source: updatedImg.Spec.Source},
+			)
+			if err != nil {
+				return err
+			}
+
+			hasChange := diff != ""
+			if hasChange {
+				if err := ch.Printlnf("%s", diff); err != nil {
+					return err
+				}
+			}
+
+			if hasChange && !ch.IsDryRun() {
+				updatedImg, err = cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Update(updatedImg)
+				if err != nil {
+					return err
+				}
+			}
+
+			if retag {
+				if err := ch.Printlnf("Tagging build image..."); err != nil {
+					return err
+				}
+
+				err = rup.Tagger(ch.CanChangeState()).Tag(bld.Status.LatestImage, img.Spec.Tag, ch.Writer(), tlsCfg)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := ch.PrintObj(updatedImg); err != nil {
+				return err
+			}
+
+			return ch.PrintChangeResult(hasChange, "Image %q rolled back", img.Name)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().IntVar(&toBuild, "to-build", 0, "build number to roll back to")
+	cmd.Flags().BoolVar(&toLastSuccess, "to-last-success", false, "roll back to the most recent successful build that produced a different image")
+	cmd.Flags().BoolVar(&retag, "retag", false, "tag the image produced by the selected build as the image tag")
+	commands.SetDryRunOutputFlags(cmd)
+	commands.SetTLSFlags(cmd, &tlsCfg)
+	return cmd
+}
+
+func findRollbackBuild(cs k8s.ClientSet, img *v1alpha1.Image, toBuild int, toLastSuccess bool) (*v1alpha1.Build, error) {
+	buildList, err := cs.KpackClient.KpackV1alpha1().Builds(img.Namespace).List(metav1.ListOptions{
+		LabelSelector: v1alpha1.ImageLabel + "=" + img.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buildList.Items) == 0 {
+		return nil, errors.New("no builds found")
+	}
+
+	sort.Slice(buildList.Items, build.Sort(buildList.Items))
+
+	if !toLastSuccess {
+		buildNumber := strconv.Itoa(toBuild)
+		for i := range buildList.Items {
+			bld := &buildList.Items[i]
+			if bld.Labels[v1alpha1.BuildNumberLabel] != buildNumber {
+				continue
+			}
+
+			if !bld.Status.GetCondition(corev1alpha1.ConditionSucceeded).IsTrue() {
+				return nil, errors.Errorf("build %d did not succeed", toBuild)
+			}
+			return bld, nil
+		}
+		return nil, errors.Errorf("build %d not found", toBuild)
+	}
+
+	for i := len(buildList.Items) - 1; i >= 0; i-- {
+		bld := &buildList.Items[i]
+		if bld.Status.GetCondition(corev1alpha1.ConditionSucceeded).IsTrue() && bld.Status.LatestImage != img.Status.LatestImage {
+			return bld, nil
+		}
+	}
+	return nil, errors.New("no previous successful build found")
+}
+
+func makeRollbackImage(cs k8s.ClientSet, ch *commands.CommandHelper, img *v1alpha1.Image, bld *v1alpha1.Build) (*v1alpha1.Image, error) {
+	updatedImg := img.DeepCopy()
+	updatedImg.Spec.Source = *bld.Spec.Source.DeepCopy()
+
+	builder, err := findBuilderForImage(cs, img, bld.Spec.Builder.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	if builder == nil {
+		err := ch.Printlnf("Warning: no builder currently provides %q, keeping %s %q", bld.Spec.Builder.Image, img.Spec.Builder.Kind, img.Spec.Builder.Name)
+		return updatedImg, err
+	}
+
+	updatedImg.Spec.Builder = *builder
+	return updatedImg, nil
+}
+
+// findBuilderForImage returns a reference to a builder or cluster builder
+// whose latest image is builderImage, preferring the image's current builder.
+func findBuilderForImage(cs k8s.ClientSet, img *v1alpha1.Image, builderImage string) (*corev1.ObjectReference, error) {
+	var candidates []corev1.ObjectReference
+
+	builders, err := cs.KpackClient.KpackV1alpha1().Builders(img.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range builders.Items {
+		if b.Status.LatestImage == builderImage {
+			candidates = append(candidates, corev1.ObjectReference{Kind: v1alpha1.BuilderKind, Name: b.Name})
+		}
+	}
+
+	clusterBuilders, err := cs.KpackClient.KpackV1alpha1().ClusterBuilders().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cb := range clusterBuilders.Items {
+		if cb.Status.LatestImage == builderImage {
+			candidates = append(candidates, corev1.ObjectReference{Kind: v1alpha1.ClusterBuilderKind, Name: cb.Name})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	for _, c := range candidates {
+		if c.Kind == img.Spec.Builder.Kind && c.Name == img.Spec.Builder.Name {
+			return img.Spec.Builder.DeepCopy(), nil
+		}
+	}
+	return &candidates[0], nil
+}
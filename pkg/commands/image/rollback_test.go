@@ -0,0 +1,238 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+
+	commandsfakes "github.com/pivotal/build-service-cli/pkg/commands/fakes"
+	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	registryfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestImageRollbackCommand(t *testing.T) {
+	spec.Run(t, "TestImageRollbackCommand", testImageRollbackCommand)
+}
+
+func testImageRollbackCommand(t *testing.T, when spec.G, it spec.S) {
+	const defaultNamespace = "some-default-namespace"
+
+	var (
+		fakeDiffer *commandsfakes.FakeDiffer
+		fakeTagger *registryfakes.Tagger
+	)
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return image.NewRollbackCommand(clientSetProvider, registryfakes.UtilProvider{FakeTagger: fakeTagger}, fakeDiffer)
+	}
+
+	makeBuild := func(number, revision, builderImage, latestImage string, succeeded corev1.ConditionStatus) *v1alpha1.Build {
+		return &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-image-build-" + number,
+				Namespace: defaultNamespace,
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       "some-image",
+					v1alpha1.BuildNumberLabel: number,
+				},
+			},
+			Spec: v1alpha1.BuildSpec{
+				Builder: v1alpha1.BuildBuilderSpec{Image: builderImage},
+				Source: v1alpha1.SourceConfig{
+					Git: &v1alpha1.Git{URL: "https://github.com/some/repo", Revision: revision},
+				},
+			},
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{
+						{Type: corev1alpha1.ConditionSucceeded, Status: succeeded},
+					},
+				},
+				LatestImage: latestImage,
+			},
+		}
+	}
+
+	img := &v1alpha1.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "some-image",
+			Namespace: defaultNamespace,
+		},
+		Spec: v1alpha1.ImageSpec{
+			Tag: "registry.io/some-image",
+			Builder: corev1.ObjectReference{
+				Kind: v1alpha1.ClusterBuilderKind,
+				Name: "new-builder",
+			},
+			Source: v1alpha1.SourceConfig{
+				Git: &v1alpha1.Git{URL: "https://github.com/some/repo", Revision: "main"},
+			},
+		},
+		Status: v1alpha1.ImageStatus{
+			LatestImage: "registry.io/some-image@sha256:333",
+		},
+	}
+
+	build1 := makeBuild("1", "sha-one", "registry.io/builder@sha256:old", "registry.io/some-image@sha256:111", corev1.ConditionTrue)
+	build2 := makeBuild("2", "sha-two", "registry.io/builder@sha256:old", "registry.io/some-image@sha256:222", corev1.ConditionFalse)
+	build3 := makeBuild("3", "sha-three", "registry.io/builder@sha256:new", "registry.io/some-image@sha256:333", corev1.ConditionTrue)
+
+	oldClusterBuilder := &v1alpha1.ClusterBuilder{
+		ObjectMeta: metav1.ObjectMeta{Name: "old-builder"},
+		Status:     v1alpha1.BuilderStatus{LatestImage: "registry.io/builder@sha256:old"},
+	}
+	newClusterBuilder := &v1alpha1.ClusterBuilder{
+		ObjectMeta: metav1.ObjectMeta{Name: "new-builder"},
+		Status:     v1alpha1.BuilderStatus{LatestImage: "registry.io/builder@sha256:new"},
+	}
+
+	rolledBackImg := img.DeepCopy()
+	rolledBackImg.Spec.Builder.Name = "old-builder"
+	rolledBackImg.Spec.Source.Git.Revision = "sha-one"
+
+	it.Before(func() {
+		fakeDiffer = &commandsfakes.FakeDiffer{DiffResult: "some-diff"}
+		fakeTagger = &registryfakes.Tagger{}
+	})
+
+	it("rolls back to the inputs of the provided build", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{img, build1, build2, build3, oldClusterBuilder, newClusterBuilder},
+			Args:    []string{"some-image", "--to-build", "1"},
+			ExpectedOutput: `Rolling back Image "some-image" to build 1...
+some-diff
+Image "some-image" rolled back
+`,
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{Object: rolledBackImg},
+			},
+		}.TestKpack(t, cmdFunc)
+
+		oldArg, newArg := fakeDiffer.Args()
+		require.NotEqual(t, oldArg, newArg)
+		require.Len(t, fakeTagger.Calls(), 0)
+	})
+
+	it("rolls back to the last successful build that produced a different image", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{img, build1, build2, build3, oldClusterBuilder, newClusterBuilder},
+			Args:    []string{"some-image", "--to-last-success"},
+			ExpectedOutput: `Rolling back Image "some-image" to build 1...
+some-diff
+Image "some-image" rolled back
+`,
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{Object: rolledBackImg},
+			},
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("keeps the builder and warns when no builder provides the build's builder image", func() {
+		expectedImg := img.DeepCopy()
+		expectedImg.Spec.Source.Git.Revision = "sha-one"
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{img, build1, build3, newClusterBuilder},
+			Args:    []string{"some-image", "--to-build", "1"},
+			ExpectedOutput: `Rolling back Image "some-image" to build 1...
+Warning: no builder currently provides "registry.io/builder@sha256:old", keeping ClusterBuilder "new-builder"
+some-diff
+Image "some-image" rolled back
+`,
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{Object: expectedImg},
+			},
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("re-tags the image produced by the build", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{img, build1, build3, oldClusterBuilder},
+			Args:    []string{"some-image", "--to-build", "1", "--retag"},
+			ExpectedOutput: `Rolling back Image "some-image" to build 1...
+some-diff
+Tagging build image...
+	Tagging 'registry.io/some-image@sha256:111' as 'registry.io/some-image'
+Image "some-image" rolled back
+`,
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{Object: rolledBackImg},
+			},
+		}.TestKpack(t, cmdFunc)
+
+		require.Equal(t, []registryfakes.TagCall{
+			{SrcRef: "registry.io/some-image@sha256:111", DstTag: "registry.io/some-image"},
+		}, fakeTagger.Calls())
+	})
+
+	it("does not update the image on dry run", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{img, build1, build3, oldClusterBuilder},
+			Args:    []string{"some-image", "--to-build", "1", "--dry-run"},
+			ExpectedOutput: `Rolling back Image "some-image" to build 1... (dry run)
+some-diff
+Image "some-image" rolled back (dry run)
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("reports no change when the image already matches the build", func() {
+		fakeDiffer.DiffResult = ""
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{img, build1, build3, oldClusterBuilder},
+			Args:    []string{"some-image", "--to-build", "1"},
+			ExpectedOutput: `Rolling back Image "some-image" to build 1...
+Image "some-image" rolled back (no change)
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when the build did not succeed", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{img, build1, build2, build3},
+			Args:           []string{"some-image", "--to-build", "2"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: build 2 did not succeed\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when the build does not exist", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{img, build1},
+			Args:           []string{"some-image", "--to-build", "7"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: build 7 not found\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when neither or both build selectors are provided", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{img, build1},
+			Args:           []string{"some-image"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: exactly one of --to-build or --to-last-success must be provided\n",
+		}.TestKpack(t, cmdFunc)
+
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{img, build1},
+			Args:           []string{"some-image", "--to-build", "1", "--to-last-success"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: exactly one of --to-build or --to-last-success must be provided\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
@@ -16,7 +16,7 @@ import (
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 )
 
-func NewSaveCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider, newImageWaiter func(k8s.ClientSet) ImageWaiter) *cobra.Command {
+func NewSaveCommand(clientSetProvider k8s.ClientSetProvider, rup registry.UtilProvider, newImageWaiter func(k8s.ClientSet) ImageWaiter, newGitValidator func(k8s.ClientSet) image.GitValidator) *cobra.Command {
 	var (
 		tag       string
 		namespace string
@@ -40,6 +40,15 @@ The flags for this command determine how the build will retrieve source code:
   "--git" and "--git-revision" to use Git based source
   "--blob" to use source code hosted in a blob store
   "--local-path" to use source code from the local machine
+  "--source-image" to use a source image already published to a registry
+
+Git sources are validated before the image is saved: the repository must be reachable over http(s),
+the revision must resolve to a commit, and a warning is shown when no git secret targets the repository host.
+Source images must exist and be readable from this machine with the provided registry TLS options.
+Use "--skip-source-validation" to disable these checks.
+
+The "--pin-revision" flag resolves a git branch or tag to the commit it currently points to.
+The image is built from that commit until the revision is updated, for example with "kp image bump".
 
 Local source code will be pushed to the same registry provided for the image tag.
 Therefore, you must have credentials to access the registry on your machine.
@@ -49,6 +58,7 @@ For each environment variable, supply the "--env" flag followed by the key value
 For example, "--env key1=value1 --env key2=value2 ...".`,
 		Example: `kp image create my-image --tag my-registry.com/my-repo --git https://my-repo.com/my-app.git --git-revision my-branch
 kp image save my-image --tag my-registry.com/my-repo --blob https://my-blob-host.com/my-blob
+kp image save my-image --tag my-registry.com/my-repo --source-image my-registry.com/my-app-source@sha256:<digest>
 kp image save my-image --tag my-registry.com/my-repo --local-path /path/to/local/source/code
 kp image save my-image --tag my-registry.com/my-repo --local-path /path/to/local/source/code --builder my-builder -n my-namespace
 kp image save my-image --tag my-registry.com/my-repo --blob https://my-blob-host.com/my-blob --env foo=bar --env color=red --env food=apple`,
@@ -69,8 +79,11 @@ kp image save my-image --tag my-registry.com/my-repo --blob https://my-blob-host
 			shouldWait := ch.ShouldWait()
 
 			factory.SourceUploader = rup.SourceUploader(ch.CanChangeState())
+			factory.Fetcher = rup.Fetcher()
 			factory.Printer = ch
 
+			factory.GitValidator = newGitValidator(cs)
+
 			img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(name, metav1.GetOptions{})
 			if k8serrors.IsNotFound(err) {
 				if tag == "" {
@@ -111,6 +124,7 @@ kp image save my-image --tag my-registry.com/my-repo --blob https://my-blob-host
 	cmd.Flags().StringVar(&factory.GitRevision, "git-revision", "", "git revision (default \"master\")")
 	cmd.Flags().StringVar(&factory.Blob, "blob", "", "source code blob url")
 	cmd.Flags().StringVar(&factory.LocalPath, "local-path", "", "path to local source code")
+	cmd.Flags().StringVar(&factory.SourceImage, "source-image", "", "published source code image")
 	cmd.Flags().StringVar(&subPath, "sub-path", "", "build code at the sub path located within the source code directory")
 	cmd.Flags().StringVar(&factory.CacheSize, "cache-size", "", "cache size as a kubernetes quantity (default \"2G\")")
 	cmd.Flags().StringVarP(&factory.Builder, "builder", "b", "", "builder name")
@@ -119,5 +133,7 @@ kp image save my-image --tag my-registry.com/my-repo --blob https://my-blob-host
 	cmd.Flags().BoolP("wait", "w", false, "wait for image create to be reconciled and tail resulting build logs")
 	commands.SetImgUploadDryRunOutputFlags(cmd)
 	commands.SetTLSFlags(cmd, &factory.TLSConfig)
+	cmd.Flags().BoolVar(&factory.SkipSourceValidation, "skip-source-validation", false, "skip validation of the git repository, revision, git secrets and source image")
+	cmd.Flags().BoolVar(&factory.PinRevision, "pin-revision", false, "resolve the git revision to a commit sha")
 	return cmd
 }
@@ -16,7 +16,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	commandsfakes "github.com/pivotal/build-service-cli/pkg/commands/fakes"
 	imgcmds "github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/image"
 	"github.com/pivotal/build-service-cli/pkg/image/fakes"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 	registryfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
@@ -36,12 +38,15 @@ func testImageSaveCommand(t *testing.T, when spec.G, it spec.S) {
 	const defaultNamespace = "some-default-namespace"
 
 	fakeImageWaiter := &fakes.FakeImageWaiter{}
+	fakeGitValidator := &fakes.FakeGitValidator{}
 
 	when("creating", func() {
 		cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
 			clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
 			return imgcmds.NewSaveCommand(clientSetProvider, registryUtilProvider, func(set k8s.ClientSet) imgcmds.ImageWaiter {
 				return fakeImageWaiter
+			}, func(set k8s.ClientSet) image.GitValidator {
+				return fakeGitValidator
 			})
 		}
 
@@ -151,7 +156,7 @@ Image "some-image" created
 						},
 						ExpectErr: true,
 						ExpectedOutput: `Creating Image...
-Error: image source must be one of git, blob, or local-path
+Error: image source must be one of git, blob, local-path, or source-image
 `,
 					}.TestKpack(t, cmdFunc)
 
@@ -232,7 +237,7 @@ Image "some-image" created
 						},
 						ExpectErr: true,
 						ExpectedOutput: `Creating Image...
-Error: image source must be one of git, blob, or local-path
+Error: image source must be one of git, blob, local-path, or source-image
 `,
 					}.TestKpack(t, cmdFunc)
 
@@ -412,7 +417,7 @@ Image "some-image" created
 						},
 						ExpectErr: true,
 						ExpectedOutput: `Creating Image...
-Error: image source must be one of git, blob, or local-path
+Error: image source must be one of git, blob, local-path, or source-image
 `,
 					}.TestKpack(t, cmdFunc)
 					assert.Len(t, fakeImageWaiter.Calls, 0)
@@ -581,7 +586,7 @@ status: {}
 						},
 						ExpectErr: true,
 						ExpectedOutput: `Creating Image...
-Error: image source must be one of git, blob, or local-path
+Error: image source must be one of git, blob, local-path, or source-image
 `,
 					}.TestKpack(t, cmdFunc)
 				})
@@ -671,7 +676,7 @@ status: {}
 						},
 						ExpectErr: true,
 						ExpectedOutput: `Creating Image... (dry run with image upload)
-Error: image source must be one of git, blob, or local-path
+Error: image source must be one of git, blob, local-path, or source-image
 `,
 					}.TestKpack(t, cmdFunc)
 				})
@@ -755,7 +760,9 @@ status: {}
 			clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
 			return imgcmds.NewPatchCommand(clientSetProvider, registryUtilProvider, func(set k8s.ClientSet) imgcmds.ImageWaiter {
 				return fakeImageWaiter
-			})
+			}, func(set k8s.ClientSet) image.GitValidator {
+				return fakeGitValidator
+			}, commandsfakes.NewFakeConfirmationProvider(true, nil))
 		}
 
 		existingImage := &v1alpha1.Image{
@@ -28,6 +28,9 @@ func NewStatusCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		Short: "Display status of an image",
 		Long: `Prints detailed information about the status of a specific image in the provided namespace.
 
+When the image is not ready, the conditions of the image, its builder, cluster stack and cluster store
+are listed, followed by hints for resolving the problems found along that chain.
+
 The namespace defaults to the kubernetes current-context namespace.`,
 		Example:      "kp image status my-image\nkp image status my-other-image -n my-namespace",
 		Args:         commands.ExactArgsWithUsage(1),
@@ -51,7 +54,7 @@ The namespace defaults to the kubernetes current-context namespace.`,
 			}
 
 			sort.Slice(buildList.Items, build.Sort(buildList.Items))
-			return displayImageStatus(cmd, image, buildList.Items)
+			return displayImageStatus(cmd, cs, image, buildList.Items)
 		},
 	}
 
@@ -60,7 +63,7 @@ The namespace defaults to the kubernetes current-context namespace.`,
 	return cmd
 }
 
-func displayImageStatus(cmd *cobra.Command, image *v1alpha1.Image, builds []v1alpha1.Build) error {
+func displayImageStatus(cmd *cobra.Command, cs k8s.ClientSet, image *v1alpha1.Image, builds []v1alpha1.Build) error {
 	statusWriter := commands.NewStatusWriter(cmd.OutOrStdout())
 	imgDetails := getImageDetails(image)
 	failedBuild := getLastFailedBuild(builds)
@@ -94,7 +97,20 @@ func displayImageStatus(cmd *cobra.Command, image *v1alpha1.Image, builds []v1al
 		return err
 	}
 
-	return statusWriter.Write()
+	if err := statusWriter.Write(); err != nil {
+		return err
+	}
+
+	if imgDetails.status != "Not Ready" {
+		return nil
+	}
+
+	diagnosis, err := diagnoseImage(cs, image)
+	if err != nil {
+		return err
+	}
+
+	return diagnosis.write(cmd.OutOrStdout())
 }
 
 func getLastSuccessfulBuild(builds []v1alpha1.Build) *v1alpha1.Build {
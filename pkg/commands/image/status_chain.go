@@ -0,0 +1,305 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+// chainLink is a resource in the chain an image depends on to build.
+type chainLink struct {
+	kind       string
+	name       string
+	found      bool
+	conditions corev1alpha1.Conditions
+}
+
+// imageDiagnosis walks the chain from an image to its builder, cluster stack
+// and cluster store, collecting the conditions of every resource along the
+// way and hints for the problems it finds.
+type imageDiagnosis struct {
+	cs    k8s.ClientSet
+	links []chainLink
+	hints []string
+}
+
+func diagnoseImage(cs k8s.ClientSet, img *v1alpha1.Image) (*imageDiagnosis, error) {
+	d := &imageDiagnosis{cs: cs}
+	d.links = append(d.links, chainLink{kind: "Image", name: img.Name, found: true, conditions: img.Status.Conditions})
+
+	builderSpec, err := d.addBuilder(img)
+	if err != nil {
+		return nil, err
+	}
+
+	if builderSpec != nil {
+		if err := d.addClusterStack(builderSpec); err != nil {
+			return nil, err
+		}
+
+		if err := d.addClusterStore(builderSpec); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := d.checkRegistrySecrets(img); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *imageDiagnosis) addBuilder(img *v1alpha1.Image) (*v1alpha1.BuilderSpec, error) {
+	kind, builderName := img.Spec.Builder.Kind, img.Spec.Builder.Name
+
+	var (
+		spec   v1alpha1.BuilderSpec
+		status v1alpha1.BuilderStatus
+		err    error
+	)
+
+	switch kind {
+	case v1alpha1.BuilderKind:
+		namespace := img.Spec.Builder.Namespace
+		if namespace == "" {
+			namespace = img.Namespace
+		}
+
+		var builder *v1alpha1.Builder
+		builder, err = d.cs.KpackClient.KpackV1alpha1().Builders(namespace).Get(builderName, metav1.GetOptions{})
+		if err == nil {
+			spec, status = builder.Spec.BuilderSpec, builder.Status
+		}
+	case v1alpha1.ClusterBuilderKind:
+		var clusterBuilder *v1alpha1.ClusterBuilder
+		clusterBuilder, err = d.cs.KpackClient.KpackV1alpha1().ClusterBuilders().Get(builderName, metav1.GetOptions{})
+		if err == nil {
+			spec, status = clusterBuilder.Spec.BuilderSpec, clusterBuilder.Status
+		}
+	default:
+		d.hints = append(d.hints, fmt.Sprintf("Image '%s' does not reference a builder, patch it with \"--builder\" or \"--cluster-builder\"", img.Name))
+		return nil, nil
+	}
+
+	if k8serrors.IsNotFound(err) {
+		d.links = append(d.links, chainLink{kind: kind, name: builderName})
+		d.hints = append(d.hints, fmt.Sprintf("%s '%s' not found, create it or patch the image to use an existing builder", kind, builderName))
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	d.addLink(kind, builderName, status.Conditions)
+	return &spec, nil
+}
+
+func (d *imageDiagnosis) addClusterStack(spec *v1alpha1.BuilderSpec) error {
+	stackName := spec.Stack.Name
+	if stackName == "" {
+		return nil
+	}
+
+	stack, err := d.cs.KpackClient.KpackV1alpha1().ClusterStacks().Get(stackName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		d.links = append(d.links, chainLink{kind: v1alpha1.ClusterStackKind, name: stackName})
+		d.hints = append(d.hints, fmt.Sprintf("ClusterStack '%s' not found, create it with \"kp clusterstack create\"", stackName))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	d.addLink(v1alpha1.ClusterStackKind, stackName, stack.Status.Conditions)
+	return nil
+}
+
+func (d *imageDiagnosis) addClusterStore(spec *v1alpha1.BuilderSpec) error {
+	storeName := spec.Store.Name
+	if storeName == "" {
+		return nil
+	}
+
+	store, err := d.cs.KpackClient.KpackV1alpha1().ClusterStores().Get(storeName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		d.links = append(d.links, chainLink{kind: v1alpha1.ClusterStoreKind, name: storeName})
+		d.hints = append(d.hints, fmt.Sprintf("ClusterStore '%s' not found, create it with \"kp clusterstore create\"", storeName))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	d.addLink(v1alpha1.ClusterStoreKind, storeName, store.Status.Conditions)
+
+	for _, entry := range spec.Order {
+		for _, ref := range entry.Group {
+			if !storeHasBuildpack(store, ref.BuildpackInfo) {
+				d.hints = append(d.hints, fmt.Sprintf("ClusterStore '%s' is missing buildpack %s", storeName, buildpackText(ref.BuildpackInfo)))
+			}
+		}
+	}
+	return nil
+}
+
+// checkRegistrySecrets verifies that a secret for the registry of the image
+// tag is attached to the image service account, as kpack needs it to push.
+func (d *imageDiagnosis) checkRegistrySecrets(img *v1alpha1.Image) error {
+	tag, err := name.ParseReference(img.Spec.Tag, name.WeakValidation)
+	if err != nil {
+		return nil
+	}
+	registry := tag.Context().RegistryStr()
+
+	serviceAccount := img.Spec.ServiceAccount
+	if serviceAccount == "" {
+		serviceAccount = defaultServiceAccount
+	}
+
+	sa, err := d.cs.K8sClient.CoreV1().ServiceAccounts(img.Namespace).Get(serviceAccount, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		d.hints = append(d.hints, fmt.Sprintf("ServiceAccount '%s' not found in namespace '%s'", serviceAccount, img.Namespace))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	attached := map[string]bool{}
+	for _, s := range sa.Secrets {
+		attached[s.Name] = true
+	}
+	for _, s := range sa.ImagePullSecrets {
+		attached[s.Name] = true
+	}
+
+	secrets, err := d.cs.K8sClient.CoreV1().Secrets(img.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	var unattached []string
+	for i := range secrets.Items {
+		s := &secrets.Items[i]
+		if !secretTargetsRegistry(s, registry) {
+			continue
+		}
+
+		if attached[s.Name] {
+			return nil
+		}
+		unattached = append(unattached, s.Name)
+	}
+
+	if len(unattached) == 0 {
+		d.hints = append(d.hints, fmt.Sprintf("No secret for registry '%s' is attached to service account '%s', create one with \"kp secret create\"", registry, serviceAccount))
+	}
+
+	for _, s := range unattached {
+		d.hints = append(d.hints, fmt.Sprintf("Secret '%s' for registry '%s' is not attached to service account '%s'", s, registry, serviceAccount))
+	}
+	return nil
+}
+
+func (d *imageDiagnosis) addLink(kind, name string, conditions corev1alpha1.Conditions) {
+	d.links = append(d.links, chainLink{kind: kind, name: name, found: true, conditions: conditions})
+
+	if cond := readyCondition(conditions); cond != nil && cond.IsFalse() {
+		hint := fmt.Sprintf("%s '%s' is not ready", kind, name)
+		if cond.Message != "" {
+			hint = fmt.Sprintf("%s: %s", hint, cond.Message)
+		}
+		d.hints = append(d.hints, hint)
+	}
+}
+
+func (d *imageDiagnosis) write(out io.Writer) error {
+	writer, err := commands.NewTableWriter(out, "Resource", "Condition", "Status", "Reason", "Message")
+	if err != nil {
+		return err
+	}
+
+	for _, link := range d.links {
+		resource := fmt.Sprintf("%s/%s", link.kind, link.name)
+
+		if !link.found {
+			err = writer.AddRow(resource, "", "NotFound", "", "")
+		} else if len(link.conditions) == 0 {
+			err = writer.AddRow(resource, "", "Unknown", "", "")
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, cond := range link.conditions {
+			err := writer.AddRow(resource, string(cond.Type), string(cond.Status), cond.Reason, cond.Message)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := writer.Write(); err != nil {
+		return err
+	}
+
+	if len(d.hints) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(out, "Hints:"); err != nil {
+		return err
+	}
+
+	for _, hint := range d.hints {
+		if _, err := fmt.Fprintf(out, "  - %s\n", hint); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(out, "")
+	return err
+}
+
+func readyCondition(conditions corev1alpha1.Conditions) *corev1alpha1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == corev1alpha1.ConditionReady {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func storeHasBuildpack(store *v1alpha1.ClusterStore, info v1alpha1.BuildpackInfo) bool {
+	for _, bp := range store.Status.Buildpacks {
+		if bp.Id == info.Id && (info.Version == "" || bp.Version == info.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildpackText(info v1alpha1.BuildpackInfo) string {
+	if info.Version == "" {
+		return info.Id
+	}
+	return info.String()
+}
+
+func secretTargetsRegistry(s *corev1.Secret, registry string) bool {
+	for _, host := range secret.RegistryHosts(s) {
+		if host == registry {
+			return true
+		}
+	}
+	return false
+}
@@ -8,12 +8,13 @@ import (
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
-	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8sfakes "k8s.io/client-go/kubernetes/fake"
 
 	"github.com/pivotal/build-service-cli/pkg/commands/image"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
@@ -33,8 +34,8 @@ func testImageStatusCommand(t *testing.T, when spec.G, it spec.S) {
 	testBuilds := testhelpers.MakeTestBuilds(imageName, defaultNamespace)
 	testNamespacedBuilds := testhelpers.MakeTestBuilds(imageName, namespace)
 
-	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
-		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+	cmdFunc := func(k8sClientSet *k8sfakes.Clientset, kpackClientSet *kpackfakes.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeProvider(k8sClientSet, kpackClientSet, defaultNamespace)
 		return image.NewStatusCommand(clientSetProvider)
 	}
 
@@ -71,13 +72,19 @@ Last Failed Build
 Id:              2
 Build Reason:    COMMIT,BUILDPACK
 
+RESOURCE            CONDITION    STATUS    REASON    MESSAGE
+Image/test-image    Ready        False               
+
+Hints:
+  - Image 'test-image' does not reference a builder, patch it with "--builder" or "--cluster-builder"
+
 `
 
 				testhelpers.CommandTest{
-					Objects:        append([]runtime.Object{image}, testNamespacedBuilds...),
+					KpackObjects:   append([]runtime.Object{image}, testNamespacedBuilds...),
 					Args:           []string{imageName, "-n", namespace},
 					ExpectedOutput: expectedOutput,
-				}.TestKpack(t, cmdFunc)
+				}.TestK8sAndKpack(t, cmdFunc)
 			})
 
 			when("the namespace has no images", func() {
@@ -86,7 +93,7 @@ Build Reason:    COMMIT,BUILDPACK
 						Args:           []string{imageName, "-n", namespace},
 						ExpectErr:      true,
 						ExpectedOutput: "Error: images.kpack.io \"test-image\" not found\n",
-					}.TestKpack(t, cmdFunc)
+					}.TestK8sAndKpack(t, cmdFunc)
 
 				})
 			})
@@ -126,13 +133,19 @@ Last Failed Build
 Id:              2
 Build Reason:    COMMIT,BUILDPACK
 
+RESOURCE            CONDITION    STATUS    REASON    MESSAGE
+Image/test-image    Ready        False               
+
+Hints:
+  - Image 'test-image' does not reference a builder, patch it with "--builder" or "--cluster-builder"
+
 `
 
 				testhelpers.CommandTest{
-					Objects:        append([]runtime.Object{image}, testBuilds...),
+					KpackObjects:   append([]runtime.Object{image}, testBuilds...),
 					Args:           []string{imageName},
 					ExpectedOutput: expectedOutput,
-				}.TestKpack(t, cmdFunc)
+				}.TestK8sAndKpack(t, cmdFunc)
 			})
 
 			when("the namespace has no images", func() {
@@ -141,10 +154,191 @@ Build Reason:    COMMIT,BUILDPACK
 						Args:           []string{imageName},
 						ExpectErr:      true,
 						ExpectedOutput: "Error: images.kpack.io \"test-image\" not found\n",
-					}.TestKpack(t, cmdFunc)
+					}.TestK8sAndKpack(t, cmdFunc)
 
 				})
 			})
 		})
 	})
+
+	when("the image is not ready", func() {
+		img := &v1alpha1.Image{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      imageName,
+				Namespace: defaultNamespace,
+			},
+			Spec: v1alpha1.ImageSpec{
+				Tag: "some-registry.io/some-repo",
+				Builder: corev1.ObjectReference{
+					Kind: v1alpha1.ClusterBuilderKind,
+					Name: "some-cluster-builder",
+				},
+			},
+			Status: v1alpha1.ImageStatus{
+				Status: corev1alpha1.Status{
+					Conditions: []corev1alpha1.Condition{
+						{
+							Type:   corev1alpha1.ConditionReady,
+							Status: corev1.ConditionFalse,
+							Reason: v1alpha1.BuilderNotReady,
+						},
+						{
+							Type:   v1alpha1.ConditionBuilderReady,
+							Status: corev1.ConditionFalse,
+							Reason: v1alpha1.BuilderNotReady,
+						},
+					},
+				},
+			},
+		}
+
+		clusterBuilder := &v1alpha1.ClusterBuilder{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "some-cluster-builder",
+			},
+			Spec: v1alpha1.ClusterBuilderSpec{
+				BuilderSpec: v1alpha1.BuilderSpec{
+					Stack: corev1.ObjectReference{Kind: v1alpha1.ClusterStackKind, Name: "some-stack"},
+					Store: corev1.ObjectReference{Kind: v1alpha1.ClusterStoreKind, Name: "some-store"},
+					Order: []v1alpha1.OrderEntry{
+						{
+							Group: []v1alpha1.BuildpackRef{
+								{BuildpackInfo: v1alpha1.BuildpackInfo{Id: "org.cloudfoundry.nodejs"}},
+								{BuildpackInfo: v1alpha1.BuildpackInfo{Id: "paketo-buildpacks/java", Version: "5.0.0"}},
+							},
+						},
+					},
+				},
+			},
+			Status: v1alpha1.BuilderStatus{
+				Status: corev1alpha1.Status{
+					Conditions: []corev1alpha1.Condition{
+						{
+							Type:    corev1alpha1.ConditionReady,
+							Status:  corev1.ConditionFalse,
+							Message: "buildpack paketo-buildpacks/java@5.0.0 not found",
+						},
+					},
+				},
+			},
+		}
+
+		clusterStack := &v1alpha1.ClusterStack{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "some-stack",
+			},
+			Status: v1alpha1.ClusterStackStatus{
+				Status: corev1alpha1.Status{
+					Conditions: []corev1alpha1.Condition{
+						{
+							Type:   corev1alpha1.ConditionReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+		}
+
+		clusterStore := &v1alpha1.ClusterStore{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "some-store",
+			},
+			Status: v1alpha1.ClusterStoreStatus{
+				Buildpacks: []v1alpha1.StoreBuildpack{
+					{BuildpackInfo: v1alpha1.BuildpackInfo{Id: "org.cloudfoundry.nodejs", Version: "1.0.0"}},
+					{BuildpackInfo: v1alpha1.BuildpackInfo{Id: "paketo-buildpacks/java", Version: "4.0.0"}},
+				},
+				Status: corev1alpha1.Status{
+					Conditions: []corev1alpha1.Condition{
+						{
+							Type:   corev1alpha1.ConditionReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+		}
+
+		serviceAccount := &corev1.ServiceAccount{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "default",
+				Namespace: defaultNamespace,
+			},
+		}
+
+		registrySecret := &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "registry-secret",
+				Namespace:   defaultNamespace,
+				Annotations: map[string]string{"kpack.io/docker": "some-registry.io"},
+			},
+		}
+
+		it("shows the conditions of the chain and remediation hints", func() {
+			const expectedOutput = `Status:         Not Ready
+Message:        Builder 'some-cluster-builder' not ready
+LatestImage:    --
+
+Last Successful Build
+Id:              --
+Build Reason:    --
+
+Last Failed Build
+Id:              --
+Build Reason:    --
+
+RESOURCE                               CONDITION       STATUS    REASON             MESSAGE
+Image/test-image                       Ready           False     BuilderNotReady    
+Image/test-image                       BuilderReady    False     BuilderNotReady    
+ClusterBuilder/some-cluster-builder    Ready           False                        buildpack paketo-buildpacks/java@5.0.0 not found
+ClusterStack/some-stack                Ready           True                         
+ClusterStore/some-store                Ready           True                         
+
+Hints:
+  - ClusterBuilder 'some-cluster-builder' is not ready: buildpack paketo-buildpacks/java@5.0.0 not found
+  - ClusterStore 'some-store' is missing buildpack paketo-buildpacks/java@5.0.0
+  - Secret 'registry-secret' for registry 'some-registry.io' is not attached to service account 'default'
+
+`
+
+			testhelpers.CommandTest{
+				K8sObjects:     []runtime.Object{serviceAccount, registrySecret},
+				KpackObjects:   []runtime.Object{img, clusterBuilder, clusterStack, clusterStore},
+				Args:           []string{imageName},
+				ExpectedOutput: expectedOutput,
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+
+		it("reports missing resources in the chain", func() {
+			const expectedOutput = `Status:         Not Ready
+Message:        Builder 'some-cluster-builder' not ready
+LatestImage:    --
+
+Last Successful Build
+Id:              --
+Build Reason:    --
+
+Last Failed Build
+Id:              --
+Build Reason:    --
+
+RESOURCE                               CONDITION       STATUS      REASON             MESSAGE
+Image/test-image                       Ready           False       BuilderNotReady    
+Image/test-image                       BuilderReady    False       BuilderNotReady    
+ClusterBuilder/some-cluster-builder                    NotFound                       
+
+Hints:
+  - ClusterBuilder 'some-cluster-builder' not found, create it or patch the image to use an existing builder
+  - No secret for registry 'some-registry.io' is attached to service account 'default', create one with "kp secret create"
+
+`
+
+			testhelpers.CommandTest{
+				K8sObjects:     []runtime.Object{serviceAccount},
+				KpackObjects:   []runtime.Object{img},
+				Args:           []string{imageName},
+				ExpectedOutput: expectedOutput,
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+	})
 }
@@ -4,25 +4,43 @@
 package image
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/pivotal/build-service-cli/pkg/build"
-	"github.com/pivotal/build-service-cli/pkg/commands"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 )
 
-const BuildNeededAnnotation = "image.kpack.io/additionalBuildNeeded"
+const (
+	BuildNeededAnnotation = "image.kpack.io/additionalBuildNeeded"
 
-func NewTriggerCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	// TriggerReasonAnnotation records why a build was triggered with kp, it is
+	// informational and not read by kpack.
+	TriggerReasonAnnotation = "kp.kpack.io/triggerReason"
+
+	defaultTriggerTimeout = 5 * time.Minute
+	triggerPollInterval   = time.Second
+)
+
+func NewTriggerCommand(clientSetProvider k8s.ClientSetProvider, newImageWaiter func(k8s.ClientSet) ImageWaiter, confirmationProvider ConfirmationProvider) *cobra.Command {
 	var (
-		namespace string
+		namespace  string
+		reason     string
+		shouldWait bool
+		timeout    time.Duration
+		bulkOpts   bulkOptions
 	)
 
 	cmd := &cobra.Command{
@@ -30,42 +48,220 @@ func NewTriggerCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		Short: "Trigger an image build",
 		Long: `Trigger a build using current inputs for a specific image in the provided namespace.
 
+For images that have not built yet, the command reports that the first build is pending and what the image
+is waiting for. kpack schedules the first build once the builder and source are ready.
+
+The "--wait" flag waits for the resulting build, or the first build, to be scheduled, for at most the "--timeout" duration, and tails its logs.
+The "--reason" flag records why the build was triggered as an annotation on the resulting build, it requires "--wait".
+
+Builds may be triggered for multiple images at once by using the "--selector" and/or "--all-namespaces" flags
+instead of an image name. The selected images are listed for confirmation unless "--force" is provided.
+
 The namespace defaults to the kubernetes current-context namespace.`,
-		Example: "kp image trigger my-image",
-		Args:    commands.ExactArgsWithUsage(1),
+		Example: `kp image trigger my-image
+kp image trigger my-image --wait --reason "base image CVE"
+kp image trigger -l team=my-team
+kp image trigger --all-namespaces -l builder=my-builder --force`,
+		Args: imageNameOrSelectorArgs(&bulkOpts),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if reason != "" && !shouldWait {
+				return errors.New("reason requires wait, the reason is recorded on the resulting build")
+			}
+
 			cs, err := clientSetProvider.GetClientSet(namespace)
 			if err != nil {
 				return err
 			}
 
-			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
-				LabelSelector: v1alpha1.ImageLabel + "=" + args[0],
-			})
+			if bulkOpts.isBulk() {
+				if shouldWait {
+					return errors.New("wait cannot be used when triggering multiple images")
+				}
+				return bulkTrigger(cmd, cs, confirmationProvider, bulkOpts)
+			}
+
+			img, triggered, err := triggerBuild(cs, cs.Namespace, args[0])
 			if err != nil {
 				return err
 			}
 
-			if len(buildList.Items) == 0 {
-				return errors.New("no builds found")
+			if triggered {
+				_, err = fmt.Fprintf(cmd.OutOrStderr(), "Triggered build for Image %q\n", args[0])
 			} else {
-				sort.Slice(buildList.Items, build.Sort(buildList.Items))
+				err = printFirstBuildPending(cmd.OutOrStderr(), cs, img)
+			}
+			if err != nil || !shouldWait {
+				return err
+			}
+
+			img, err = waitForNewBuild(cmd.Context(), cs, img, timeout)
+			if err != nil {
+				return err
+			}
 
-				build := buildList.Items[len(buildList.Items)-1].DeepCopy()
-				build.Annotations[BuildNeededAnnotation] = time.Now().String()
-				_, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).Update(build)
-				if err != nil {
+			if reason != "" {
+				if err := annotateTriggerReason(cs, img.Namespace, img.Status.LatestBuildRef, reason); err != nil {
 					return err
 				}
-
-				_, err = fmt.Fprintf(cmd.OutOrStderr(), "Triggered build for Image %q\n", args[0])
-				return err
 			}
+
+			_, err = newImageWaiter(cs).Wait(cmd.Context(), cmd.OutOrStdout(), img)
+			return err
 		},
 		SilenceUsage: true,
 	}
 
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVar(&reason, "reason", "", "reason for triggering the build, recorded on the resulting build (requires --wait)")
+	cmd.Flags().BoolVarP(&shouldWait, "wait", "w", false, "wait for the triggered build to be scheduled and tail its logs")
+	cmd.Flags().DurationVar(&timeout, "timeout", defaultTriggerTimeout, "maximum time to wait for the triggered build to be scheduled")
+	setBulkFlags(cmd, &bulkOpts)
 
 	return cmd
 }
+
+func bulkTrigger(cmd *cobra.Command, cs k8s.ClientSet, confirmationProvider ConfirmationProvider, opts bulkOptions) error {
+	images, err := listSelectedImages(cs, opts)
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := confirmBulk(cmd.OutOrStdout(), confirmationProvider, opts, images, "trigger")
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), "Skipping image trigger")
+		return err
+	}
+
+	results := runBulk(images, opts.concurrency, func(_ int, img v1alpha1.Image) (string, error) {
+		_, triggered, err := triggerBuild(cs, img.Namespace, img.Name)
+		if err != nil || triggered {
+			return "triggered", err
+		}
+		return "first build pending", nil
+	})
+
+	return displayBulkResults(cmd.OutOrStdout(), results, "trigger")
+}
+
+// triggerBuild requests a new build of the image by annotating its latest
+// build. Images without builds are not annotated: kpack copies the image
+// annotations onto every build, which would cause a build on every
+// reconcile, and it schedules the first build by itself once the image is
+// ready. triggerBuild reports whether a build was triggered.
+func triggerBuild(cs k8s.ClientSet, namespace, name string) (*v1alpha1.Image, bool, error) {
+	img, err := cs.KpackClient.KpackV1alpha1().Images(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+
+	buildList, err := cs.KpackClient.KpackV1alpha1().Builds(namespace).List(metav1.ListOptions{
+		LabelSelector: v1alpha1.ImageLabel + "=" + name,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(buildList.Items) == 0 {
+		return img, false, nil
+	}
+
+	sort.Slice(buildList.Items, build.Sort(buildList.Items))
+
+	bld := buildList.Items[len(buildList.Items)-1].DeepCopy()
+	if bld.Annotations == nil {
+		bld.Annotations = map[string]string{}
+	}
+	bld.Annotations[BuildNeededAnnotation] = time.Now().String()
+	if _, err = cs.KpackClient.KpackV1alpha1().Builds(namespace).Update(bld); err != nil {
+		return nil, false, err
+	}
+
+	return img, true, nil
+}
+
+// printFirstBuildPending reports the builder and source readiness of an
+// image that has no builds.
+func printFirstBuildPending(out io.Writer, cs k8s.ClientSet, img *v1alpha1.Image) error {
+	var waitingFor []string
+
+	builderReady := img.Status.GetCondition(v1alpha1.ConditionBuilderReady)
+	if ready := img.Status.GetCondition(corev1alpha1.ConditionReady); ready != nil && ready.Reason == v1alpha1.BuilderNotFound {
+		waitingFor = append(waitingFor, conditionMessage("builder", ready))
+	} else if !builderReady.IsTrue() {
+		waitingFor = append(waitingFor, conditionMessage("builder", builderReady))
+	}
+
+	sourceResolver, err := cs.KpackClient.KpackV1alpha1().SourceResolvers(img.Namespace).Get(img.SourceResolverName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		waitingFor = append(waitingFor, "source: not resolved yet")
+	} else if err != nil {
+		return err
+	} else if !sourceResolver.Ready() {
+		waitingFor = append(waitingFor, conditionMessage("source", sourceResolver.Status.GetCondition(corev1alpha1.ConditionReady)))
+	}
+
+	if len(waitingFor) == 0 {
+		waitingFor = append(waitingFor, "builder and source are ready, the build has not been scheduled yet")
+	}
+
+	_, err = fmt.Fprintf(out, "First build of Image %q is pending: %s\n", img.Name, strings.Join(waitingFor, "; "))
+	return err
+}
+
+func conditionMessage(name string, cond *corev1alpha1.Condition) string {
+	switch {
+	case cond == nil:
+		return fmt.Sprintf("%s: not ready yet", name)
+	case cond.Message != "":
+		return fmt.Sprintf("%s: %s", name, cond.Message)
+	case cond.Reason != "":
+		return fmt.Sprintf("%s: %s", name, cond.Reason)
+	default:
+		return fmt.Sprintf("%s: not ready", name)
+	}
+}
+
+func waitForNewBuild(ctx context.Context, cs k8s.ClientSet, img *v1alpha1.Image, timeout time.Duration) (*v1alpha1.Image, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	previousBuild := img.Status.LatestBuildRef
+
+	var updated *v1alpha1.Image
+	err := wait.PollImmediateUntil(triggerPollInterval, func() (bool, error) {
+		var err error
+		updated, err = cs.KpackClient.KpackV1alpha1().Images(img.Namespace).Get(img.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		latestBuild := updated.Status.LatestBuildRef
+		return latestBuild != "" && latestBuild != previousBuild, nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return nil, errors.Errorf("timed out after %s waiting for build of image %q to be scheduled", timeout, img.Name)
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "waiting for build of image %q to be scheduled", img.Name)
+	}
+
+	return updated, nil
+}
+
+func annotateTriggerReason(cs k8s.ClientSet, namespace, buildName, reason string) error {
+	bld, err := cs.KpackClient.KpackV1alpha1().Builds(namespace).Get(buildName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	bld = bld.DeepCopy()
+	if bld.Annotations == nil {
+		bld.Annotations = map[string]string{}
+	}
+	bld.Annotations[TriggerReasonAnnotation] = reason
+	_, err = cs.KpackClient.KpackV1alpha1().Builds(namespace).Update(bld)
+	return err
+}
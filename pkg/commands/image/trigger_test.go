@@ -8,11 +8,19 @@ import (
 	"testing"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
 	"github.com/sclevine/spec"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
 
+	commandsfakes "github.com/pivotal/build-service-cli/pkg/commands/fakes"
 	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	imgfakes "github.com/pivotal/build-service-cli/pkg/image/fakes"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
 )
 
@@ -26,15 +34,37 @@ func testImageTrigger(t *testing.T, when spec.G, it spec.S) {
 		namespace        = "some-namespace"
 	)
 
-	testBuilds := testhelpers.MakeTestBuilds("some-image", defaultNamespace)
-	testNamespacedBuilds := testhelpers.MakeTestBuilds("some-image", namespace)
+	var fakeImageWaiter *imgfakes.FakeImageWaiter
+
+	newImageWaiter := func(k8s.ClientSet) image.ImageWaiter {
+		return fakeImageWaiter
+	}
+
+	makeImage := func(namespace string) *v1alpha1.Image {
+		return &v1alpha1.Image{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-image",
+				Namespace: namespace,
+			},
+			Status: v1alpha1.ImageStatus{
+				LatestBuildRef: "build-three",
+			},
+		}
+	}
+
+	testBuilds := append(testhelpers.MakeTestBuilds("some-image", defaultNamespace), makeImage(defaultNamespace))
+	testNamespacedBuilds := append(testhelpers.MakeTestBuilds("some-image", namespace), makeImage(namespace))
+
+	it.Before(func() {
+		fakeImageWaiter = &imgfakes.FakeImageWaiter{}
+	})
 
 	when("a namespace is provided", func() {
 		when("an image build is available", func() {
 			it("triggers the latest build", func() {
 				clientSet := fake.NewSimpleClientset(testNamespacedBuilds...)
 				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-				cmd := image.NewTriggerCommand(clientSetProvider)
+				cmd := image.NewTriggerCommand(clientSetProvider, newImageWaiter, commandsfakes.NewFakeConfirmationProvider(true, nil))
 
 				out := &bytes.Buffer{}
 				cmd.SetOut(out)
@@ -51,21 +81,136 @@ func testImageTrigger(t *testing.T, when spec.G, it spec.S) {
 				build := actions.Updates[0].GetObject().(*v1alpha1.Build)
 				require.Equal(t, build.Name, "build-three")
 				require.NotEmpty(t, build.Annotations[image.BuildNeededAnnotation])
+				require.Len(t, fakeImageWaiter.Calls, 0)
 			})
 		})
 
 		when("an image build is not available", func() {
+			it("reports that the first build is pending without updating the image", func() {
+				img := makeImage(namespace)
+				img.Status.LatestBuildRef = ""
+				img.Status.Conditions = corev1alpha1.Conditions{
+					{
+						Type:    corev1alpha1.ConditionReady,
+						Status:  corev1.ConditionFalse,
+						Reason:  v1alpha1.BuilderNotFound,
+						Message: "Unable to find builder some-builder.",
+					},
+				}
+				sourceResolver := &v1alpha1.SourceResolver{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      img.SourceResolverName(),
+						Namespace: namespace,
+					},
+				}
+
+				clientSet := fake.NewSimpleClientset(img, sourceResolver)
+				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+				cmd := image.NewTriggerCommand(clientSetProvider, newImageWaiter, commandsfakes.NewFakeConfirmationProvider(true, nil))
+
+				out := &bytes.Buffer{}
+				cmd.SetOut(out)
+				cmd.SetErr(out)
+				cmd.SetArgs([]string{"some-image", "-n", namespace})
+
+				err := cmd.Execute()
+				require.NoError(t, err)
+				require.Equal(t, "First build of Image \"some-image\" is pending: builder: Unable to find builder some-builder.; source: not ready yet\n", out.String())
+
+				actions, err := testhelpers.ActionRecorderList{clientSet}.ActionsByVerb()
+				require.NoError(t, err)
+				require.Len(t, actions.Updates, 0)
+				require.Len(t, fakeImageWaiter.Calls, 0)
+			})
+
+			it("waits for the first build and records the reason on it", func() {
+				img := makeImage(namespace)
+				img.Status.LatestBuildRef = ""
+				img.Status.Conditions = corev1alpha1.Conditions{
+					{Type: v1alpha1.ConditionBuilderReady, Status: corev1.ConditionTrue},
+				}
+				sourceResolver := &v1alpha1.SourceResolver{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      img.SourceResolverName(),
+						Namespace: namespace,
+					},
+					Status: v1alpha1.SourceResolverStatus{
+						Status: corev1alpha1.Status{
+							Conditions: corev1alpha1.Conditions{
+								{Type: corev1alpha1.ConditionReady, Status: corev1.ConditionTrue},
+							},
+						},
+					},
+				}
+
+				clientSet := fake.NewSimpleClientset(img, sourceResolver)
+
+				// simulate kpack scheduling the first build after the image was read
+				imageGets := 0
+				clientSet.PrependReactor("get", "images", func(clientgotesting.Action) (bool, runtime.Object, error) {
+					imageGets++
+					if imageGets != 2 {
+						return false, nil, nil
+					}
+
+					tracker := clientSet.Tracker()
+					err := tracker.Add(&v1alpha1.Build{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "build-one",
+							Namespace: namespace,
+							Labels: map[string]string{
+								v1alpha1.ImageLabel:       "some-image",
+								v1alpha1.BuildNumberLabel: "1",
+							},
+						},
+					})
+					if err != nil {
+						return true, nil, err
+					}
+
+					built := img.DeepCopy()
+					built.Status.LatestBuildRef = "build-one"
+					return false, nil, tracker.Update(v1alpha1.SchemeGroupVersion.WithResource("images"), built, namespace)
+				})
+
+				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+				cmd := image.NewTriggerCommand(clientSetProvider, newImageWaiter, commandsfakes.NewFakeConfirmationProvider(true, nil))
+
+				out := &bytes.Buffer{}
+				cmd.SetOut(out)
+				cmd.SetErr(out)
+				cmd.SetArgs([]string{"some-image", "-n", namespace, "--wait", "--reason", "first release"})
+
+				err := cmd.Execute()
+				require.NoError(t, err)
+				require.Equal(t, "First build of Image \"some-image\" is pending: builder and source are ready, the build has not been scheduled yet\n", out.String())
+
+				actions, err := testhelpers.ActionRecorderList{clientSet}.ActionsByVerb()
+				require.NoError(t, err)
+
+				require.Len(t, actions.Updates, 1)
+				build := actions.Updates[0].GetObject().(*v1alpha1.Build)
+				require.Equal(t, "build-one", build.Name)
+				require.Equal(t, "first release", build.Annotations[image.TriggerReasonAnnotation])
+				require.Empty(t, build.Annotations[image.BuildNeededAnnotation])
+
+				require.Len(t, fakeImageWaiter.Calls, 1)
+				require.Equal(t, "build-one", fakeImageWaiter.Calls[0].Status.LatestBuildRef)
+			})
+		})
+
+		when("the image does not exist", func() {
 			it("returns an error", func() {
 				clientSet := fake.NewSimpleClientset()
 				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-				cmd := image.NewTriggerCommand(clientSetProvider)
+				cmd := image.NewTriggerCommand(clientSetProvider, newImageWaiter, commandsfakes.NewFakeConfirmationProvider(true, nil))
 
 				out := &bytes.Buffer{}
 				cmd.SetOut(out)
 				cmd.SetArgs([]string{"some-image", "-n", namespace})
 
 				err := cmd.Execute()
-				require.EqualError(t, err, "no builds found")
+				require.EqualError(t, err, "images.kpack.io \"some-image\" not found")
 			})
 		})
 	})
@@ -75,11 +220,11 @@ func testImageTrigger(t *testing.T, when spec.G, it spec.S) {
 			it("triggers the latest build", func() {
 				clientSet := fake.NewSimpleClientset(testBuilds...)
 				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-				cmd := image.NewTriggerCommand(clientSetProvider)
+				cmd := image.NewTriggerCommand(clientSetProvider, newImageWaiter, commandsfakes.NewFakeConfirmationProvider(true, nil))
 
 				out := &bytes.Buffer{}
 				cmd.SetOut(out)
-				cmd.SetArgs([]string{"some-image", "-n", defaultNamespace})
+				cmd.SetArgs([]string{"some-image"})
 
 				err := cmd.Execute()
 				require.NoError(t, err)
@@ -94,20 +239,180 @@ func testImageTrigger(t *testing.T, when spec.G, it spec.S) {
 				require.NotEmpty(t, build.Annotations[image.BuildNeededAnnotation])
 			})
 		})
+	})
 
-		when("an image build is not available", func() {
-			it("returns an error", func() {
-				clientSet := fake.NewSimpleClientset()
-				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-				cmd := image.NewTriggerCommand(clientSetProvider)
+	when("a reason or wait is provided", func() {
+		var clientSet *fake.Clientset
 
-				out := &bytes.Buffer{}
-				cmd.SetOut(out)
-				cmd.SetArgs([]string{"some-image", "-n", defaultNamespace})
+		it.Before(func() {
+			clientSet = fake.NewSimpleClientset(testBuilds...)
 
-				err := cmd.Execute()
-				require.EqualError(t, err, "no builds found")
+			// simulate kpack scheduling a new build in response to the trigger
+			clientSet.PrependReactor("update", "builds", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				bld := action.(clientgotesting.UpdateAction).GetObject().(*v1alpha1.Build)
+				if bld.Annotations[image.BuildNeededAnnotation] == "" || bld.Name != "build-three" {
+					return false, nil, nil
+				}
+
+				tracker := clientSet.Tracker()
+				err := tracker.Add(&v1alpha1.Build{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "build-four",
+						Namespace: defaultNamespace,
+						Labels: map[string]string{
+							v1alpha1.ImageLabel:       "some-image",
+							v1alpha1.BuildNumberLabel: "4",
+						},
+					},
+				})
+				if err != nil {
+					return true, nil, err
+				}
+
+				img := makeImage(defaultNamespace)
+				img.Status.LatestBuildRef = "build-four"
+				return false, nil, tracker.Update(v1alpha1.SchemeGroupVersion.WithResource("images"), img, defaultNamespace)
 			})
 		})
+
+		it("records the reason on the resulting build", func() {
+			clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+			cmd := image.NewTriggerCommand(clientSetProvider, newImageWaiter, commandsfakes.NewFakeConfirmationProvider(true, nil))
+
+			out := &bytes.Buffer{}
+			cmd.SetOut(out)
+			cmd.SetArgs([]string{"some-image", "--wait", "--reason", "base image CVE"})
+
+			err := cmd.Execute()
+			require.NoError(t, err)
+			require.Equal(t, "Triggered build for Image \"some-image\"\n", out.String())
+
+			actions, err := testhelpers.ActionRecorderList{clientSet}.ActionsByVerb()
+			require.NoError(t, err)
+
+			require.Len(t, actions.Updates, 2)
+			build := actions.Updates[1].GetObject().(*v1alpha1.Build)
+			require.Equal(t, "build-four", build.Name)
+			require.Equal(t, "base image CVE", build.Annotations[image.TriggerReasonAnnotation])
+			require.Len(t, fakeImageWaiter.Calls, 1)
+		})
+
+		it("waits on the resulting build", func() {
+			clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+			cmd := image.NewTriggerCommand(clientSetProvider, newImageWaiter, commandsfakes.NewFakeConfirmationProvider(true, nil))
+
+			out := &bytes.Buffer{}
+			cmd.SetOut(out)
+			cmd.SetArgs([]string{"some-image", "--wait"})
+
+			err := cmd.Execute()
+			require.NoError(t, err)
+
+			require.Len(t, fakeImageWaiter.Calls, 1)
+			require.Equal(t, "build-four", fakeImageWaiter.Calls[0].Status.LatestBuildRef)
+		})
+	})
+
+	when("the resulting build is not scheduled", func() {
+		it("stops waiting after the timeout", func() {
+			clientSet := fake.NewSimpleClientset(testBuilds...)
+			clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+			cmd := image.NewTriggerCommand(clientSetProvider, newImageWaiter, commandsfakes.NewFakeConfirmationProvider(true, nil))
+
+			out := &bytes.Buffer{}
+			cmd.SetOut(out)
+			cmd.SetErr(out)
+			cmd.SetArgs([]string{"some-image", "--wait", "--timeout", "10ms"})
+
+			err := cmd.Execute()
+			require.EqualError(t, err, "timed out after 10ms waiting for build of image \"some-image\" to be scheduled")
+			require.Len(t, fakeImageWaiter.Calls, 0)
+		})
+	})
+
+	when("a reason is provided without wait", func() {
+		it("returns an error", func() {
+			clientSet := fake.NewSimpleClientset(testBuilds...)
+			clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+			cmd := image.NewTriggerCommand(clientSetProvider, newImageWaiter, commandsfakes.NewFakeConfirmationProvider(true, nil))
+
+			out := &bytes.Buffer{}
+			cmd.SetOut(out)
+			cmd.SetErr(out)
+			cmd.SetArgs([]string{"some-image", "--reason", "base image CVE"})
+
+			err := cmd.Execute()
+			require.EqualError(t, err, "reason requires wait, the reason is recorded on the resulting build")
+
+			actions, err := testhelpers.ActionRecorderList{clientSet}.ActionsByVerb()
+			require.NoError(t, err)
+			require.Len(t, actions.Updates, 0)
+		})
+	})
+
+	when("a selector is provided", func() {
+		it("confirms and triggers a build of every selected image", func() {
+			labeledImage := makeImage(defaultNamespace)
+			labeledImage.Labels = map[string]string{"team": "some-team"}
+			imageWithoutBuilds := &v1alpha1.Image{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "image-without-builds",
+					Namespace: defaultNamespace,
+					Labels:    map[string]string{"team": "some-team"},
+				},
+			}
+			unlabeledImage := &v1alpha1.Image{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "unlabeled-image",
+					Namespace: defaultNamespace,
+				},
+			}
+
+			clientSet := fake.NewSimpleClientset(append(testhelpers.MakeTestBuilds("some-image", defaultNamespace), labeledImage, imageWithoutBuilds, unlabeledImage)...)
+			clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+			fakeConfirmationProvider := commandsfakes.NewFakeConfirmationProvider(true, nil)
+			cmd := image.NewTriggerCommand(clientSetProvider, newImageWaiter, fakeConfirmationProvider)
+
+			out := &bytes.Buffer{}
+			cmd.SetOut(out)
+			cmd.SetErr(out)
+			cmd.SetArgs([]string{"-l", "team=some-team", "--concurrency", "1"})
+
+			err := cmd.Execute()
+			require.NoError(t, err)
+			require.NoError(t, fakeConfirmationProvider.WasRequestedWithMsg("Please confirm trigger of 2 image(s) by typing 'y': "))
+			require.Equal(t, `The following images were selected for trigger:
+NAMESPACE                 NAME
+some-default-namespace    image-without-builds
+some-default-namespace    some-image
+
+NAMESPACE                 NAME                    RESULT
+some-default-namespace    image-without-builds    first build pending
+some-default-namespace    some-image              triggered
+
+`, out.String())
+
+			actions, err := testhelpers.ActionRecorderList{clientSet}.ActionsByVerb()
+			require.NoError(t, err)
+
+			require.Len(t, actions.Updates, 1)
+			build := actions.Updates[0].GetObject().(*v1alpha1.Build)
+			require.Equal(t, build.Name, "build-three")
+			require.NotEmpty(t, build.Annotations[image.BuildNeededAnnotation])
+		})
+
+		it("does not allow waiting on multiple images", func() {
+			clientSet := fake.NewSimpleClientset()
+			clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+			cmd := image.NewTriggerCommand(clientSetProvider, newImageWaiter, commandsfakes.NewFakeConfirmationProvider(true, nil))
+
+			out := &bytes.Buffer{}
+			cmd.SetOut(out)
+			cmd.SetErr(out)
+			cmd.SetArgs([]string{"-A", "--wait"})
+
+			err := cmd.Execute()
+			require.EqualError(t, err, "wait cannot be used when triggering multiple images")
+		})
 	})
 }
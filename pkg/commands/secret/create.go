@@ -130,12 +130,12 @@ kp secret create my-git-cred --git-url https://github.com --git-user my-git-user
 }
 
 func updateManagedSecretsAnnotation(err error, sa *corev1.ServiceAccount, name, target string) error {
-	managedSecrets, err := readManagedSecrets(sa)
+	managedSecrets, err := secret.ReadManagedSecrets(sa)
 	if err != nil {
 		return err
 	}
 
 	managedSecrets[name] = target
 
-	return writeManagedSecrets(managedSecrets, sa)
+	return secret.WriteManagedSecrets(managedSecrets, sa)
 }
@@ -86,7 +86,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						Name:      "default",
 						Namespace: namespace,
 						Annotations: map[string]string{
-							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, secret.DockerhubUrl),
+							secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, secret.DockerhubUrl),
 						},
 					},
 					ImagePullSecrets: []corev1.LocalObjectReference{
@@ -144,7 +144,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						Name:      "default",
 						Namespace: namespace,
 						Annotations: map[string]string{
-							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, registry),
+							secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, registry),
 						},
 					},
 					ImagePullSecrets: []corev1.LocalObjectReference{
@@ -200,7 +200,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						Name:      "default",
 						Namespace: namespace,
 						Annotations: map[string]string{
-							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, secret.GcrUrl),
+							secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, secret.GcrUrl),
 						},
 					},
 					ImagePullSecrets: []corev1.LocalObjectReference{
@@ -259,7 +259,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						Name:      "default",
 						Namespace: namespace,
 						Annotations: map[string]string{
-							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, gitRepo),
+							secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, gitRepo),
 						},
 					},
 					Secrets: []corev1.ObjectReference{
@@ -317,7 +317,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						Name:      "default",
 						Namespace: namespace,
 						Annotations: map[string]string{
-							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, gitRepo),
+							secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, gitRepo),
 						},
 					},
 					Secrets: []corev1.ObjectReference{
@@ -373,7 +373,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						Name:      "default",
 						Namespace: defaultNamespace,
 						Annotations: map[string]string{
-							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, secret.DockerhubUrl),
+							secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, secret.DockerhubUrl),
 						},
 					},
 					ImagePullSecrets: []corev1.LocalObjectReference{
@@ -431,7 +431,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						Name:      "default",
 						Namespace: defaultNamespace,
 						Annotations: map[string]string{
-							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, registry),
+							secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, registry),
 						},
 					},
 					ImagePullSecrets: []corev1.LocalObjectReference{
@@ -487,7 +487,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						Name:      "default",
 						Namespace: defaultNamespace,
 						Annotations: map[string]string{
-							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, secret.GcrUrl),
+							secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, secret.GcrUrl),
 						},
 					},
 					ImagePullSecrets: []corev1.LocalObjectReference{
@@ -546,7 +546,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						Name:      "default",
 						Namespace: defaultNamespace,
 						Annotations: map[string]string{
-							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, gitRepo),
+							secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, gitRepo),
 						},
 					},
 					Secrets: []corev1.ObjectReference{
@@ -604,7 +604,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						Name:      "default",
 						Namespace: defaultNamespace,
 						Annotations: map[string]string{
-							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, gitRepo),
+							secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, gitRepo),
 						},
 					},
 					Secrets: []corev1.ObjectReference{
@@ -656,7 +656,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 				Name:      "default",
 				Namespace: defaultNamespace,
 				Annotations: map[string]string{
-					secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, secret.DockerhubUrl),
+					secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, secret.DockerhubUrl),
 				},
 			},
 			ImagePullSecrets: []corev1.LocalObjectReference{
@@ -12,6 +12,7 @@ import (
 
 	"github.com/pivotal/build-service-cli/pkg/commands"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/secret"
 )
 
 func NewDeleteCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
@@ -65,7 +66,7 @@ The namespace defaults to the kubernetes current-context namespace.`,
 }
 
 func deleteSecretsFromServiceAccount(sa *corev1.ServiceAccount, name string) (bool, error) {
-	managedSecrets, err := readManagedSecrets(sa)
+	managedSecrets, err := secret.ReadManagedSecrets(sa)
 	if err != nil {
 		return false, err
 	}
@@ -88,7 +89,7 @@ func deleteSecretsFromServiceAccount(sa *corev1.ServiceAccount, name string) (bo
 		}
 	}
 
-	err = writeManagedSecrets(managedSecrets, sa)
+	err = secret.WriteManagedSecrets(managedSecrets, sa)
 	if err != nil {
 		return false, err
 	}
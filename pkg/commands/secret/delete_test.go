@@ -52,7 +52,7 @@ func testSecretDeleteCommand(t *testing.T, when spec.G, it spec.S) {
 							Name:      "default",
 							Namespace: defaultNamespace,
 							Annotations: map[string]string{
-								secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s", "foo":"bar"}`, secretName, secret.DockerhubUrl),
+								secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s", "foo":"bar"}`, secretName, secret.DockerhubUrl),
 							},
 						},
 						Secrets: []corev1.ObjectReference{
@@ -68,7 +68,7 @@ func testSecretDeleteCommand(t *testing.T, when spec.G, it spec.S) {
 							Name:      "default",
 							Namespace: defaultNamespace,
 							Annotations: map[string]string{
-								secretcmds.ManagedSecretAnnotationKey: `{"foo":"bar"}`,
+								secret.ManagedSecretAnnotationKey: `{"foo":"bar"}`,
 							},
 						},
 					}
@@ -147,7 +147,7 @@ func testSecretDeleteCommand(t *testing.T, when spec.G, it spec.S) {
 							Name:      "default",
 							Namespace: namespace,
 							Annotations: map[string]string{
-								secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s", "foo":"bar"}`, secretName, secret.DockerhubUrl),
+								secret.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s", "foo":"bar"}`, secretName, secret.DockerhubUrl),
 							},
 						},
 						Secrets: []corev1.ObjectReference{
@@ -163,7 +163,7 @@ func testSecretDeleteCommand(t *testing.T, when spec.G, it spec.S) {
 							Name:      "default",
 							Namespace: namespace,
 							Annotations: map[string]string{
-								secretcmds.ManagedSecretAnnotationKey: `{"foo":"bar"}`,
+								secret.ManagedSecretAnnotationKey: `{"foo":"bar"}`,
 							},
 						},
 					}
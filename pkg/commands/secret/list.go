@@ -13,6 +13,7 @@ import (
 
 	"github.com/pivotal/build-service-cli/pkg/commands"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/secret"
 )
 
 func NewListCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
@@ -53,7 +54,7 @@ The namespace defaults to the kubernetes current-context namespace.`,
 }
 
 func displaySecretsTable(cmd *cobra.Command, sa *corev1.ServiceAccount) error {
-	managedSecrets, err := readManagedSecrets(sa)
+	managedSecrets, err := secret.ReadManagedSecrets(sa)
 	if err != nil {
 		return err
 	}
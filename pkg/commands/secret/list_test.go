@@ -14,6 +14,7 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 
 	secretcmds "github.com/pivotal/build-service-cli/pkg/commands/secret"
+	"github.com/pivotal/build-service-cli/pkg/secret"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
 )
 
@@ -40,7 +41,7 @@ func testSecretListCommand(t *testing.T, when spec.G, it spec.S) {
 							Name:      "default",
 							Namespace: defaultNamespace,
 							Annotations: map[string]string{
-								secretcmds.ManagedSecretAnnotationKey: `{"secret-one":"https://index.docker.io/v1/", "secret-two":"some-git-url", "secret-three":""}`,
+								secret.ManagedSecretAnnotationKey: `{"secret-one":"https://index.docker.io/v1/", "secret-two":"some-git-url", "secret-three":""}`,
 							},
 						},
 						Secrets: []corev1.ObjectReference{
@@ -107,7 +108,7 @@ secret-two      some-git-url
 							Name:      "default",
 							Namespace: namespace,
 							Annotations: map[string]string{
-								secretcmds.ManagedSecretAnnotationKey: `{"secret-one":"https://index.docker.io/v1/", "secret-two":"some-git-url", "secret-three":""}`,
+								secret.ManagedSecretAnnotationKey: `{"secret-one":"https://index.docker.io/v1/", "secret-two":"some-git-url", "secret-three":""}`,
 							},
 						},
 						Secrets: []corev1.ObjectReference{
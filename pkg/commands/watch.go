@@ -0,0 +1,100 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh/terminal"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const WatchFlag = "watch"
+
+// WatchEvent is a single line of "--watch -o json" output.
+type WatchEvent struct {
+	Type   watch.EventType `json:"type"`
+	Object runtime.Object  `json:"object"`
+}
+
+// Watch calls handle for each added, modified or deleted object received
+// from w until the watch is closed or the context is done.
+func Watch(ctx context.Context, w watch.Interface, handle func(watch.Event) error) error {
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+
+			switch event.Type {
+			case watch.Added, watch.Modified, watch.Deleted:
+				if err := handle(event); err != nil {
+					return err
+				}
+			case watch.Error:
+				return errors.Wrap(k8serrors.FromObject(event.Object), "watch failed")
+			}
+		}
+	}
+}
+
+// WriteWatchEvent writes an event as a single line of json.
+func WriteWatchEvent(out io.Writer, eventType watch.EventType, obj runtime.Object) error {
+	data, err := json.Marshal(WatchEvent{Type: eventType, Object: obj})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// Redrawer repaints its output in place when writing to a terminal.
+// On any other writer each render is appended to the previous one.
+type Redrawer struct {
+	out   io.Writer
+	tty   bool
+	lines int
+}
+
+func NewRedrawer(out io.Writer) *Redrawer {
+	return &Redrawer{out: out, tty: IsTerminal(out)}
+}
+
+// IsTerminal reports whether a writer is a terminal.
+func IsTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
+
+func (r *Redrawer) Redraw(render func(io.Writer) error) error {
+	buf := &bytes.Buffer{}
+	if err := render(buf); err != nil {
+		return err
+	}
+
+	if r.tty && r.lines > 0 {
+		// move the cursor to the start of the previous render and clear to the end of the screen
+		if _, err := fmt.Fprintf(r.out, "\033[%dA\033[J", r.lines); err != nil {
+			return err
+		}
+	}
+
+	r.lines = bytes.Count(buf.Bytes(), []byte("\n"))
+	_, err := r.out.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,83 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+)
+
+func TestWatch(t *testing.T) {
+	spec.Run(t, "TestWatch", testWatch)
+}
+
+func testWatch(t *testing.T, when spec.G, it spec.S) {
+	when("Watch", func() {
+		it("handles added, modified and deleted events until the watch closes", func() {
+			w := watch.NewFakeWithChanSize(4, false)
+			w.Add(&metav1.Status{Message: "one"})
+			w.Action(watch.Bookmark, &metav1.Status{Message: "bookmark"})
+			w.Modify(&metav1.Status{Message: "two"})
+			w.Delete(&metav1.Status{Message: "three"})
+			w.Stop()
+
+			var handled []string
+			err := commands.Watch(context.Background(), w, func(event watch.Event) error {
+				handled = append(handled, fmt.Sprintf("%s %s", event.Type, event.Object.(*metav1.Status).Message))
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, []string{"ADDED one", "MODIFIED two", "DELETED three"}, handled)
+		})
+
+		it("returns an error for error events", func() {
+			w := watch.NewFakeWithChanSize(1, false)
+			w.Error(&metav1.Status{Status: metav1.StatusFailure, Message: "some-error", Reason: metav1.StatusReasonExpired})
+			w.Stop()
+
+			err := commands.Watch(context.Background(), w, func(event watch.Event) error {
+				t.Fatal("unexpected event")
+				return nil
+			})
+			require.EqualError(t, err, "watch failed: some-error")
+		})
+	})
+
+	when("WriteWatchEvent", func() {
+		it("writes the event as a single line of json", func() {
+			out := &bytes.Buffer{}
+			err := commands.WriteWatchEvent(out, watch.Added, &metav1.Status{Message: "some-message"})
+			require.NoError(t, err)
+			require.Equal(t, `{"type":"ADDED","object":{"metadata":{},"message":"some-message"}}`+"\n", out.String())
+		})
+	})
+
+	when("Redrawer", func() {
+		it("appends each render when not writing to a terminal", func() {
+			out := &bytes.Buffer{}
+			redrawer := commands.NewRedrawer(out)
+
+			for _, s := range []string{"first\n", "second\n"} {
+				s := s
+				err := redrawer.Redraw(func(w io.Writer) error {
+					_, err := io.WriteString(w, s)
+					return err
+				})
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, "first\nsecond\n", out.String())
+		})
+	})
+}
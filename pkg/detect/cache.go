@@ -0,0 +1,78 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package detect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+// BuilderInfo is the part of a builder needed for detection. It is cached
+// locally so that detection works offline.
+type BuilderInfo struct {
+	Kind       string                         `json:"kind"`
+	Namespace  string                         `json:"namespace,omitempty"`
+	Name       string                         `json:"name"`
+	Order      []v1alpha1.OrderEntry          `json:"order"`
+	Buildpacks v1alpha1.BuildpackMetadataList `json:"buildpacks,omitempty"`
+}
+
+type BuilderCache interface {
+	Load(kind, namespace, name string) (BuilderInfo, error)
+	Save(info BuilderInfo) error
+}
+
+// FileCache stores builder info as json files in a directory.
+type FileCache struct {
+	Dir string
+}
+
+func NewDefaultFileCache() (FileCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return FileCache{}, err
+	}
+	return FileCache{Dir: filepath.Join(dir, "kp", "builders")}, nil
+}
+
+func (c FileCache) Load(kind, namespace, name string) (BuilderInfo, error) {
+	buf, err := ioutil.ReadFile(c.path(kind, namespace, name))
+	if os.IsNotExist(err) {
+		return BuilderInfo{}, errors.Errorf("no cached metadata for %s %q, run without --offline to fetch it", kind, name)
+	} else if err != nil {
+		return BuilderInfo{}, err
+	}
+
+	var info BuilderInfo
+	if err := json.Unmarshal(buf, &info); err != nil {
+		return BuilderInfo{}, errors.Wrapf(err, "invalid cached metadata for %s %q", kind, name)
+	}
+	return info, nil
+}
+
+func (c FileCache) Save(info BuilderInfo) error {
+	buf, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.path(info.Kind, info.Namespace, info.Name), buf, 0644)
+}
+
+func (c FileCache) path(kind, namespace, name string) string {
+	if namespace == "" {
+		return filepath.Join(c.Dir, fmt.Sprintf("%s_%s.json", kind, name))
+	}
+	return filepath.Join(c.Dir, fmt.Sprintf("%s_%s_%s.json", kind, namespace, name))
+}
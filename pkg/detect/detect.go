@@ -0,0 +1,190 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+)
+
+type Result string
+
+const (
+	Pass    Result = "pass"
+	Fail    Result = "fail"
+	Unknown Result = "unknown"
+)
+
+// heuristic approximates the detect phase of the buildpacks whose id contains
+// one of the tokens: detection passes when one of the files exists at the
+// root of the source code.
+type heuristic struct {
+	tokens []string
+	files  []string
+}
+
+var heuristics = []heuristic{
+	{tokens: []string{"java", "maven", "gradle", "jvm"}, files: []string{"pom.xml", "build.gradle", "build.gradle.kts", "mvnw", "gradlew", "*.jar", "*.war"}},
+	{tokens: []string{"nodejs", "node", "npm", "yarn"}, files: []string{"package.json"}},
+	{tokens: []string{"go", "golang"}, files: []string{"go.mod", "Gopkg.toml", "*.go"}},
+	{tokens: []string{"python", "pip", "pipenv", "conda"}, files: []string{"requirements.txt", "setup.py", "Pipfile", "pyproject.toml", "environment.yml"}},
+	{tokens: []string{"ruby", "bundler"}, files: []string{"Gemfile"}},
+	{tokens: []string{"dotnet"}, files: []string{"*.csproj", "*.fsproj", "*.vbproj", "*.sln", "runtimeconfig.json", "*.runtimeconfig.json"}},
+	{tokens: []string{"php", "composer"}, files: []string{"composer.json", "*.php"}},
+	{tokens: []string{"rust", "cargo"}, files: []string{"Cargo.toml"}},
+	{tokens: []string{"nginx"}, files: []string{"nginx.conf"}},
+	{tokens: []string{"httpd"}, files: []string{"httpd.conf"}},
+	{tokens: []string{"procfile"}, files: []string{"Procfile"}},
+}
+
+type BuildpackResult struct {
+	Id       string
+	Version  string
+	Optional bool
+	Result   Result
+	// Evidence is the file that made detection pass.
+	Evidence string
+}
+
+type GroupResult struct {
+	Buildpacks []BuildpackResult
+	Result     Result
+}
+
+type OrderResult struct {
+	Groups []GroupResult
+	// Match is the index of the first group likely to pass detection, or -1.
+	Match int
+}
+
+// Detect evaluates every group of the builder order against the source code
+// at path, the same way the lifecycle selects the first group that passes.
+// Buildpack versions missing from the order are taken from the builder metadata.
+func Detect(path string, order []v1alpha1.OrderEntry, metadata v1alpha1.BuildpackMetadataList) (OrderResult, error) {
+	files, err := sourceFiles(path)
+	if err != nil {
+		return OrderResult{}, err
+	}
+
+	result := OrderResult{Match: -1}
+	for i, entry := range order {
+		group := detectGroup(entry, metadata, files)
+		result.Groups = append(result.Groups, group)
+
+		if result.Match == -1 && group.Result == Pass {
+			result.Match = i
+		}
+	}
+	return result, nil
+}
+
+func detectGroup(entry v1alpha1.OrderEntry, metadata v1alpha1.BuildpackMetadataList, files []string) GroupResult {
+	group := GroupResult{Result: Pass}
+	passed := false
+
+	for _, ref := range entry.Group {
+		bp := BuildpackResult{
+			Id:       ref.Id,
+			Version:  ref.Version,
+			Optional: ref.Optional,
+		}
+		if bp.Version == "" {
+			bp.Version = metadataVersion(metadata, ref.Id)
+		}
+
+		bp.Result, bp.Evidence = detectBuildpack(ref.Id, files)
+		group.Buildpacks = append(group.Buildpacks, bp)
+
+		if bp.Result == Pass {
+			passed = true
+		}
+
+		if ref.Optional {
+			continue
+		}
+
+		switch bp.Result {
+		case Fail:
+			group.Result = Fail
+		case Unknown:
+			if group.Result == Pass {
+				group.Result = Unknown
+			}
+		}
+	}
+
+	if group.Result == Pass && !passed {
+		group.Result = Fail
+	}
+	return group
+}
+
+func detectBuildpack(id string, files []string) (Result, string) {
+	tokens := idTokens(id)
+
+	for _, h := range heuristics {
+		if !containsAny(tokens, h.tokens) {
+			continue
+		}
+
+		for _, pattern := range h.files {
+			for _, file := range files {
+				if ok, _ := filepath.Match(pattern, file); ok {
+					return Pass, file
+				}
+			}
+		}
+		return Fail, ""
+	}
+
+	return Unknown, ""
+}
+
+func sourceFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{info.Name()}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return f.Readdirnames(-1)
+}
+
+func idTokens(id string) []string {
+	return strings.FieldsFunc(strings.ToLower(id), func(r rune) bool {
+		return r == '/' || r == '.' || r == '-' || r == '_'
+	})
+}
+
+func containsAny(tokens, values []string) bool {
+	for _, t := range tokens {
+		for _, v := range values {
+			if t == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func metadataVersion(metadata v1alpha1.BuildpackMetadataList, id string) string {
+	for _, bp := range metadata {
+		if bp.Id == id {
+			return bp.Version
+		}
+	}
+	return ""
+}
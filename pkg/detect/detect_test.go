@@ -0,0 +1,133 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package detect_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pivotal/build-service-cli/pkg/detect"
+)
+
+func TestDetect(t *testing.T) {
+	spec.Run(t, "TestDetect", testDetect)
+}
+
+func testDetect(t *testing.T, when spec.G, it spec.S) {
+	var dir string
+
+	it.Before(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "detect-test")
+		require.NoError(t, err)
+	})
+
+	it.After(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	ref := func(id string, optional bool) v1alpha1.BuildpackRef {
+		return v1alpha1.BuildpackRef{BuildpackInfo: v1alpha1.BuildpackInfo{Id: id}, Optional: optional}
+	}
+
+	order := []v1alpha1.OrderEntry{
+		{Group: []v1alpha1.BuildpackRef{ref("paketo-buildpacks/java", false), ref("paketo-buildpacks/procfile", true)}},
+		{Group: []v1alpha1.BuildpackRef{ref("paketo-buildpacks/nodejs", false), ref("paketo-buildpacks/procfile", true)}},
+		{Group: []v1alpha1.BuildpackRef{ref("paketo-buildpacks/go", false)}},
+	}
+
+	metadata := v1alpha1.BuildpackMetadataList{
+		{Id: "paketo-buildpacks/nodejs", Version: "0.0.5"},
+	}
+
+	writeFile := func(name string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte{}, 0644))
+	}
+
+	it("selects the first group whose required buildpacks pass", func() {
+		writeFile("package.json")
+		writeFile("main.go")
+
+		result, err := detect.Detect(dir, order, metadata)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Match)
+
+		require.Equal(t, detect.Fail, result.Groups[0].Result)
+		require.Equal(t, detect.Pass, result.Groups[1].Result)
+		require.Equal(t, detect.Pass, result.Groups[2].Result)
+
+		require.Equal(t, detect.BuildpackResult{
+			Id:       "paketo-buildpacks/nodejs",
+			Version:  "0.0.5",
+			Result:   detect.Pass,
+			Evidence: "package.json",
+		}, result.Groups[1].Buildpacks[0])
+	})
+
+	it("matches file patterns", func() {
+		writeFile("app.jar")
+
+		result, err := detect.Detect(dir, order, metadata)
+		require.NoError(t, err)
+		require.Equal(t, 0, result.Match)
+		require.Equal(t, "app.jar", result.Groups[0].Buildpacks[0].Evidence)
+	})
+
+	it("reports unknown buildpacks", func() {
+		writeFile("package.json")
+
+		result, err := detect.Detect(dir, []v1alpha1.OrderEntry{
+			{Group: []v1alpha1.BuildpackRef{ref("some-org/some-buildpack", false)}},
+		}, nil)
+		require.NoError(t, err)
+		require.Equal(t, -1, result.Match)
+		require.Equal(t, detect.Unknown, result.Groups[0].Result)
+	})
+
+	it("does not match groups with only optional buildpacks that fail", func() {
+		result, err := detect.Detect(dir, []v1alpha1.OrderEntry{
+			{Group: []v1alpha1.BuildpackRef{ref("paketo-buildpacks/procfile", true)}},
+		}, nil)
+		require.NoError(t, err)
+		require.Equal(t, -1, result.Match)
+		require.Equal(t, detect.Fail, result.Groups[0].Result)
+	})
+
+	it("returns an error when the path does not exist", func() {
+		_, err := detect.Detect(filepath.Join(dir, "missing"), order, metadata)
+		require.Error(t, err)
+	})
+
+	when("FileCache", func() {
+		it("saves and loads builder info", func() {
+			cache := detect.FileCache{Dir: filepath.Join(dir, "cache")}
+			info := detect.BuilderInfo{
+				Kind:       v1alpha1.BuilderKind,
+				Namespace:  "some-namespace",
+				Name:       "some-builder",
+				Order:      order,
+				Buildpacks: metadata,
+			}
+
+			require.NoError(t, cache.Save(info))
+
+			loaded, err := cache.Load(v1alpha1.BuilderKind, "some-namespace", "some-builder")
+			require.NoError(t, err)
+			require.Equal(t, info, loaded)
+		})
+
+		it("returns an error when nothing is cached", func() {
+			cache := detect.FileCache{Dir: filepath.Join(dir, "cache")}
+
+			_, err := cache.Load(v1alpha1.ClusterBuilderKind, "", "default")
+			require.EqualError(t, err, `no cached metadata for ClusterBuilder "default", run without --offline to fetch it`)
+		})
+	})
+}
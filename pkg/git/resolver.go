@@ -0,0 +1,255 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	uploadPackService       = "git-upload-pack"
+	uploadPackAdvertisement = "application/x-git-upload-pack-advertisement"
+	peeledSuffix            = "^{}"
+)
+
+var (
+	ErrUnsupportedURL         = errors.New("only http and https git urls can be validated")
+	ErrAuthenticationRequired = errors.New("authentication required")
+
+	// git accepts commits abbreviated to at least 4 hex characters
+	commitPattern = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+)
+
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// RemoteResolver resolves git revisions against a remote repository using
+// the smart http protocol, the same exchange used by "git ls-remote".
+type RemoteResolver struct {
+	Client *http.Client
+}
+
+func (r RemoteResolver) Resolve(url, revision string, auth *BasicAuth) (string, error) {
+	if !IsHTTP(url) {
+		return "", ErrUnsupportedURL
+	}
+
+	refs, err := r.listRefs(url, auth)
+	if err != nil {
+		return "", err
+	}
+
+	return resolveRevision(refs, revision)
+}
+
+func (r RemoteResolver) listRefs(url string, auth *BasicAuth) (map[string]string, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(url, "/")+"/info/refs?service="+uploadPackService, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if auth != nil {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "git repository %q is not reachable", url)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrAuthenticationRequired
+	case http.StatusNotFound:
+		return nil, errors.Errorf("git repository %q not found", url)
+	default:
+		return nil, errors.Errorf("git repository %q returned unexpected status %d", url, resp.StatusCode)
+	}
+
+	if resp.Header.Get("Content-Type") != uploadPackAdvertisement {
+		return nil, errors.Errorf("%q is not a git repository", url)
+	}
+
+	return parseAdvertisement(resp.Body)
+}
+
+func (r RemoteResolver) client() *http.Client {
+	if r.Client != nil {
+		return r.Client
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// parseAdvertisement reads the pkt-line encoded ref advertisement and returns
+// a map of ref name to commit. Annotated tags are recorded with the commit
+// they point to.
+func parseAdvertisement(reader io.Reader) (map[string]string, error) {
+	r := bufio.NewReader(reader)
+	refs := map[string]string{}
+
+	for {
+		line, flush, err := readPktLine(r)
+		if err == io.EOF {
+			return refs, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if flush || strings.HasPrefix(line, "# service=") {
+			continue
+		}
+
+		if idx := strings.IndexByte(line, 0); idx != -1 {
+			line = line[:idx]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		sha, ref := fields[0], fields[1]
+		if strings.HasSuffix(ref, peeledSuffix) {
+			refs[strings.TrimSuffix(ref, peeledSuffix)] = sha
+		} else if _, ok := refs[ref]; !ok {
+			refs[ref] = sha
+		}
+	}
+}
+
+func readPktLine(r *bufio.Reader) (string, bool, error) {
+	lenHex := make([]byte, 4)
+	if _, err := io.ReadFull(r, lenHex); err != nil {
+		return "", false, err
+	}
+
+	length, err := strconv.ParseUint(string(lenHex), 16, 16)
+	if err != nil {
+		return "", false, errors.Errorf("invalid git pkt-line length %q", lenHex)
+	}
+
+	if length == 0 {
+		return "", true, nil
+	}
+
+	if length < 4 {
+		return "", false, errors.Errorf("invalid git pkt-line length %d", length)
+	}
+
+	payload := make([]byte, length-4)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return "", false, err
+	}
+
+	return strings.TrimSuffix(string(payload), "\n"), false, nil
+}
+
+func resolveRevision(refs map[string]string, revision string) (string, error) {
+	for _, ref := range []string{revision, "refs/heads/" + revision, "refs/tags/" + revision} {
+		if sha, ok := refs[ref]; ok {
+			return sha, nil
+		}
+	}
+
+	// commits are not advertised unless a ref points to them, so a full or
+	// abbreviated commit is expanded when possible and otherwise accepted as is
+	if commitPattern.MatchString(revision) {
+		return expandCommit(refs, strings.ToLower(revision)), nil
+	}
+
+	return "", errors.Errorf("git revision %q not found", revision)
+}
+
+func expandCommit(refs map[string]string, revision string) string {
+	match := ""
+	for _, sha := range refs {
+		if !strings.HasPrefix(sha, revision) {
+			continue
+		}
+		if match != "" && match != sha {
+			return revision
+		}
+		match = sha
+	}
+
+	if match == "" {
+		return revision
+	}
+	return match
+}
+
+func IsHTTP(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// Host returns the host of a git url in either url or scp-like syntax.
+func Host(url string) string {
+	if idx := strings.Index(url, "://"); idx != -1 {
+		url = url[idx+3:]
+	} else if idx := strings.Index(url, ":"); idx != -1 {
+		url = url[:idx]
+	}
+
+	if idx := strings.Index(url, "/"); idx != -1 {
+		url = url[:idx]
+	}
+
+	if idx := strings.LastIndex(url, "@"); idx != -1 {
+		url = url[idx+1:]
+	}
+
+	if idx := strings.Index(url, ":"); idx != -1 {
+		url = url[:idx]
+	}
+
+	return strings.ToLower(url)
+}
+
+// CompareURL returns a web page comparing two commits of a repository hosted
+// on github.com or gitlab.com. An empty string is returned for other hosts.
+func CompareURL(url, from, to string) string {
+	repo := repositoryPath(url)
+	if repo == "" {
+		return ""
+	}
+
+	switch host := Host(url); host {
+	case "github.com":
+		return fmt.Sprintf("https://%s/%s/compare/%s...%s", host, repo, from, to)
+	case "gitlab.com":
+		return fmt.Sprintf("https://%s/%s/-/compare/%s...%s", host, repo, from, to)
+	default:
+		return ""
+	}
+}
+
+// repositoryPath returns the path of a git url in either url or scp-like
+// syntax, without the .git suffix.
+func repositoryPath(url string) string {
+	if idx := strings.Index(url, "://"); idx != -1 {
+		url = url[idx+3:]
+		if idx := strings.Index(url, "/"); idx != -1 {
+			url = url[idx+1:]
+		} else {
+			url = ""
+		}
+	} else if idx := strings.Index(url, ":"); idx != -1 {
+		url = url[idx+1:]
+	}
+
+	return strings.TrimSuffix(strings.Trim(url, "/"), ".git")
+}
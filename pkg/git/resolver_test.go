@@ -0,0 +1,162 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package git_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pivotal/build-service-cli/pkg/git"
+)
+
+func TestRemoteResolver(t *testing.T) {
+	spec.Run(t, "TestRemoteResolver", testRemoteResolver)
+}
+
+func testRemoteResolver(t *testing.T, when spec.G, it spec.S) {
+	const (
+		mainSha   = "1111111111111111111111111111111111111111"
+		tagSha    = "2222222222222222222222222222222222222222"
+		commitSha = "3333333333333333333333333333333333333333"
+	)
+
+	var (
+		server        *httptest.Server
+		requireAuth   bool
+		authRequested bool
+	)
+
+	pktLine := func(line string) string {
+		return fmt.Sprintf("%04x%s", len(line)+4, line)
+	}
+
+	it.Before(func() {
+		requireAuth = false
+		authRequested = false
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/some/repo.git/info/refs" || r.URL.Query().Get("service") != "git-upload-pack" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			username, password, ok := r.BasicAuth()
+			authRequested = ok
+			if requireAuth && (username != "some-user" || password != "some-password") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+			_, _ = fmt.Fprint(w, strings.Join([]string{
+				pktLine("# service=git-upload-pack\n"),
+				"0000",
+				pktLine(mainSha + " HEAD\x00multi_ack side-band-64k\n"),
+				pktLine(mainSha + " refs/heads/main\n"),
+				pktLine("4444444444444444444444444444444444444444 refs/tags/v1.0.0\n"),
+				pktLine(tagSha + " refs/tags/v1.0.0^{}\n"),
+				"0000",
+			}, ""))
+		}))
+	})
+
+	it.After(func() {
+		server.Close()
+	})
+
+	resolver := git.RemoteResolver{}
+
+	it("resolves branches", func() {
+		sha, err := resolver.Resolve(server.URL+"/some/repo.git", "main", nil)
+		require.NoError(t, err)
+		require.Equal(t, mainSha, sha)
+	})
+
+	it("resolves full ref names", func() {
+		sha, err := resolver.Resolve(server.URL+"/some/repo.git", "refs/heads/main", nil)
+		require.NoError(t, err)
+		require.Equal(t, mainSha, sha)
+	})
+
+	it("resolves annotated tags to the tagged commit", func() {
+		sha, err := resolver.Resolve(server.URL+"/some/repo.git", "v1.0.0", nil)
+		require.NoError(t, err)
+		require.Equal(t, tagSha, sha)
+	})
+
+	it("accepts commit shas", func() {
+		sha, err := resolver.Resolve(server.URL+"/some/repo.git", commitSha, nil)
+		require.NoError(t, err)
+		require.Equal(t, commitSha, sha)
+	})
+
+	it("accepts abbreviated commit shas", func() {
+		sha, err := resolver.Resolve(server.URL+"/some/repo.git", commitSha[:7], nil)
+		require.NoError(t, err)
+		require.Equal(t, commitSha[:7], sha)
+	})
+
+	it("expands abbreviated commit shas of advertised refs", func() {
+		sha, err := resolver.Resolve(server.URL+"/some/repo.git", tagSha[:8], nil)
+		require.NoError(t, err)
+		require.Equal(t, tagSha, sha)
+	})
+
+	it("errors when the revision does not exist", func() {
+		_, err := resolver.Resolve(server.URL+"/some/repo.git", "some-branch", nil)
+		require.EqualError(t, err, `git revision "some-branch" not found`)
+	})
+
+	it("errors when the repository does not exist", func() {
+		_, err := resolver.Resolve(server.URL+"/other/repo.git", "main", nil)
+		require.EqualError(t, err, fmt.Sprintf("git repository %q not found", server.URL+"/other/repo.git"))
+	})
+
+	it("sends credentials when provided", func() {
+		requireAuth = true
+
+		_, err := resolver.Resolve(server.URL+"/some/repo.git", "main", nil)
+		require.Equal(t, git.ErrAuthenticationRequired, err)
+
+		sha, err := resolver.Resolve(server.URL+"/some/repo.git", "main", &git.BasicAuth{Username: "some-user", Password: "some-password"})
+		require.NoError(t, err)
+		require.Equal(t, mainSha, sha)
+		require.True(t, authRequested)
+	})
+
+	it("does not resolve non http urls", func() {
+		_, err := resolver.Resolve("git@github.com:some/repo.git", "main", nil)
+		require.Equal(t, git.ErrUnsupportedURL, err)
+	})
+}
+
+func TestHost(t *testing.T) {
+	for url, host := range map[string]string{
+		"https://github.com/some/repo.git":          "github.com",
+		"https://user@GitHub.com:443/some/repo.git": "github.com",
+		"git@github.com:some/repo.git":              "github.com",
+		"ssh://git@gitlab.example.com:22/some/repo": "gitlab.example.com",
+		"https://bitbucket.org":                     "bitbucket.org",
+	} {
+		require.Equal(t, host, git.Host(url), url)
+	}
+}
+
+func TestCompareURL(t *testing.T) {
+	for url, compareURL := range map[string]string{
+		"https://github.com/some/repo.git":          "https://github.com/some/repo/compare/old...new",
+		"git@github.com:some/repo.git":              "https://github.com/some/repo/compare/old...new",
+		"https://gitlab.com/some/group/repo/":       "https://gitlab.com/some/group/repo/-/compare/old...new",
+		"ssh://git@gitlab.example.com:22/some/repo": "",
+		"https://github.com":                        "",
+	} {
+		require.Equal(t, compareURL, git.CompareURL(url, "old", "new"), url)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+)
+
+const ImageKind = "Image"
+
+// kindOrder sorts nodes from the resources images depend on to the images.
+var kindOrder = map[string]int{
+	v1alpha1.ClusterStackKind:   0,
+	v1alpha1.ClusterStoreKind:   1,
+	v1alpha1.ClusterBuilderKind: 2,
+	v1alpha1.BuilderKind:        3,
+	ImageKind:                   4,
+}
+
+type Node struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Missing is set for resources that are referenced but do not exist.
+	Missing bool
+
+	Dependents []*Node
+	parents    int
+}
+
+func (n *Node) ID() string {
+	return nodeID(n.Kind, n.Namespace, n.Name)
+}
+
+// AffectedImages counts the images that are rebuilt when the node changes.
+func (n *Node) AffectedImages() int {
+	images := map[string]bool{}
+	n.walk(func(node *Node) {
+		if node.Kind == ImageKind {
+			images[node.ID()] = true
+		}
+	})
+	return len(images)
+}
+
+func (n *Node) walk(fn func(*Node)) {
+	fn(n)
+	for _, d := range n.Dependents {
+		d.walk(fn)
+	}
+}
+
+// Graph is the dependency graph from cluster stacks and cluster stores,
+// through builders and cluster builders, to images.
+type Graph struct {
+	nodes map[string]*Node
+}
+
+func New(stacks []v1alpha1.ClusterStack, stores []v1alpha1.ClusterStore, clusterBuilders []v1alpha1.ClusterBuilder, builders []v1alpha1.Builder, images []v1alpha1.Image) *Graph {
+	g := &Graph{nodes: map[string]*Node{}}
+
+	for _, s := range stacks {
+		g.add(v1alpha1.ClusterStackKind, "", s.Name)
+	}
+
+	for _, s := range stores {
+		g.add(v1alpha1.ClusterStoreKind, "", s.Name)
+	}
+
+	for _, cb := range clusterBuilders {
+		g.addBuilder(g.add(v1alpha1.ClusterBuilderKind, "", cb.Name), cb.Spec.BuilderSpec)
+	}
+
+	for _, b := range builders {
+		g.addBuilder(g.add(v1alpha1.BuilderKind, b.Namespace, b.Name), b.Spec.BuilderSpec)
+	}
+
+	for _, img := range images {
+		node := g.add(ImageKind, img.Namespace, img.Name)
+
+		switch img.Spec.Builder.Kind {
+		case v1alpha1.ClusterBuilderKind:
+			g.link(g.reference(v1alpha1.ClusterBuilderKind, "", img.Spec.Builder.Name), node)
+		case v1alpha1.BuilderKind:
+			namespace := img.Spec.Builder.Namespace
+			if namespace == "" {
+				namespace = img.Namespace
+			}
+			g.link(g.reference(v1alpha1.BuilderKind, namespace, img.Spec.Builder.Name), node)
+		}
+	}
+
+	for _, n := range g.nodes {
+		sort.Slice(n.Dependents, less(n.Dependents))
+	}
+
+	return g
+}
+
+// Find returns the node for a resource, or nil when it is not in the graph.
+func (g *Graph) Find(kind, namespace, name string) *Node {
+	return g.nodes[nodeID(kind, namespace, name)]
+}
+
+// Roots returns the nodes that do not depend on any other node.
+func (g *Graph) Roots() []*Node {
+	var roots []*Node
+	for _, n := range g.nodes {
+		if n.parents == 0 && n.Kind != ImageKind {
+			roots = append(roots, n)
+		}
+	}
+	sort.Slice(roots, less(roots))
+	return roots
+}
+
+// Reachable returns the roots and every node that depends on them, sorted.
+func Reachable(roots []*Node) []*Node {
+	seen := map[string]*Node{}
+	for _, r := range roots {
+		r.walk(func(n *Node) {
+			seen[n.ID()] = n
+		})
+	}
+
+	nodes := make([]*Node, 0, len(seen))
+	for _, n := range seen {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, less(nodes))
+	return nodes
+}
+
+func (g *Graph) add(kind, namespace, name string) *Node {
+	id := nodeID(kind, namespace, name)
+	if n, ok := g.nodes[id]; ok {
+		n.Missing = false
+		return n
+	}
+
+	n := &Node{Kind: kind, Namespace: namespace, Name: name}
+	g.nodes[id] = n
+	return n
+}
+
+// reference returns the node for a referenced resource, marking it missing
+// until the resource itself is added.
+func (g *Graph) reference(kind, namespace, name string) *Node {
+	id := nodeID(kind, namespace, name)
+	if n, ok := g.nodes[id]; ok {
+		return n
+	}
+
+	n := &Node{Kind: kind, Namespace: namespace, Name: name, Missing: true}
+	g.nodes[id] = n
+	return n
+}
+
+func (g *Graph) addBuilder(node *Node, spec v1alpha1.BuilderSpec) {
+	if spec.Stack.Name != "" {
+		g.link(g.reference(v1alpha1.ClusterStackKind, "", spec.Stack.Name), node)
+	}
+
+	if spec.Store.Name != "" {
+		g.link(g.reference(v1alpha1.ClusterStoreKind, "", spec.Store.Name), node)
+	}
+}
+
+func (g *Graph) link(parent, child *Node) {
+	parent.Dependents = append(parent.Dependents, child)
+	child.parents++
+}
+
+func nodeID(kind, namespace, name string) string {
+	if namespace == "" {
+		return fmt.Sprintf("%s/%s", kind, name)
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+func less(nodes []*Node) func(i, j int) bool {
+	return func(i, j int) bool {
+		if nodes[i].Kind != nodes[j].Kind {
+			return kindOrder[nodes[i].Kind] < kindOrder[nodes[j].Kind]
+		}
+		return nodes[i].ID() < nodes[j].ID()
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/graph"
+)
+
+func TestGraph(t *testing.T) {
+	spec.Run(t, "TestGraph", testGraph)
+}
+
+func testGraph(t *testing.T, when spec.G, it spec.S) {
+	builderSpec := v1alpha1.BuilderSpec{
+		Stack: corev1.ObjectReference{Kind: v1alpha1.ClusterStackKind, Name: "some-stack"},
+		Store: corev1.ObjectReference{Kind: v1alpha1.ClusterStoreKind, Name: "some-store"},
+	}
+
+	clusterBuilders := []v1alpha1.ClusterBuilder{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-cluster-builder"},
+			Spec:       v1alpha1.ClusterBuilderSpec{BuilderSpec: builderSpec},
+		},
+	}
+
+	builders := []v1alpha1.Builder{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-builder", Namespace: "some-namespace"},
+			Spec:       v1alpha1.NamespacedBuilderSpec{BuilderSpec: builderSpec},
+		},
+	}
+
+	images := []v1alpha1.Image{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-image", Namespace: "some-namespace"},
+			Spec: v1alpha1.ImageSpec{
+				Builder: corev1.ObjectReference{Kind: v1alpha1.ClusterBuilderKind, Name: "some-cluster-builder"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-image", Namespace: "some-namespace"},
+			Spec: v1alpha1.ImageSpec{
+				Builder: corev1.ObjectReference{Kind: v1alpha1.BuilderKind, Name: "some-builder"},
+			},
+		},
+	}
+
+	stores := []v1alpha1.ClusterStore{{ObjectMeta: metav1.ObjectMeta{Name: "some-store"}}}
+
+	it("links stacks and stores through builders to images", func() {
+		g := graph.New(nil, stores, clusterBuilders, builders, images)
+
+		store := g.Find(v1alpha1.ClusterStoreKind, "", "some-store")
+		require.NotNil(t, store)
+		require.False(t, store.Missing)
+		require.Equal(t, 2, store.AffectedImages())
+
+		builder := g.Find(v1alpha1.BuilderKind, "some-namespace", "some-builder")
+		require.NotNil(t, builder)
+		require.Equal(t, 1, builder.AffectedImages())
+		require.Equal(t, "Image/some-namespace/other-image", builder.Dependents[0].ID())
+	})
+
+	it("marks referenced resources that do not exist as missing", func() {
+		g := graph.New(nil, stores, clusterBuilders, builders, images)
+
+		stack := g.Find(v1alpha1.ClusterStackKind, "", "some-stack")
+		require.NotNil(t, stack)
+		require.True(t, stack.Missing)
+
+		var roots []string
+		for _, n := range g.Roots() {
+			roots = append(roots, n.ID())
+		}
+		require.Equal(t, []string{"ClusterStack/some-stack", "ClusterStore/some-store"}, roots)
+	})
+
+	it("returns every node reachable from the roots", func() {
+		g := graph.New(nil, stores, clusterBuilders, builders, images)
+
+		var reachable []string
+		for _, n := range graph.Reachable([]*graph.Node{g.Find(v1alpha1.ClusterBuilderKind, "", "some-cluster-builder")}) {
+			reachable = append(reachable, n.ID())
+		}
+		require.Equal(t, []string{"ClusterBuilder/some-cluster-builder", "Image/some-namespace/some-image"}, reachable)
+	})
+}
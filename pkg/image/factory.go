@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -26,6 +27,10 @@ type SourceUploader interface {
 	Upload(ref, path string, writer io.Writer, tlsCfg registry.TLSConfig) (string, error)
 }
 
+type Fetcher interface {
+	Fetch(src string, tlsCfg registry.TLSConfig) (ggcrv1.Image, error)
+}
+
 type Printer interface {
 	Printlnf(format string, args ...interface{}) error
 	PrintStatus(format string, args ...interface{}) error
@@ -38,6 +43,7 @@ type Factory struct {
 	GitRevision    string
 	Blob           string
 	LocalPath      string
+	SourceImage    string
 	SubPath        *string
 	Builder        string
 	ClusterBuilder string
@@ -46,6 +52,11 @@ type Factory struct {
 	DeleteEnv      []string
 	TLSConfig      registry.TLSConfig
 	Printer        Printer
+	GitValidator   GitValidator
+	Fetcher        Fetcher
+
+	SkipSourceValidation bool
+	PinRevision          bool
 }
 
 func (f *Factory) MakeImage(name, namespace, tag string) (*v1alpha1.Image, error) {
@@ -59,6 +70,11 @@ func (f *Factory) MakeImage(name, namespace, tag string) (*v1alpha1.Image, error
 		return nil, err
 	}
 
+	pinnedRef, err := f.prepareGitSource(namespace, defaultServiceAccount, source.Git)
+	if err != nil {
+		return nil, err
+	}
+
 	envVars, err := f.makeEnvVars()
 	if err != nil {
 		return nil, err
@@ -71,19 +87,25 @@ func (f *Factory) MakeImage(name, namespace, tag string) (*v1alpha1.Image, error
 
 	builder := f.makeBuilder(namespace)
 
+	var annotations map[string]string
+	if pinnedRef != "" {
+		annotations = map[string]string{PinnedRevisionAnnotation: pinnedRef}
+	}
+
 	return &v1alpha1.Image{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Image",
 			APIVersion: v1alpha1.SchemeGroupVersion.String(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: annotations,
 		},
 		Spec: v1alpha1.ImageSpec{
 			Tag:            tag,
 			Builder:        builder,
-			ServiceAccount: "default",
+			ServiceAccount: defaultServiceAccount,
 			Source:         source,
 			Build: &v1alpha1.ImageBuild{
 				Env: envVars,
@@ -98,9 +120,10 @@ func (f *Factory) validateCreate() error {
 	sourceSet.add("git", f.GitRepo)
 	sourceSet.add("blob", f.Blob)
 	sourceSet.add("local-path", f.LocalPath)
+	sourceSet.add("source-image", f.SourceImage)
 
 	if len(sourceSet) != 1 {
-		return errors.New("image source must be one of git, blob, or local-path")
+		return errors.New("image source must be one of git, blob, local-path, or source-image")
 	}
 
 	builderSet := paramSet{}
@@ -174,6 +197,17 @@ func (f *Factory) makeSource(tag string) (v1alpha1.SourceConfig, error) {
 			},
 			SubPath: subPath,
 		}, nil
+	} else if f.SourceImage != "" {
+		if err := f.validateSourceImage(f.SourceImage); err != nil {
+			return v1alpha1.SourceConfig{}, err
+		}
+
+		return v1alpha1.SourceConfig{
+			Registry: &v1alpha1.Registry{
+				Image: f.SourceImage,
+			},
+			SubPath: subPath,
+		}, nil
 	} else {
 		ref, err := name.ParseReference(tag)
 		if err != nil {
@@ -199,6 +233,24 @@ func (f *Factory) makeSource(tag string) (v1alpha1.SourceConfig, error) {
 	}
 }
 
+// validateSourceImage checks that a published source image exists and can
+// be read from the registry with the configured TLS options.
+func (f *Factory) validateSourceImage(ref string) error {
+	if _, err := name.ParseReference(ref, name.WeakValidation); err != nil {
+		return errors.Wrapf(err, "invalid source image %q", ref)
+	}
+
+	if f.SkipSourceValidation || f.Fetcher == nil {
+		return nil
+	}
+
+	if _, err := f.Fetcher.Fetch(ref, f.TLSConfig); err != nil {
+		return errors.Wrapf(err, "unable to read source image %q", ref)
+	}
+
+	return nil
+}
+
 func (f *Factory) makeBuilder(namespace string) corev1.ObjectReference {
 	if f.Builder != "" {
 		return corev1.ObjectReference{
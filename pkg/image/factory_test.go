@@ -40,10 +40,54 @@ func testImageFactory(t *testing.T, when spec.G, it spec.S) {
 		require.Equal(t, "master", img.Spec.Source.Git.Revision)
 	})
 
+	when("a source image is provided", func() {
+		const sourceImage = "some-registry.io/some-repo-source@sha256:1f3c4d8e2b7a6f5e9d0c1b2a3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e"
+
+		it("uses the source image as a registry source", func() {
+			fetcher := &fakes.Fetcher{}
+			fetcher.AddImage(sourceImage, fakes.NewFakeLabeledImage("some-label", "some-value", "some-digest"))
+			factory.Fetcher = fetcher
+			factory.SourceImage = sourceImage
+
+			img, err := factory.MakeImage("test-name", "test-namespace", "test-registry.io/test-image")
+			require.NoError(t, err)
+			require.Equal(t, sourceImage, img.Spec.Source.Registry.Image)
+			require.Equal(t, 1, fetcher.CallCount())
+		})
+
+		it("returns an error when the source image cannot be read", func() {
+			factory.Fetcher = &fakes.Fetcher{}
+			factory.SourceImage = sourceImage
+
+			_, err := factory.MakeImage("test-name", "test-namespace", "test-registry.io/test-image")
+			require.EqualError(t, err, `unable to read source image "`+sourceImage+`": image not found: "`+sourceImage+`"`)
+		})
+
+		it("does not read the source image when validation is skipped", func() {
+			fetcher := &fakes.Fetcher{}
+			factory.Fetcher = fetcher
+			factory.SourceImage = sourceImage
+			factory.SkipSourceValidation = true
+
+			img, err := factory.MakeImage("test-name", "test-namespace", "test-registry.io/test-image")
+			require.NoError(t, err)
+			require.Equal(t, sourceImage, img.Spec.Source.Registry.Image)
+			require.Equal(t, 0, fetcher.CallCount())
+		})
+
+		it("returns an error when the source image is not a valid reference", func() {
+			factory.SourceImage = "Invalid Ref"
+
+			_, err := factory.MakeImage("test-name", "test-namespace", "test-registry.io/test-image")
+			require.Error(t, err)
+			require.Contains(t, err.Error(), `invalid source image "Invalid Ref"`)
+		})
+	})
+
 	when("no params are set", func() {
 		it("returns an error message", func() {
 			_, err := factory.MakeImage("test-name", "test-namespace", "test-registry.io/test-image")
-			require.EqualError(t, err, "image source must be one of git, blob, or local-path")
+			require.EqualError(t, err, "image source must be one of git, blob, local-path, or source-image")
 		})
 	})
 
@@ -53,7 +97,7 @@ func testImageFactory(t *testing.T, when spec.G, it spec.S) {
 			factory.Blob = "some-blob"
 			factory.LocalPath = "some-local-path"
 			_, err := factory.MakeImage("test-name", "test-namespace", "test-registry.io/test-image")
-			require.EqualError(t, err, "image source must be one of git, blob, or local-path")
+			require.EqualError(t, err, "image source must be one of git, blob, local-path, or source-image")
 		})
 	})
 
@@ -0,0 +1,40 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fakes
+
+type GitValidateCall struct {
+	Namespace      string
+	ServiceAccount string
+	URL            string
+	Revision       string
+}
+
+type FakeGitValidator struct {
+	Calls    []GitValidateCall
+	Warnings []string
+	Err      error
+
+	ResolveCalls []GitValidateCall
+	Revisions    map[string]string
+	ResolveErr   error
+}
+
+func (f *FakeGitValidator) Validate(namespace, serviceAccount, url, revision string) ([]string, error) {
+	f.Calls = append(f.Calls, GitValidateCall{Namespace: namespace, ServiceAccount: serviceAccount, URL: url, Revision: revision})
+	return f.Warnings, f.Err
+}
+
+// ResolveRevision returns the commit configured for the revision, or the
+// revision itself when none is configured.
+func (f *FakeGitValidator) ResolveRevision(namespace, serviceAccount, url, revision string) (string, error) {
+	f.ResolveCalls = append(f.ResolveCalls, GitValidateCall{Namespace: namespace, ServiceAccount: serviceAccount, URL: url, Revision: revision})
+	if f.ResolveErr != nil {
+		return "", f.ResolveErr
+	}
+
+	if sha, ok := f.Revisions[revision]; ok {
+		return sha, nil
+	}
+	return revision, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/pivotal/build-service-cli/pkg/git"
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+const (
+	defaultServiceAccount = "default"
+
+	// PinnedRevisionAnnotation records the branch or tag that was resolved
+	// to the commit in a pinned git source.
+	PinnedRevisionAnnotation = "kp.kpack.io/pinnedRevision"
+)
+
+type GitValidator interface {
+	Validate(namespace, serviceAccount, url, revision string) (warnings []string, err error)
+	ResolveRevision(namespace, serviceAccount, url, revision string) (string, error)
+}
+
+type GitResolver interface {
+	Resolve(url, revision string, auth *git.BasicAuth) (string, error)
+}
+
+// GitSourceValidator checks that a git source is reachable and that its
+// revision resolves to a commit. Credentials are found through the secrets
+// managed by "kp secret" on the service account of the image.
+type GitSourceValidator struct {
+	Resolver  GitResolver
+	K8sClient kubernetes.Interface
+}
+
+func (v GitSourceValidator) Validate(namespace, serviceAccount, url, revision string) ([]string, error) {
+	var warnings []string
+
+	secrets, err := v.gitSecrets(namespace, serviceAccount, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(secrets) == 0 {
+		warnings = append(warnings, fmt.Sprintf("no git secret of service account %q targets %q in namespace %q, builds of private repositories will fail", serviceAccount, git.Host(url), namespace))
+	}
+
+	if !git.IsHTTP(url) {
+		return append(warnings, fmt.Sprintf("skipping validation of git url %q, only http and https urls can be validated", url)), nil
+	}
+
+	_, err = v.Resolver.Resolve(url, revision, basicAuth(secrets))
+	if err == git.ErrAuthenticationRequired {
+		return append(warnings, fmt.Sprintf("skipping validation of git url %q, authentication required", url)), nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "invalid git source")
+	}
+
+	return warnings, nil
+}
+
+// ResolveRevision resolves a branch or tag to the commit it currently
+// points to, using the same credentials as Validate.
+func (v GitSourceValidator) ResolveRevision(namespace, serviceAccount, url, revision string) (string, error) {
+	secrets, err := v.gitSecrets(namespace, serviceAccount, url)
+	if err != nil {
+		return "", err
+	}
+
+	sha, err := v.Resolver.Resolve(url, revision, basicAuth(secrets))
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to resolve git revision %q", revision)
+	}
+
+	return sha, nil
+}
+
+func (v GitSourceValidator) gitSecrets(namespace, serviceAccount, url string) ([]*corev1.Secret, error) {
+	if serviceAccount == "" {
+		serviceAccount = defaultServiceAccount
+	}
+
+	sa, err := v.K8sClient.CoreV1().ServiceAccounts(namespace).Get(serviceAccount, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	managedSecrets, err := secret.ReadManagedSecrets(sa)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []*corev1.Secret
+	for _, name := range secret.GitSecretsForURL(managedSecrets, url) {
+		s, err := v.K8sClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, s)
+	}
+	return secrets, nil
+}
+
+func basicAuth(secrets []*corev1.Secret) *git.BasicAuth {
+	for _, s := range secrets {
+		if s.Type == corev1.SecretTypeBasicAuth {
+			return &git.BasicAuth{
+				Username: string(s.Data[corev1.BasicAuthUsernameKey]),
+				Password: string(s.Data[corev1.BasicAuthPasswordKey]),
+			}
+		}
+	}
+	return nil
+}
+
+// prepareGitSource validates the git source and, when pinning, replaces its
+// revision with the resolved commit. The returned ref is the pinned branch or
+// tag, or empty when the source is not pinned.
+func (f *Factory) prepareGitSource(namespace, serviceAccount string, source *v1alpha1.Git) (string, error) {
+	if f.GitValidator == nil || source == nil {
+		return "", nil
+	}
+
+	if !f.SkipSourceValidation {
+		warnings, err := f.GitValidator.Validate(namespace, serviceAccount, source.URL, source.Revision)
+		if err != nil {
+			return "", err
+		}
+
+		for _, w := range warnings {
+			if err := f.Printer.Printlnf("Warning: %s", w); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if !f.PinRevision {
+		return "", nil
+	}
+
+	sha, err := f.GitValidator.ResolveRevision(namespace, serviceAccount, source.URL, source.Revision)
+	if err != nil {
+		return "", err
+	}
+
+	if sha == source.Revision {
+		return "", nil
+	}
+
+	ref := source.Revision
+	source.Revision = sha
+	return ref, f.Printer.Printlnf("Pinned git revision %q to %s", ref, sha)
+}
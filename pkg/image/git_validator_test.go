@@ -0,0 +1,158 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfakes "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/pivotal/build-service-cli/pkg/git"
+	"github.com/pivotal/build-service-cli/pkg/image"
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+func TestGitSourceValidator(t *testing.T) {
+	spec.Run(t, "TestGitSourceValidator", testGitSourceValidator)
+}
+
+type fakeGitResolver struct {
+	err  error
+	auth *git.BasicAuth
+}
+
+func (r *fakeGitResolver) Resolve(url, revision string, auth *git.BasicAuth) (string, error) {
+	r.auth = auth
+	return "some-sha", r.err
+}
+
+func testGitSourceValidator(t *testing.T, when spec.G, it spec.S) {
+	const namespace = "some-namespace"
+
+	var resolver *fakeGitResolver
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default",
+			Namespace: namespace,
+			Annotations: map[string]string{
+				secret.ManagedSecretAnnotationKey: `{"git-secret":"https://github.com","registry-secret":"some-registry.io"}`,
+			},
+		},
+	}
+
+	gitSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "git-secret",
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("some-user"),
+			corev1.BasicAuthPasswordKey: []byte("some-password"),
+		},
+	}
+
+	it.Before(func() {
+		resolver = &fakeGitResolver{}
+	})
+
+	it("resolves the revision with the credentials of the matching git secret", func() {
+		validator := image.GitSourceValidator{
+			Resolver:  resolver,
+			K8sClient: k8sfakes.NewSimpleClientset(serviceAccount, gitSecret),
+		}
+
+		warnings, err := validator.Validate(namespace, "default", "https://github.com/some/repo", "main")
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+		require.Equal(t, &git.BasicAuth{Username: "some-user", Password: "some-password"}, resolver.auth)
+	})
+
+	it("warns when no git secret targets the repository host", func() {
+		validator := image.GitSourceValidator{
+			Resolver:  resolver,
+			K8sClient: k8sfakes.NewSimpleClientset(serviceAccount, gitSecret),
+		}
+
+		warnings, err := validator.Validate(namespace, "default", "https://gitlab.com/some/repo", "main")
+		require.NoError(t, err)
+		require.Equal(t, []string{`no git secret of service account "default" targets "gitlab.com" in namespace "some-namespace", builds of private repositories will fail`}, warnings)
+		require.Nil(t, resolver.auth)
+	})
+
+	it("reads the git secrets of the service account of the image", func() {
+		otherServiceAccount := serviceAccount.DeepCopy()
+		otherServiceAccount.Name = "some-service-account"
+		otherServiceAccount.Annotations = nil
+
+		validator := image.GitSourceValidator{
+			Resolver:  resolver,
+			K8sClient: k8sfakes.NewSimpleClientset(serviceAccount, otherServiceAccount, gitSecret),
+		}
+
+		warnings, err := validator.Validate(namespace, "some-service-account", "https://github.com/some/repo", "main")
+		require.NoError(t, err)
+		require.Equal(t, []string{`no git secret of service account "some-service-account" targets "github.com" in namespace "some-namespace", builds of private repositories will fail`}, warnings)
+		require.Nil(t, resolver.auth)
+	})
+
+	it("warns and skips resolution for non http urls", func() {
+		validator := image.GitSourceValidator{
+			Resolver:  resolver,
+			K8sClient: k8sfakes.NewSimpleClientset(),
+		}
+
+		warnings, err := validator.Validate(namespace, "default", "git@github.com:some/repo.git", "main")
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			`no git secret of service account "default" targets "github.com" in namespace "some-namespace", builds of private repositories will fail`,
+			`skipping validation of git url "git@github.com:some/repo.git", only http and https urls can be validated`,
+		}, warnings)
+	})
+
+	it("warns when the repository requires authentication", func() {
+		resolver.err = git.ErrAuthenticationRequired
+		validator := image.GitSourceValidator{
+			Resolver:  resolver,
+			K8sClient: k8sfakes.NewSimpleClientset(serviceAccount, gitSecret),
+		}
+
+		warnings, err := validator.Validate(namespace, "default", "https://github.com/some/repo", "main")
+		require.NoError(t, err)
+		require.Equal(t, []string{`skipping validation of git url "https://github.com/some/repo", authentication required`}, warnings)
+	})
+
+	it("errors when the revision cannot be resolved", func() {
+		resolver.err = errors.New(`git revision "main" not found`)
+		validator := image.GitSourceValidator{
+			Resolver:  resolver,
+			K8sClient: k8sfakes.NewSimpleClientset(serviceAccount, gitSecret),
+		}
+
+		_, err := validator.Validate(namespace, "default", "https://github.com/some/repo", "main")
+		require.EqualError(t, err, `invalid git source: git revision "main" not found`)
+	})
+
+	it("resolves revisions with the credentials of the matching git secret", func() {
+		validator := image.GitSourceValidator{
+			Resolver:  resolver,
+			K8sClient: k8sfakes.NewSimpleClientset(serviceAccount, gitSecret),
+		}
+
+		sha, err := validator.ResolveRevision(namespace, "default", "https://github.com/some/repo", "main")
+		require.NoError(t, err)
+		require.Equal(t, "some-sha", sha)
+		require.Equal(t, &git.BasicAuth{Username: "some-user", Password: "some-password"}, resolver.auth)
+
+		resolver.err = git.ErrAuthenticationRequired
+		_, err = validator.ResolveRevision(namespace, "default", "https://github.com/some/repo", "main")
+		require.EqualError(t, err, `unable to resolve git revision "main": authentication required`)
+	})
+}
@@ -51,13 +51,14 @@ func (f *Factory) validatePatch(img *v1alpha1.Image) error {
 	sourceSet.add("git", f.GitRepo)
 	sourceSet.add("blob", f.Blob)
 	sourceSet.add("local-path", f.LocalPath)
+	sourceSet.add("source-image", f.SourceImage)
 
 	if len(sourceSet) > 1 {
-		return errors.New("image source must be one of git, blob, or local-path")
+		return errors.New("image source must be one of git, blob, local-path, or source-image")
 	}
 
-	if (sourceSet.contains("blob") || sourceSet.contains("local-path")) && f.GitRevision != "" {
-		return errors.New("git-revision is incompatible with blob and local path image sources")
+	if (sourceSet.contains("blob") || sourceSet.contains("local-path") || sourceSet.contains("source-image")) && f.GitRevision != "" {
+		return errors.New("git-revision is incompatible with blob, local path and source image sources")
 	}
 
 	if len(sourceSet) == 0 && img.Spec.Source.Git == nil && f.GitRevision != "" {
@@ -113,7 +114,7 @@ func (f *Factory) setSource(image *v1alpha1.Image) error {
 		image.Spec.Source.SubPath = *f.SubPath
 	}
 
-	if f.GitRepo != "" || f.GitRevision != "" {
+	if f.GitRepo != "" || f.GitRevision != "" || f.shouldPinExistingRevision(image) {
 		if f.GitRepo != "" {
 			image.Spec.Source.Blob = nil
 			image.Spec.Source.Registry = nil
@@ -126,7 +127,15 @@ func (f *Factory) setSource(image *v1alpha1.Image) error {
 		if f.GitRevision != "" {
 			image.Spec.Source.Git.Revision = f.GitRevision
 		}
+
+		pinnedRef, err := f.prepareGitSource(image.Namespace, image.Spec.ServiceAccount, image.Spec.Source.Git)
+		if err != nil {
+			return err
+		}
+
+		setPinnedRevision(image, pinnedRef)
 	} else if f.Blob != "" {
+		setPinnedRevision(image, "")
 		image.Spec.Source.Git = nil
 		image.Spec.Source.Registry = nil
 		image.Spec.Source.Blob = &v1alpha1.Blob{URL: f.Blob}
@@ -141,14 +150,45 @@ func (f *Factory) setSource(image *v1alpha1.Image) error {
 			return err
 		}
 
+		setPinnedRevision(image, "")
 		image.Spec.Source.Git = nil
 		image.Spec.Source.Blob = nil
 		image.Spec.Source.Registry = &v1alpha1.Registry{Image: sourceRef}
+	} else if f.SourceImage != "" {
+		if err := f.validateSourceImage(f.SourceImage); err != nil {
+			return err
+		}
+
+		setPinnedRevision(image, "")
+		image.Spec.Source.Git = nil
+		image.Spec.Source.Blob = nil
+		if image.Spec.Source.Registry == nil {
+			image.Spec.Source.Registry = &v1alpha1.Registry{}
+		}
+		image.Spec.Source.Registry.Image = f.SourceImage
 	}
 
 	return nil
 }
 
+// shouldPinExistingRevision reports whether "--pin-revision" was provided on
+// its own for a git source that is not yet pinned.
+func (f *Factory) shouldPinExistingRevision(image *v1alpha1.Image) bool {
+	return f.PinRevision && image.Spec.Source.Git != nil && image.Annotations[PinnedRevisionAnnotation] == ""
+}
+
+func setPinnedRevision(image *v1alpha1.Image, ref string) {
+	if ref == "" {
+		delete(image.Annotations, PinnedRevisionAnnotation)
+		return
+	}
+
+	if image.Annotations == nil {
+		image.Annotations = map[string]string{}
+	}
+	image.Annotations[PinnedRevisionAnnotation] = ref
+}
+
 func (f *Factory) setCacheSize(image *v1alpha1.Image) error {
 	if f.CacheSize == "" {
 		return nil
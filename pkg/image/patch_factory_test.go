@@ -62,13 +62,27 @@ func testPatchFactory(t *testing.T, when spec.G, it spec.S) {
 		require.Equal(t, `{"spec":{"source":{"blob":null,"git":{"revision":"master","url":"some-repo"}}}}`, string(patch))
 	})
 
+	it("replaces the source with a source image", func() {
+		const sourceImage = "some-registry.io/some-repo-source@sha256:1f3c4d8e2b7a6f5e9d0c1b2a3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e"
+
+		fetcher := &fakes.Fetcher{}
+		fetcher.AddImage(sourceImage, fakes.NewFakeLabeledImage("some-label", "some-value", "some-digest"))
+		factory.Fetcher = fetcher
+		factory.SourceImage = sourceImage
+
+		_, patch, err := factory.MakePatch(img)
+		require.NoError(t, err)
+		require.Equal(t, `{"spec":{"source":{"blob":null,"registry":{"image":"`+sourceImage+`"}}}}`, string(patch))
+		require.Equal(t, 1, fetcher.CallCount())
+	})
+
 	when("too many source types are provided", func() {
 		it("returns an error message", func() {
 			factory.GitRepo = "some-git-repo"
 			factory.Blob = "some-blob"
 			factory.LocalPath = "some-local-path"
 			_, _, err := factory.MakePatch(img)
-			require.EqualError(t, err, "image source must be one of git, blob, or local-path")
+			require.EqualError(t, err, "image source must be one of git, blob, local-path, or source-image")
 		})
 	})
 
@@ -77,7 +91,7 @@ func testPatchFactory(t *testing.T, when spec.G, it spec.S) {
 			factory.Blob = "some-blob"
 			factory.GitRevision = "some-revision"
 			_, _, err := factory.MakePatch(img)
-			require.EqualError(t, err, "git-revision is incompatible with blob and local path image sources")
+			require.EqualError(t, err, "git-revision is incompatible with blob, local path and source image sources")
 		})
 	})
 
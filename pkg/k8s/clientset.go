@@ -27,19 +27,25 @@ type ClientSetProvider interface {
 	GetClientSet(namespace string) (ClientSet, error)
 }
 
+// NamespaceProvider resolves the namespace of a command without creating
+// clients, it defaults to the kubeconfig namespace.
+type NamespaceProvider interface {
+	GetNamespace(namespace string) (string, error)
+}
+
 type DefaultClientSetProvider struct {
+	// KubeContext is the kubeconfig context used to create clients.
+	// The kubeconfig current-context is used when empty.
+	KubeContext string
+
 	clientSet ClientSet
 }
 
 func (d DefaultClientSetProvider) GetClientSet(namespace string) (ClientSet, error) {
 	var err error
 
-	if namespace == "" {
-		if d.clientSet.Namespace, err = d.getDefaultNamespace(); err != nil {
-			return d.clientSet, err
-		}
-	} else {
-		d.clientSet.Namespace = namespace
+	if d.clientSet.Namespace, err = d.GetNamespace(namespace); err != nil {
+		return d.clientSet, err
 	}
 
 	if d.clientSet.KpackClient, err = d.getKpackClient(); err != nil {
@@ -50,6 +56,13 @@ func (d DefaultClientSetProvider) GetClientSet(namespace string) (ClientSet, err
 	return d.clientSet, err
 }
 
+func (d DefaultClientSetProvider) GetNamespace(namespace string) (string, error) {
+	if namespace != "" {
+		return namespace, nil
+	}
+	return d.getDefaultNamespace()
+}
+
 func (d DefaultClientSetProvider) getKpackClient() (*kpack.Clientset, error) {
 	restConfig, err := d.restConfig()
 	if err != nil {
@@ -71,7 +84,7 @@ func (d DefaultClientSetProvider) getK8sClient() (*k8s.Clientset, error) {
 func (d DefaultClientSetProvider) restConfig() (*rest.Config, error) {
 	clientConfig := clientcmd.NewInteractiveDeferredLoadingClientConfig(
 		clientcmd.NewDefaultClientConfigLoadingRules(),
-		&clientcmd.ConfigOverrides{},
+		&clientcmd.ConfigOverrides{CurrentContext: d.KubeContext},
 		os.Stdin,
 	)
 
@@ -91,11 +104,19 @@ func (d DefaultClientSetProvider) getDefaultNamespace() (string, error) {
 		return "", err
 	}
 
-	if _, ok := rawConfig.Contexts[rawConfig.CurrentContext]; !ok {
+	contextName := rawConfig.CurrentContext
+	if d.KubeContext != "" {
+		contextName = d.KubeContext
+	}
+
+	if _, ok := rawConfig.Contexts[contextName]; !ok {
+		if d.KubeContext != "" {
+			return "", errors.Errorf("Kubernetes context %q does not exist", d.KubeContext)
+		}
 		return "", errors.New("Kubernetes current context is not set")
 	}
 
-	defaultNamespace := rawConfig.Contexts[rawConfig.CurrentContext].Namespace
+	defaultNamespace := rawConfig.Contexts[contextName].Namespace
 	if defaultNamespace == "" {
 		defaultNamespace = "default"
 	}
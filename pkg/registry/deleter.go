@@ -0,0 +1,50 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+type Deleter interface {
+	Delete(ref string, writer io.Writer, tlsCfg TLSConfig) error
+}
+
+type DiscardDeleter struct{}
+
+func (d DiscardDeleter) Delete(ref string, writer io.Writer, _ TLSConfig) error {
+	_, err := writer.Write([]byte(fmt.Sprintf("\tSkipping deletion of '%s'\n", ref)))
+	return err
+}
+
+type DefaultDeleter struct{}
+
+// Delete removes the manifest referenced by ref. Registries remove the tags
+// pointing to a manifest along with it.
+func (d DefaultDeleter) Delete(ref string, writer io.Writer, tlsCfg TLSConfig) error {
+	reference, err := name.ParseReference(ref, name.WeakValidation)
+	if err != nil {
+		return err
+	}
+
+	transport, err := tlsCfg.Transport()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write([]byte(fmt.Sprintf("\tDeleting '%s'\n", ref))); err != nil {
+		return err
+	}
+
+	err = remote.Delete(reference, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithTransport(transport))
+	if err != nil {
+		return newImageAccessError(reference.String(), err)
+	}
+	return nil
+}
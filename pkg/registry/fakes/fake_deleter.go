@@ -0,0 +1,49 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fakes
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+type Deleter struct {
+	skip   bool
+	calls  []string
+	errors map[string]error
+}
+
+func (d *Deleter) Delete(ref string, writer io.Writer, _ registry.TLSConfig) error {
+	d.calls = append(d.calls, ref)
+
+	var message string
+	if d.skip {
+		message = fmt.Sprintf("\tSkipping deletion of '%s'\n", ref)
+	} else {
+		message = fmt.Sprintf("\tDeleting '%s'\n", ref)
+	}
+
+	if _, err := writer.Write([]byte(message)); err != nil {
+		return err
+	}
+
+	return d.errors[ref]
+}
+
+func (d *Deleter) Calls() []string {
+	return d.calls
+}
+
+func (d *Deleter) SetSkip(skip bool) {
+	d.skip = skip
+}
+
+func (d *Deleter) SetError(ref string, err error) {
+	if d.errors == nil {
+		d.errors = map[string]error{}
+	}
+	d.errors[ref] = err
+}
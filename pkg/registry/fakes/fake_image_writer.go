@@ -0,0 +1,55 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fakes
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+type ImageWriter struct {
+	skip   bool
+	images map[string]v1.Image
+}
+
+func (w *ImageWriter) Write(img v1.Image, dstTag string, writer io.Writer, _ registry.TLSConfig) (string, error) {
+	if w.images == nil {
+		w.images = map[string]v1.Image{}
+	}
+	w.images[dstTag] = img
+
+	tag, err := name.NewTag(dstTag, name.WeakValidation)
+	if err != nil {
+		return "", err
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return "", err
+	}
+	ref := fmt.Sprintf("%s@%s", tag.Context().Name(), digest)
+
+	var message string
+	if w.skip {
+		message = fmt.Sprintf("\tSkipping '%s'\n", ref)
+	} else {
+		message = fmt.Sprintf("\tUploading '%s'\n", ref)
+	}
+
+	_, err = writer.Write([]byte(message))
+	return ref, err
+}
+
+func (w *ImageWriter) Images() map[string]v1.Image {
+	return w.images
+}
+
+func (w *ImageWriter) SetSkip(skip bool) {
+	w.skip = skip
+}
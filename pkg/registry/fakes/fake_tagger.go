@@ -0,0 +1,43 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fakes
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+type TagCall struct {
+	SrcRef string
+	DstTag string
+}
+
+type Tagger struct {
+	skip  bool
+	calls []TagCall
+}
+
+func (t *Tagger) Tag(srcRef, dstTag string, writer io.Writer, _ registry.TLSConfig) error {
+	t.calls = append(t.calls, TagCall{SrcRef: srcRef, DstTag: dstTag})
+
+	var message string
+	if t.skip {
+		message = fmt.Sprintf("\tSkipping tag of '%s' as '%s'\n", srcRef, dstTag)
+	} else {
+		message = fmt.Sprintf("\tTagging '%s' as '%s'\n", srcRef, dstTag)
+	}
+
+	_, err := writer.Write([]byte(message))
+	return err
+}
+
+func (t *Tagger) Calls() []TagCall {
+	return t.calls
+}
+
+func (t *Tagger) SetSkip(skip bool) {
+	t.skip = skip
+}
@@ -6,6 +6,9 @@ type UtilProvider struct {
 	FakeFetcher        registry.Fetcher
 	FakeRelocator      registry.Relocator
 	FakeSourceUploader registry.SourceUploader
+	FakeTagger         registry.Tagger
+	FakeDeleter        registry.Deleter
+	FakeImageWriter    registry.ImageWriter
 }
 
 func (u UtilProvider) Fetcher() registry.Fetcher {
@@ -19,3 +22,15 @@ func (u UtilProvider) Relocator(changeState bool) registry.Relocator {
 func (u UtilProvider) SourceUploader(changeState bool) registry.SourceUploader {
 	return u.FakeSourceUploader
 }
+
+func (u UtilProvider) Tagger(changeState bool) registry.Tagger {
+	return u.FakeTagger
+}
+
+func (u UtilProvider) Deleter(changeState bool) registry.Deleter {
+	return u.FakeDeleter
+}
+
+func (u UtilProvider) ImageWriter(changeState bool) registry.ImageWriter {
+	return u.FakeImageWriter
+}
@@ -0,0 +1,62 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+type Tagger interface {
+	Tag(srcRef, dstTag string, writer io.Writer, tlsCfg TLSConfig) error
+}
+
+type DiscardTagger struct{}
+
+func (d DiscardTagger) Tag(srcRef, dstTag string, writer io.Writer, _ TLSConfig) error {
+	_, err := writer.Write([]byte(fmt.Sprintf("\tSkipping tag of '%s' as '%s'\n", srcRef, dstTag)))
+	return err
+}
+
+type DefaultTagger struct{}
+
+func (d DefaultTagger) Tag(srcRef, dstTag string, writer io.Writer, tlsCfg TLSConfig) error {
+	src, err := name.ParseReference(srcRef, name.WeakValidation)
+	if err != nil {
+		return err
+	}
+
+	dst, err := name.NewTag(dstTag, name.WeakValidation)
+	if err != nil {
+		return err
+	}
+
+	transport, err := tlsCfg.Transport()
+	if err != nil {
+		return err
+	}
+
+	options := []remote.Option{
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithTransport(transport),
+	}
+
+	if _, err := writer.Write([]byte(fmt.Sprintf("\tTagging '%s' as '%s'\n", srcRef, dstTag))); err != nil {
+		return err
+	}
+
+	desc, err := remote.Get(src, options...)
+	if err != nil {
+		return newImageAccessError(src.String(), err)
+	}
+
+	if err := remote.Tag(dst, desc, options...); err != nil {
+		return newImageAccessError(dst.String(), err)
+	}
+	return nil
+}
@@ -4,6 +4,9 @@ type UtilProvider interface {
 	Relocator(changeState bool) Relocator
 	SourceUploader(changeState bool) SourceUploader
 	Fetcher() Fetcher
+	Tagger(changeState bool) Tagger
+	Deleter(changeState bool) Deleter
+	ImageWriter(changeState bool) ImageWriter
 }
 
 type DefaultUtilProvider struct{}
@@ -27,3 +30,27 @@ func (d DefaultUtilProvider) SourceUploader(changeState bool) SourceUploader {
 func (d DefaultUtilProvider) Fetcher() Fetcher {
 	return DefaultFetcher{}
 }
+
+func (d DefaultUtilProvider) Tagger(changeState bool) Tagger {
+	if changeState {
+		return DefaultTagger{}
+	} else {
+		return DiscardTagger{}
+	}
+}
+
+func (d DefaultUtilProvider) Deleter(changeState bool) Deleter {
+	if changeState {
+		return DefaultDeleter{}
+	} else {
+		return DiscardDeleter{}
+	}
+}
+
+func (d DefaultUtilProvider) ImageWriter(changeState bool) ImageWriter {
+	if changeState {
+		return DefaultImageWriter{}
+	} else {
+		return DiscardImageWriter{}
+	}
+}
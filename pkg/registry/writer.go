@@ -0,0 +1,73 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+type ImageWriter interface {
+	Write(img v1.Image, dstTag string, writer io.Writer, tlsCfg TLSConfig) (string, error)
+}
+
+type DiscardImageWriter struct{}
+
+func (d DiscardImageWriter) Write(img v1.Image, dstTag string, writer io.Writer, _ TLSConfig) (string, error) {
+	ref, err := digestRef(img, dstTag)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = writer.Write([]byte(fmt.Sprintf("\tSkipping '%s'\n", ref)))
+	return ref, err
+}
+
+type DefaultImageWriter struct{}
+
+func (d DefaultImageWriter) Write(img v1.Image, dstTag string, writer io.Writer, tlsCfg TLSConfig) (string, error) {
+	tag, err := name.NewTag(dstTag, name.WeakValidation)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := digestRef(img, dstTag)
+	if err != nil {
+		return "", err
+	}
+
+	transport, err := tlsCfg.Transport()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := writer.Write([]byte(fmt.Sprintf("\tUploading '%s'\n", ref))); err != nil {
+		return ref, err
+	}
+
+	err = remote.Write(tag, img, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithTransport(transport))
+	if err != nil {
+		return ref, newImageAccessError(tag.String(), err)
+	}
+	return ref, nil
+}
+
+func digestRef(img v1.Image, dstTag string) (string, error) {
+	tag, err := name.NewTag(dstTag, name.WeakValidation)
+	if err != nil {
+		return "", err
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s@%s", tag.Context().Name(), digest), nil
+}
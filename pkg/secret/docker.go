@@ -3,10 +3,60 @@
 
 package secret
 
-import "github.com/google/go-containerregistry/pkg/authn"
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	DockerAnnotation = "kpack.io/docker"
+
+	dockerHubRegistry = "index.docker.io"
+)
 
 type DockerCredentials map[string]authn.AuthConfig
 
 type DockerConfigJson struct {
 	Auths DockerCredentials `json:"auths"`
 }
+
+// RegistryHosts returns the registry hosts a secret provides credentials for,
+// from either a docker config json or a kpack docker annotation.
+func RegistryHosts(s *corev1.Secret) []string {
+	var hosts []string
+
+	if registry, ok := s.Annotations[DockerAnnotation]; ok {
+		hosts = append(hosts, RegistryHost(registry))
+	}
+
+	if s.Type == corev1.SecretTypeDockerConfigJson {
+		configJson := DockerConfigJson{}
+		if err := json.Unmarshal(s.Data[corev1.DockerConfigJsonKey], &configJson); err == nil {
+			for registry := range configJson.Auths {
+				hosts = append(hosts, RegistryHost(registry))
+			}
+		}
+	}
+
+	return hosts
+}
+
+// RegistryHost normalizes a registry url such as "https://index.docker.io/v1/"
+// to its host.
+func RegistryHost(registry string) string {
+	if idx := strings.Index(registry, "://"); idx != -1 {
+		registry = registry[idx+3:]
+	}
+
+	if idx := strings.Index(registry, "/"); idx != -1 {
+		registry = registry[:idx]
+	}
+
+	if registry == "docker.io" {
+		return dockerHubRegistry
+	}
+	return registry
+}
@@ -5,13 +5,17 @@ package secret
 
 import (
 	"encoding/json"
+	"sort"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/git"
 )
 
 const ManagedSecretAnnotationKey = "kpack.io/managedSecret"
 
-func readManagedSecrets(sa *corev1.ServiceAccount) (map[string]string, error) {
+func ReadManagedSecrets(sa *corev1.ServiceAccount) (map[string]string, error) {
 	if sa.Annotations == nil {
 		return map[string]string{}, nil
 	}
@@ -30,7 +34,7 @@ func readManagedSecrets(sa *corev1.ServiceAccount) (map[string]string, error) {
 	return managedSecrets, nil
 }
 
-func writeManagedSecrets(managedSecrets map[string]string, sa *corev1.ServiceAccount) error {
+func WriteManagedSecrets(managedSecrets map[string]string, sa *corev1.ServiceAccount) error {
 	if sa.Annotations == nil {
 		sa.Annotations = map[string]string{}
 	}
@@ -49,3 +53,22 @@ func writeManagedSecrets(managedSecrets map[string]string, sa *corev1.ServiceAcc
 
 	return nil
 }
+
+// GitSecretsForURL returns the names of the managed secrets whose git target
+// has the same host as gitURL.
+func GitSecretsForURL(managedSecrets map[string]string, gitURL string) []string {
+	host := git.Host(gitURL)
+
+	var names []string
+	for name, target := range managedSecrets {
+		if isGitTarget(target) && git.Host(target) == host {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isGitTarget(target string) bool {
+	return git.IsHTTP(target) || strings.HasPrefix(target, "git@")
+}
@@ -21,6 +21,13 @@ func (f FakeClientSetProvider) GetClientSet(namespace string) (clientSet k8s.Cli
 	return f.clientSet, nil
 }
 
+func (f FakeClientSetProvider) GetNamespace(namespace string) (string, error) {
+	if namespace != "" {
+		return namespace, nil
+	}
+	return f.clientSet.Namespace, nil
+}
+
 func GetFakeKpackProvider(kpackClient *kpackfakes.Clientset, namespace string) FakeClientSetProvider {
 	return FakeClientSetProvider{
 		clientSet: k8s.ClientSet{
@@ -55,3 +62,13 @@ func GetFakeClusterProvider(k8sClient *k8sfakes.Clientset, kpackClient *kpackfak
 		},
 	}
 }
+
+func GetFakeProvider(k8sClient *k8sfakes.Clientset, kpackClient *kpackfakes.Clientset, namespace string) FakeClientSetProvider {
+	return FakeClientSetProvider{
+		clientSet: k8s.ClientSet{
+			K8sClient:   k8sClient,
+			KpackClient: kpackClient,
+			Namespace:   namespace,
+		},
+	}
+}